@@ -3,8 +3,6 @@ package stream
 import (
 	"encoding/json"
 	"fmt"
-
-	"github.com/fatih/structs"
 )
 
 // Activity is a Stream activity entity.
@@ -23,60 +21,151 @@ type Activity struct {
 }
 
 // UnmarshalJSON decodes the provided JSON payload into the Activity. It's required
-// because of the custom JSON fields and time formats.
+// because of the custom JSON fields and time formats. The standard fields are
+// decoded directly into their types, while the other ones are kept in Extra.
 func (a *Activity) UnmarshalJSON(b []byte) error {
-	var data map[string]interface{}
-	if err := json.Unmarshal(b, &data); err != nil {
+	fields, err := decodeObject(b)
+	if err != nil {
 		return err
 	}
+	var extra map[string]interface{}
+	for k, raw := range fields {
+		switch k {
+		case "id":
+			a.ID, err = decodeString(raw)
+		case "actor":
+			a.Actor, err = decodeString(raw)
+		case "verb":
+			a.Verb, err = decodeString(raw)
+		case "object":
+			a.Object, err = decodeString(raw)
+		case "foreign_id":
+			a.ForeignID, err = decodeString(raw)
+		case "target":
+			a.Target, err = decodeString(raw)
+		case "time":
+			a.Time, err = decodeTime(raw)
+		case "origin":
+			a.Origin, err = decodeString(raw)
+		case "to":
+			a.To, err = decodeToTargets(raw)
+		case "score":
+			err = json.Unmarshal(raw, &a.Score)
+		default:
+			extra, err = setExtra(extra, k, raw)
+		}
+		if err != nil {
+			return fieldError(k, err)
+		}
+	}
+	if extra != nil {
+		a.Extra = extra
+	}
+	return nil
+}
 
-	if _, ok := data["to"]; ok {
-		tos := data["to"].([]interface{})
-		simpleTos := make([]string, len(tos))
-		for i := range tos {
-			if sliceTos, isString := tos[i].(string); isString {
-				simpleTos[i] = sliceTos
-			} else if sliceTos, isSlice := tos[i].([]interface{}); isSlice {
-				tos, ok := sliceTos[0].(string)
-				if !ok {
-					return fmt.Errorf("invalid format for to targets")
-				}
-				simpleTos[i] = tos
+// decodeToTargets decodes the `to` targets of an activity, which are returned
+// by the API either as feed IDs or as pairs of feed ID and token.
+func decodeToTargets(raw json.RawMessage) ([]string, error) {
+	var targets []json.RawMessage
+	if err := json.Unmarshal(raw, &targets); err != nil {
+		return nil, fmt.Errorf("expected a list of targets, got %s", jsonKind(raw))
+	}
+	if targets == nil {
+		return nil, nil
+	}
+	to := make([]string, len(targets))
+	for i, target := range targets {
+		switch target[0] {
+		case '"':
+			id, err := decodeString(target)
+			if err != nil {
+				return nil, err
+			}
+			to[i] = id
+		case '[':
+			var pair []json.RawMessage
+			if err := json.Unmarshal(target, &pair); err != nil {
+				return nil, err
 			}
+			if len(pair) == 0 || pair[0][0] != '"' {
+				return nil, fmt.Errorf("expected a feed ID or a pair of feed ID and token, got a list without a feed ID")
+			}
+			id, err := decodeString(pair[0])
+			if err != nil {
+				return nil, err
+			}
+			to[i] = id
+		default:
+			return nil, fmt.Errorf("expected a feed ID or a pair of feed ID and token, got %s", jsonKind(target))
 		}
-		data["to"] = simpleTos
 	}
-
-	return a.decode(data)
+	return to, nil
 }
 
 // MarshalJSON encodes the Activity to a valid JSON bytes slice. It's required because of
-// the custom JSON fields and time formats.
+// the custom JSON fields and time formats. Fields are sorted by name, and the
+// extra fields take precedence over the standard ones having the same name.
 func (a Activity) MarshalJSON() ([]byte, error) {
-	data := structs.New(a).Map()
-	for k, v := range a.Extra {
-		data[k] = v
+	e := objectEncoder{buf: make([]byte, 0, 256)}
+	for _, f := range []struct{ key, value string }{
+		{"id", a.ID}, {"actor", a.Actor}, {"verb", a.Verb}, {"object", a.Object},
+		{"foreign_id", a.ForeignID}, {"target", a.Target}, {"origin", a.Origin},
+	} {
+		if f.value != "" {
+			e.setString(f.key, f.value)
+		}
+	}
+	if !a.Time.IsZero() {
+		e.setTime("time", a.Time)
 	}
-	if _, ok := data["time"]; ok {
-		data["time"] = a.Time.Format(TimeLayout)
+	if a.To != nil {
+		e.setStrings("to", a.To)
+	}
+	if a.Score != 0 {
+		if err := e.setValue("score", a.Score, false); err != nil {
+			return nil, err
+		}
 	}
-	return json.Marshal(data)
+	if err := e.setExtra(a.Extra, activityFields); err != nil {
+		return nil, err
+	}
+	if e.has("time") {
+		e.setTime("time", a.Time)
+	}
+	return e.encode(), nil
 }
 
-func (a *Activity) decode(data map[string]interface{}) error {
-	meta, err := decodeData(data, a)
+// activityFields are the JSON fields of Activity, which can't be set as extra fields.
+var activityFields = jsonFieldNames(Activity{})
+
+// DecodeExtra decodes the extra fields of the Activity into v, which is usually
+// a pointer to a struct describing the custom fields of a given verb. Fields
+// are matched following the rules of json.Unmarshal.
+func (a Activity) DecodeExtra(v interface{}) error {
+	return decodeExtra(a.Extra, v)
+}
+
+// EncodeExtra adds the fields v encodes to, as a JSON object, to the extra
+// fields of the Activity. v is usually a struct describing the custom fields of
+// a given verb. An error is returned if v doesn't encode to a JSON object, or if
+// any of its fields conflicts with a standard Activity field. Numbers are added
+// as json.Number, and the existing Extra map is copied rather than modified.
+func (a *Activity) EncodeExtra(v interface{}) error {
+	extra, err := encodeExtra(a.Extra, v, activityFields)
 	if err != nil {
 		return err
 	}
-	if len(meta.Unused) > 0 {
-		a.Extra = make(map[string]interface{})
-		for _, k := range meta.Unused {
-			a.Extra[k] = data[k]
-		}
-	}
+	a.Extra = extra
 	return nil
 }
 
+// isIdempotent tells whether the activity can be safely sent more than once,
+// since activities having the same foreign ID and time are deduplicated by the API.
+func (a Activity) isIdempotent() bool {
+	return a.ForeignID != "" && !a.Time.IsZero()
+}
+
 // baseActivityGroup is the common part of responses obtained from reading normal or enriched aggregated feeds.
 type baseActivityGroup struct {
 	ActivityCount int    `json:"activity_count,omitempty"`
@@ -2,6 +2,7 @@ package stream_test
 
 import (
 	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -68,6 +69,42 @@ func TestActivityMarshalUnmarshalJSON_toTargets(t *testing.T) {
 			data:        []byte(`{"to":[[123]]}`),
 			shouldError: true,
 		},
+		{
+			activity: stream.Activity{},
+			data:     []byte(`{"to":null}`),
+		},
+		{
+			data:        []byte(`{"to":"abcd"}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[[]]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[["abcd", "foo"], [null]]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[42]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[{}]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[true]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[null]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":["abcd", 42]}`),
+			shouldError: true,
+		},
 	}
 	for _, tc := range testCases {
 		var out stream.Activity
@@ -79,4 +116,80 @@ func TestActivityMarshalUnmarshalJSON_toTargets(t *testing.T) {
 			assert.Equal(t, tc.activity, out)
 		}
 	}
+	err := json.Unmarshal([]byte(`{"to":[42]}`), &stream.Activity{})
+	assert.EqualError(t, err, `invalid "to" field: expected a feed ID or a pair of feed ID and token, got a number`)
+}
+
+type pictureExtra struct {
+	URL   string         `json:"url"`
+	Size  map[string]int `json:"size"`
+	Tags  []string       `json:"tags,omitempty"`
+	Taken time.Time      `json:"taken"`
+}
+
+func TestActivityExtra(t *testing.T) {
+	taken := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	extra := pictureExtra{URL: "http://example.com/1.jpg", Size: map[string]int{"width": 800}, Taken: taken}
+	existing := map[string]interface{}{"popularity": 42.0}
+	activity := stream.Activity{Actor: "bob", Verb: "upload", Object: "picture:1", Extra: existing}
+	require.NoError(t, activity.EncodeExtra(extra))
+	assert.Equal(t, map[string]interface{}{
+		"popularity": 42.0,
+		"url":        "http://example.com/1.jpg",
+		"size":       map[string]interface{}{"width": json.Number("800")},
+		"taken":      "2019-04-01T12:00:00Z",
+	}, activity.Extra)
+	assert.Equal(t, map[string]interface{}{"popularity": 42.0}, existing)
+
+	data, err := json.Marshal(activity)
+	require.NoError(t, err)
+	var out stream.Activity
+	require.NoError(t, json.Unmarshal(data, &out))
+	var decoded pictureExtra
+	require.NoError(t, out.DecodeExtra(&decoded))
+	assert.Equal(t, extra, decoded)
+
+	var empty pictureExtra
+	require.NoError(t, stream.Activity{}.DecodeExtra(&empty))
+	assert.Equal(t, pictureExtra{}, empty)
+	var wrongType struct {
+		URL int `json:"url"`
+	}
+	assert.Error(t, out.DecodeExtra(&wrongType))
+
+	testCases := []struct {
+		extra interface{}
+		err   string
+	}{
+		{extra: struct {
+			Actor string `json:"actor"`
+		}{"alice"}, err: `extra field "actor" conflicts with a standard field`},
+		{extra: "picture", err: "extra fields must encode to a JSON object: json: cannot unmarshal string into Go value of type map[string]interface {}"},
+		{extra: nil, err: "extra fields must encode to a JSON object"},
+		{extra: make(chan int), err: "json: unsupported type: chan int"},
+	}
+	for _, tc := range testCases {
+		activity := stream.Activity{Actor: "bob"}
+		assert.EqualError(t, activity.EncodeExtra(tc.extra), tc.err)
+		assert.Nil(t, activity.Extra)
+	}
+}
+
+func TestActivityEncodeExtra_largeIntegers(t *testing.T) {
+	type ids struct {
+		BigID  int64   `json:"big_id"`
+		MaxID  uint64  `json:"max_id"`
+		Factor float64 `json:"factor"`
+	}
+	extra := ids{BigID: 9007199254740993, MaxID: math.MaxUint64, Factor: 0.1}
+	activity := stream.Activity{Actor: "bob", Verb: "post", Object: "post:1"}
+	require.NoError(t, activity.EncodeExtra(extra))
+
+	data, err := json.Marshal(activity)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"big_id":9007199254740993`)
+	assert.Contains(t, string(data), `"max_id":18446744073709551615`)
+	var decoded ids
+	require.NoError(t, activity.DecodeExtra(&decoded))
+	assert.Equal(t, extra, decoded)
 }
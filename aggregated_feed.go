@@ -1,8 +1,6 @@
 package stream
 
-import (
-	"encoding/json"
-)
+import "context"
 
 // AggregatedFeed is a Stream aggregated feed, which contains activities grouped
 // based on the grouping function defined on the dashboard.
@@ -13,12 +11,13 @@ type AggregatedFeed struct {
 // GetActivities requests and retrieves the activities and groups for the
 // aggregated feed.
 func (f *AggregatedFeed) GetActivities(opts ...GetActivitiesOption) (*AggregatedFeedResponse, error) {
-	body, err := f.client.getActivities(f, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return f.GetActivitiesContext(context.Background(), opts...)
+}
+
+// GetActivitiesContext is like GetActivities, using the provided context for the request.
+func (f *AggregatedFeed) GetActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*AggregatedFeedResponse, error) {
 	var resp AggregatedFeedResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := f.client.getActivities(ctx, f, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -27,22 +26,28 @@ func (f *AggregatedFeed) GetActivities(opts ...GetActivitiesOption) (*Aggregated
 // GetNextPageActivities returns the activities for the given AggregatedFeed at the "next" page
 // of a previous *AggregatedFeedResponse response, if any.
 func (f *AggregatedFeed) GetNextPageActivities(resp *AggregatedFeedResponse) (*AggregatedFeedResponse, error) {
-	opts, err := resp.parseNext()
-	if err != nil {
+	return f.GetNextPageActivitiesContext(context.Background(), resp)
+}
+
+// GetNextPageActivitiesContext is like GetNextPageActivities, using the provided context for the request.
+func (f *AggregatedFeed) GetNextPageActivitiesContext(ctx context.Context, resp *AggregatedFeedResponse) (*AggregatedFeedResponse, error) {
+	var next AggregatedFeedResponse
+	if err := f.client.getNextActivities(ctx, f, resp.Next, &next); err != nil {
 		return nil, err
 	}
-	return f.GetActivities(opts...)
+	return &next, nil
 }
 
 // GetEnrichedActivities requests and retrieves the enriched activities and groups for the
 // aggregated feed.
 func (f *AggregatedFeed) GetEnrichedActivities(opts ...GetActivitiesOption) (*EnrichedAggregatedFeedResponse, error) {
-	body, err := f.client.getEnrichedActivities(f, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return f.GetEnrichedActivitiesContext(context.Background(), opts...)
+}
+
+// GetEnrichedActivitiesContext is like GetEnrichedActivities, using the provided context for the request.
+func (f *AggregatedFeed) GetEnrichedActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*EnrichedAggregatedFeedResponse, error) {
 	var resp EnrichedAggregatedFeedResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := f.client.getEnrichedActivities(ctx, f, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -51,9 +56,14 @@ func (f *AggregatedFeed) GetEnrichedActivities(opts ...GetActivitiesOption) (*En
 // GetNextPageEnrichedActivities returns the enriched activities for the given AggregatedFeed at the "next" page
 // of a previous *EnrichedAggregatedFeedResponse response, if any.
 func (f *AggregatedFeed) GetNextPageEnrichedActivities(resp *EnrichedAggregatedFeedResponse) (*EnrichedAggregatedFeedResponse, error) {
-	opts, err := resp.parseNext()
-	if err != nil {
+	return f.GetNextPageEnrichedActivitiesContext(context.Background(), resp)
+}
+
+// GetNextPageEnrichedActivitiesContext is like GetNextPageEnrichedActivities, using the provided context for the request.
+func (f *AggregatedFeed) GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *EnrichedAggregatedFeedResponse) (*EnrichedAggregatedFeedResponse, error) {
+	var next EnrichedAggregatedFeedResponse
+	if err := f.client.getNextEnrichedActivities(ctx, f, resp.Next, &next); err != nil {
 		return nil, err
 	}
-	return f.GetEnrichedActivities(opts...)
+	return &next, nil
 }
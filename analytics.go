@@ -1,6 +1,7 @@
 package stream
 
 import (
+	"context"
 	"encoding/json"
 )
 
@@ -12,26 +13,36 @@ type AnalyticsClient struct {
 
 // TrackEngagement is used to send and track analytics EngagementEvents.
 func (c *AnalyticsClient) TrackEngagement(events ...EngagementEvent) error {
-	endpoint := c.client.makeEndpoint("engagement/")
+	return c.TrackEngagementContext(context.Background(), events...)
+}
+
+// TrackEngagementContext is like TrackEngagement, using the provided context for the request.
+func (c *AnalyticsClient) TrackEngagementContext(ctx context.Context, events ...EngagementEvent) error {
+	endpoint := c.client.makeEndpoint(ResourceAnalytics, "engagement/")
+	endpoint.operation = "analytics.track_engagement"
 	data := map[string]interface{}{
 		"content_list": events,
 	}
-	_, err := c.client.post(endpoint, data, c.client.authenticator.analyticsAuth)
-	return err
+	return c.client.post(ctx, endpoint, data, nil, c.client.authenticator.analyticsAuth)
 }
 
 // TrackImpression is used to send and track analytics ImpressionEvents.
 func (c *AnalyticsClient) TrackImpression(eventsData ImpressionEventsData) error {
-	endpoint := c.client.makeEndpoint("impression/")
-	_, err := c.client.post(endpoint, eventsData, c.client.authenticator.analyticsAuth)
-	return err
+	return c.TrackImpressionContext(context.Background(), eventsData)
+}
+
+// TrackImpressionContext is like TrackImpression, using the provided context for the request.
+func (c *AnalyticsClient) TrackImpressionContext(ctx context.Context, eventsData ImpressionEventsData) error {
+	endpoint := c.client.makeEndpoint(ResourceAnalytics, "impression/")
+	endpoint.operation = "analytics.track_impression"
+	return c.client.post(ctx, endpoint, eventsData, nil, c.client.authenticator.analyticsAuth)
 }
 
 // RedirectAndTrack is used to send and track analytics ImpressionEvents. It tracks
 // the events data (either EngagementEvents or ImpressionEvents) and redirects to the provided
 // URL string.
 func (c *AnalyticsClient) RedirectAndTrack(url string, events ...map[string]interface{}) (string, error) {
-	endpoint := c.client.makeEndpoint("redirect/")
+	endpoint := c.client.makeEndpoint(ResourceAnalyticsRedirect, "redirect/")
 	eventsData, err := json.Marshal(events)
 	if err != nil {
 		return "", err
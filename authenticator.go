@@ -10,19 +10,22 @@ import (
 
 type authFunc func(*http.Request) error
 
-type resource string
+// Resource is a group of API endpoints, used for authorizing requests and for
+// client-side rate limiting.
+type Resource string
 
+// The API resources.
 const (
-	resFollower          resource = "follower"
-	resActivities        resource = "activities"
-	resFeed              resource = "feed"
-	resFeedTargets       resource = "feed_targets"
-	resCollections       resource = "collections"
-	resUsers             resource = "users"
-	resReactions         resource = "reactions"
-	resPersonalization   resource = "personalization"
-	resAnalytics         resource = "analytics"
-	resAnalyticsRedirect resource = "redirect_and_track"
+	ResourceFollower          Resource = "follower"
+	ResourceActivities        Resource = "activities"
+	ResourceFeed              Resource = "feed"
+	ResourceFeedTargets       Resource = "feed_targets"
+	ResourceCollections       Resource = "collections"
+	ResourceUsers             Resource = "users"
+	ResourceReactions         Resource = "reactions"
+	ResourcePersonalization   Resource = "personalization"
+	ResourceAnalytics         Resource = "analytics"
+	ResourceAnalyticsRedirect Resource = "redirect_and_track"
 )
 
 type action string
@@ -54,7 +57,7 @@ func (a authenticator) feedID(feed Feed) string {
 	return fmt.Sprintf("%s%s", feed.Slug(), feed.UserID())
 }
 
-func (a authenticator) feedAuth(resource resource, feed Feed) authFunc {
+func (a authenticator) feedAuth(resource Resource, feed Feed) authFunc {
 	return func(req *http.Request) error {
 		var feedID string
 		if feed != nil {
@@ -70,7 +73,7 @@ func (a authenticator) collectionsAuth(req *http.Request) error {
 	claims := jwt.MapClaims{
 		"action":   "*",
 		"feed_id":  "*",
-		"resource": resCollections,
+		"resource": ResourceCollections,
 	}
 	return a.jwtSignRequest(req, claims)
 }
@@ -79,7 +82,7 @@ func (a authenticator) usersAuth(req *http.Request) error {
 	claims := jwt.MapClaims{
 		"action":   "*",
 		"feed_id":  "*",
-		"resource": resUsers,
+		"resource": ResourceUsers,
 	}
 	return a.jwtSignRequest(req, claims)
 }
@@ -88,7 +91,7 @@ func (a authenticator) reactionsAuth(req *http.Request) error {
 	claims := jwt.MapClaims{
 		"action":   "*",
 		"feed_id":  "*",
-		"resource": resReactions,
+		"resource": ResourceReactions,
 	}
 	return a.jwtSignRequest(req, claims)
 }
@@ -98,7 +101,7 @@ func (a authenticator) personalizationAuth(req *http.Request) error {
 		"action":   "*",
 		"user_id":  "*",
 		"feed_id":  "*",
-		"resource": resPersonalization,
+		"resource": ResourcePersonalization,
 	}
 	return a.jwtSignRequest(req, claims)
 }
@@ -107,7 +110,7 @@ func (a authenticator) analyticsAuth(req *http.Request) error {
 	claims := jwt.MapClaims{
 		"action":   "*",
 		"user_id":  "*",
-		"resource": resAnalytics,
+		"resource": ResourceAnalytics,
 	}
 	return a.jwtSignRequest(req, claims)
 }
@@ -116,7 +119,7 @@ func (a authenticator) signAnalyticsRedirectEndpoint(endpoint *endpoint) error {
 	claims := jwt.MapClaims{
 		"action":   "*",
 		"user_id":  "*",
-		"resource": resAnalyticsRedirect,
+		"resource": ResourceAnalyticsRedirect,
 	}
 	signature, err := a.jwtSignatureFromClaims(claims)
 	if err != nil {
@@ -132,7 +135,7 @@ func (a authenticator) jwtSignatureFromClaims(claims jwt.MapClaims) (string, err
 	return token.SignedString([]byte(a.secret))
 }
 
-func (a authenticator) jwtFeedClaims(resource resource, action action, feedID string) jwt.MapClaims {
+func (a authenticator) jwtFeedClaims(resource Resource, action action, feedID string) jwt.MapClaims {
 	return jwt.MapClaims{
 		"resource": resource,
 		"action":   action,
@@ -145,8 +148,8 @@ func (a authenticator) jwtSignRequest(req *http.Request, claims jwt.MapClaims) e
 	if err != nil {
 		return fmt.Errorf("cannot make auth: %s", err)
 	}
-	req.Header.Add("Stream-Auth-Type", "jwt")
-	req.Header.Add("Authorization", auth)
+	req.Header.Set("Stream-Auth-Type", "jwt")
+	req.Header.Set("Authorization", auth)
 	return nil
 }
 
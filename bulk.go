@@ -0,0 +1,349 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Default chunk sizes of bulk operations, i.e. the number of items sent with
+// each request.
+const (
+	DefaultActivitiesChunkSize   = 100
+	DefaultAddToManyChunkSize    = 100
+	DefaultFollowManyChunkSize   = 2500
+	DefaultUnfollowManyChunkSize = 250
+)
+
+// DefaultBulkConcurrency is the default number of requests a bulk operation
+// runs concurrently.
+const DefaultBulkConcurrency = 4
+
+// BulkConfig configures a bulk operation. The zero value uses the default
+// chunk size of the operation and DefaultBulkConcurrency.
+type BulkConfig struct {
+	// ChunkSize is the number of items sent with each request.
+	ChunkSize int
+	// Concurrency is the maximum number of requests run concurrently.
+	Concurrency int
+}
+
+// BulkItemError is the failure of an item of a bulk operation.
+type BulkItemError struct {
+	// Index is the position of the item in the input.
+	Index int
+	// Err is the error of the item, usually the one of the request it was
+	// sent with, shared by the other items of its chunk.
+	Err error
+}
+
+// BulkResult tells which items of a bulk operation succeeded and which
+// failed.
+type BulkResult struct {
+	// Succeeded are the positions in the input of the items written
+	// successfully, in ascending order.
+	Succeeded []int
+	// Failed are the items which weren't written, ordered by position.
+	Failed []BulkItemError
+}
+
+// Err returns a *BulkError if any item failed, nil otherwise.
+func (r *BulkResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	return &BulkError{Failed: len(r.Failed), Total: len(r.Failed) + len(r.Succeeded), Err: r.Failed[0].Err}
+}
+
+// BulkActivitiesResult is the result of a bulk operation writing activities.
+type BulkActivitiesResult struct {
+	BulkResult
+	// Activities are the activities returned by the API, at the position of
+	// the corresponding input item. Activities of failed items are zero.
+	Activities []Activity
+}
+
+// BulkError is returned by bulk operations when some items failed, along with
+// the result listing them. It wraps the error of the first failed item.
+type BulkError struct {
+	// Failed is the number of failed items.
+	Failed int
+	// Total is the number of items.
+	Total int
+	// Err is the error of the first failed item.
+	Err error
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("%d of %d items failed: %s", e.Failed, e.Total, e.Err)
+}
+
+// Unwrap returns the error of the first failed item.
+func (e *BulkError) Unwrap() error {
+	return e.Err
+}
+
+// runBulk sends the items at the given positions in chunks, running up to
+// config.Concurrency calls to send at once, and records the outcome of each
+// item in result. Chunks not sent yet when the context is done fail with the
+// context error.
+func runBulk(ctx context.Context, items []int, defaultChunkSize int, config BulkConfig, result *BulkResult, send func(ctx context.Context, chunk []int) error) {
+	size := config.ChunkSize
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	record := func(chunk []int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, i := range chunk {
+			if err != nil {
+				result.Failed = append(result.Failed, BulkItemError{Index: i, Err: err})
+			} else {
+				result.Succeeded = append(result.Succeeded, i)
+			}
+		}
+	}
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		chunk := items[start:end]
+		if err := ctx.Err(); err != nil {
+			record(chunk, err)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			record(chunk, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			record(chunk, send(ctx, chunk))
+		}()
+	}
+	wg.Wait()
+
+	sort.Ints(result.Succeeded)
+	sort.Slice(result.Failed, func(i, j int) bool { return result.Failed[i].Index < result.Failed[j].Index })
+}
+
+// readChunkSize is the number of items looked up with each request by the
+// reads split in chunks.
+const readChunkSize = 100
+
+// runChunkedRead looks up n items in chunks of readChunkSize, running up to
+// DefaultBulkConcurrency calls to send at once, and returns the first error.
+func runChunkedRead(ctx context.Context, n int, send func(ctx context.Context, chunk []int) error) error {
+	var result BulkResult
+	runBulk(ctx, positions(n), readChunkSize, BulkConfig{}, &result, send)
+	if len(result.Failed) > 0 {
+		return result.Failed[0].Err
+	}
+	return nil
+}
+
+// uniqueStrings returns the given values without duplicates, in the order of
+// their first occurrence.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// positions returns the positions of n items.
+func positions(n int) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	return items
+}
+
+// validActivities returns the positions of the given activities passing the
+// client-side validation, if enabled, recording the other ones as failed.
+func (c *Client) validActivities(activities []Activity, update bool, result *BulkResult) []int {
+	items := make([]int, 0, len(activities))
+	for i, activity := range activities {
+		if err := c.validateActivities(update, activity); err != nil {
+			if verr, ok := err.(*ActivityValidationError); ok && len(verr.Activities) > 0 {
+				verr.Activities[0].Index = i
+			}
+			result.Failed = append(result.Failed, BulkItemError{Index: i, Err: err})
+			continue
+		}
+		items = append(items, i)
+	}
+	return items
+}
+
+// BulkAddActivities adds the given activities to the feed in chunks,
+// DefaultActivitiesChunkSize by default, reporting which ones were added and
+// which failed. A *BulkError is returned along with the result if any failed.
+func (c *Client) BulkAddActivities(feed Feed, activities []Activity, config BulkConfig) (*BulkActivitiesResult, error) {
+	return c.BulkAddActivitiesContext(context.Background(), feed, activities, config)
+}
+
+// BulkAddActivitiesContext is like BulkAddActivities, using the provided context for the requests.
+func (c *Client) BulkAddActivitiesContext(ctx context.Context, feed Feed, activities []Activity, config BulkConfig) (*BulkActivitiesResult, error) {
+	result := &BulkActivitiesResult{Activities: make([]Activity, len(activities))}
+	items := c.validActivities(activities, false, &result.BulkResult)
+	runBulk(ctx, items, DefaultActivitiesChunkSize, config, &result.BulkResult, func(ctx context.Context, chunk []int) error {
+		batch := make([]Activity, len(chunk))
+		for j, i := range chunk {
+			batch[j] = activities[i]
+		}
+		resp, err := c.addActivities(ctx, feed, batch...)
+		if err != nil {
+			return err
+		}
+		for j, i := range chunk {
+			if j < len(resp.Activities) {
+				result.Activities[i] = resp.Activities[j]
+			}
+		}
+		return nil
+	})
+	return result, result.Err()
+}
+
+// BulkUpdateActivities updates the given activities in chunks,
+// DefaultActivitiesChunkSize by default, reporting which ones were updated and
+// which failed. A *BulkError is returned along with the result if any failed.
+func (c *Client) BulkUpdateActivities(activities []Activity, config BulkConfig) (*BulkResult, error) {
+	return c.BulkUpdateActivitiesContext(context.Background(), activities, config)
+}
+
+// BulkUpdateActivitiesContext is like BulkUpdateActivities, using the provided context for the requests.
+func (c *Client) BulkUpdateActivitiesContext(ctx context.Context, activities []Activity, config BulkConfig) (*BulkResult, error) {
+	result := &BulkResult{}
+	items := c.validActivities(activities, true, result)
+	runBulk(ctx, items, DefaultActivitiesChunkSize, config, result, func(ctx context.Context, chunk []int) error {
+		batch := make([]Activity, len(chunk))
+		for j, i := range chunk {
+			batch[j] = activities[i]
+		}
+		return c.UpdateActivitiesContext(ctx, batch...)
+	})
+	return result, result.Err()
+}
+
+// BulkPartialUpdateActivities performs the given partial updates in chunks,
+// DefaultActivitiesChunkSize by default, reporting which ones were performed
+// and which failed. A *BulkError is returned along with the result if any
+// failed.
+func (c *Client) BulkPartialUpdateActivities(changesets []UpdateActivityRequest, config BulkConfig) (*BulkActivitiesResult, error) {
+	return c.BulkPartialUpdateActivitiesContext(context.Background(), changesets, config)
+}
+
+// BulkPartialUpdateActivitiesContext is like BulkPartialUpdateActivities, using the provided context for the requests.
+func (c *Client) BulkPartialUpdateActivitiesContext(ctx context.Context, changesets []UpdateActivityRequest, config BulkConfig) (*BulkActivitiesResult, error) {
+	result := &BulkActivitiesResult{Activities: make([]Activity, len(changesets))}
+	runBulk(ctx, positions(len(changesets)), DefaultActivitiesChunkSize, config, &result.BulkResult, func(ctx context.Context, chunk []int) error {
+		batch := make([]UpdateActivityRequest, len(chunk))
+		for j, i := range chunk {
+			batch[j] = changesets[i]
+		}
+		resp, err := c.PartialUpdateActivitiesContext(ctx, batch...)
+		if err != nil {
+			return err
+		}
+		for j, i := range chunk {
+			if j < len(resp.Activities) && resp.Activities[j] != nil {
+				result.Activities[i] = *resp.Activities[j]
+			}
+		}
+		return nil
+	})
+	return result, result.Err()
+}
+
+// BulkAddToMany adds the activity to the given feeds in chunks of feeds,
+// DefaultAddToManyChunkSize by default, reporting which feeds it was added to
+// and which failed. A *BulkError is returned along with the result if any
+// failed.
+func (c *Client) BulkAddToMany(activity Activity, feeds []Feed, config BulkConfig) (*BulkResult, error) {
+	return c.BulkAddToManyContext(context.Background(), activity, feeds, config)
+}
+
+// BulkAddToManyContext is like BulkAddToMany, using the provided context for the requests.
+func (c *Client) BulkAddToManyContext(ctx context.Context, activity Activity, feeds []Feed, config BulkConfig) (*BulkResult, error) {
+	result := &BulkResult{}
+	if err := c.validateActivities(false, activity); err != nil {
+		for i := range feeds {
+			result.Failed = append(result.Failed, BulkItemError{Index: i, Err: err})
+		}
+		return result, result.Err()
+	}
+	runBulk(ctx, positions(len(feeds)), DefaultAddToManyChunkSize, config, result, func(ctx context.Context, chunk []int) error {
+		batch := make([]Feed, len(chunk))
+		for j, i := range chunk {
+			batch[j] = feeds[i]
+		}
+		return c.AddToManyContext(ctx, activity, batch...)
+	})
+	return result, result.Err()
+}
+
+// BulkFollowMany creates the given follow relationships in chunks,
+// DefaultFollowManyChunkSize by default, reporting which ones were created and
+// which failed. A *BulkError is returned along with the result if any failed.
+func (c *Client) BulkFollowMany(relationships []FollowRelationship, config BulkConfig, opts ...FollowManyOption) (*BulkResult, error) {
+	return c.BulkFollowManyContext(context.Background(), relationships, config, opts...)
+}
+
+// BulkFollowManyContext is like BulkFollowMany, using the provided context for the requests.
+func (c *Client) BulkFollowManyContext(ctx context.Context, relationships []FollowRelationship, config BulkConfig, opts ...FollowManyOption) (*BulkResult, error) {
+	result := &BulkResult{}
+	runBulk(ctx, positions(len(relationships)), DefaultFollowManyChunkSize, config, result, func(ctx context.Context, chunk []int) error {
+		batch := make([]FollowRelationship, len(chunk))
+		for j, i := range chunk {
+			batch[j] = relationships[i]
+		}
+		return c.FollowManyContext(ctx, batch, opts...)
+	})
+	return result, result.Err()
+}
+
+// BulkUnfollowMany removes the given follow relationships in chunks,
+// DefaultUnfollowManyChunkSize by default, reporting which ones were removed and
+// which failed. A *BulkError is returned along with the result if any failed.
+func (c *Client) BulkUnfollowMany(relationships []UnfollowRelationship, config BulkConfig) (*BulkResult, error) {
+	return c.BulkUnfollowManyContext(context.Background(), relationships, config)
+}
+
+// BulkUnfollowManyContext is like BulkUnfollowMany, using the provided context for the requests.
+func (c *Client) BulkUnfollowManyContext(ctx context.Context, relationships []UnfollowRelationship, config BulkConfig) (*BulkResult, error) {
+	result := &BulkResult{}
+	runBulk(ctx, positions(len(relationships)), DefaultUnfollowManyChunkSize, config, result, func(ctx context.Context, chunk []int) error {
+		batch := make([]UnfollowRelationship, len(chunk))
+		for j, i := range chunk {
+			batch[j] = relationships[i]
+		}
+		return c.UnfollowManyContext(ctx, batch)
+	})
+	return result, result.Err()
+}
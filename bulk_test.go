@@ -0,0 +1,201 @@
+package stream_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bulkRecorder is a middleware recording the calls, which fails the ones
+// whose body mentions "fail".
+type bulkRecorder struct {
+	mu          sync.Mutex
+	calls       []*stream.Call
+	running     int
+	maxRunning  int
+	callLatency time.Duration
+}
+
+func (r *bulkRecorder) middleware(next stream.Handler) stream.Handler {
+	return func(ctx context.Context, call *stream.Call) error {
+		r.mu.Lock()
+		r.calls = append(r.calls, call)
+		r.running++
+		if r.running > r.maxRunning {
+			r.maxRunning = r.running
+		}
+		r.mu.Unlock()
+		defer func() {
+			r.mu.Lock()
+			r.running--
+			r.mu.Unlock()
+		}()
+		time.Sleep(r.callLatency)
+
+		body, err := json.Marshal(call.Body)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(body), "fail") {
+			return errors.New("boom")
+		}
+		return next(ctx, call)
+	}
+}
+
+func newBulkClient(t *testing.T, opts ...stream.ClientOption) (*stream.Client, *bulkRecorder) {
+	recorder := &bulkRecorder{callLatency: 5 * time.Millisecond}
+	client, err := streamtest.New().NewClient(append(opts, stream.WithMiddleware(recorder.middleware))...)
+	require.NoError(t, err)
+	return client, recorder
+}
+
+func TestBulkAddActivities(t *testing.T) {
+	client, recorder := newBulkClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	activities := make([]stream.Activity, 10)
+	for i := range activities {
+		activities[i] = stream.Activity{Actor: "bob", Verb: "post", Object: fmt.Sprintf("post:%d", i)}
+	}
+	activities[4].Object = "fail"
+
+	result, err := client.BulkAddActivities(feed, activities, stream.BulkConfig{ChunkSize: 3, Concurrency: 2})
+	require.Error(t, err)
+	assert.EqualError(t, err, "3 of 10 items failed: boom")
+	var bulkErr *stream.BulkError
+	require.True(t, errors.As(err, &bulkErr))
+	assert.Equal(t, 3, bulkErr.Failed)
+
+	assert.Len(t, recorder.calls, 4)
+	assert.Equal(t, 2, recorder.maxRunning)
+	assert.Equal(t, []int{0, 1, 2, 6, 7, 8, 9}, result.Succeeded)
+	require.Len(t, result.Failed, 3)
+	for i, failed := range result.Failed {
+		assert.Equal(t, 3+i, failed.Index)
+		assert.EqualError(t, failed.Err, "boom")
+	}
+	for i, activity := range result.Activities {
+		if i >= 3 && i <= 5 {
+			assert.Empty(t, activity.ID)
+			continue
+		}
+		assert.NotEmpty(t, activity.ID)
+		assert.Equal(t, activities[i].Object, activity.Object)
+	}
+
+	resp, err := feed.GetActivities(stream.WithActivitiesLimit(20))
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 7)
+}
+
+func TestBulkAddActivitiesValidation(t *testing.T) {
+	client, recorder := newBulkClient(t, stream.WithActivityValidation(stream.ValidationConfig{}))
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	activities := []stream.Activity{
+		{Actor: "bob", Verb: "post", Object: "post:1"},
+		{Actor: "bob", Verb: "post"},
+		{Actor: "bob", Verb: "post", Object: "post:3"},
+	}
+
+	result, err := client.BulkAddActivities(feed, activities, stream.BulkConfig{})
+	assert.True(t, errors.Is(err, stream.ErrValidation))
+	assert.Len(t, recorder.calls, 1)
+	assert.Equal(t, []int{0, 2}, result.Succeeded)
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, 1, result.Failed[0].Index)
+	var verr *stream.ActivityValidationError
+	require.True(t, errors.As(result.Failed[0].Err, &verr))
+	assert.Equal(t, 1, verr.Activities[0].Index)
+}
+
+func TestBulkWrites(t *testing.T) {
+	client, recorder := newBulkClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	now := stream.Time{Time: time.Now().UTC().Truncate(time.Second)}
+	activities := make([]stream.Activity, 5)
+	for i := range activities {
+		activities[i] = stream.Activity{Actor: "bob", Verb: "post", Object: "post", ForeignID: fmt.Sprintf("post:%d", i), Time: now}
+	}
+	added, err := client.BulkAddActivities(feed, activities, stream.BulkConfig{})
+	require.NoError(t, err)
+	assert.Len(t, added.Succeeded, 5)
+	assert.Len(t, recorder.calls, 1)
+
+	activities[1].Extra = map[string]interface{}{"status": "fail"}
+	updated, err := client.BulkUpdateActivities(activities, stream.BulkConfig{ChunkSize: 2})
+	assert.Error(t, err)
+	assert.Equal(t, []int{2, 3, 4}, updated.Succeeded)
+	assert.Len(t, updated.Failed, 2)
+
+	changesets := make([]stream.UpdateActivityRequest, 5)
+	for i, activity := range added.Activities {
+		changesets[i] = stream.NewUpdateActivityRequestByID(activity.ID, map[string]interface{}{"status": "edited"}, nil)
+	}
+	partial, err := client.BulkPartialUpdateActivities(changesets, stream.BulkConfig{ChunkSize: 2, Concurrency: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, partial.Succeeded)
+	for i, activity := range partial.Activities {
+		assert.Equal(t, added.Activities[i].ID, activity.ID)
+		assert.Equal(t, "edited", activity.Extra["status"])
+	}
+
+	feeds := make([]stream.Feed, 5)
+	for i := range feeds {
+		feeds[i], err = client.FlatFeed("timeline", fmt.Sprintf("user%d", i))
+		require.NoError(t, err)
+	}
+	feeds[3], err = client.FlatFeed("timeline", "fail")
+	require.NoError(t, err)
+	many, err := client.BulkAddToMany(stream.Activity{Actor: "bob", Verb: "post", Object: "post:6"}, feeds, stream.BulkConfig{ChunkSize: 2})
+	assert.Error(t, err)
+	assert.Equal(t, []int{0, 1, 4}, many.Succeeded)
+	assert.Len(t, many.Failed, 2)
+
+	follows := make([]stream.FollowRelationship, 5)
+	unfollows := make([]stream.UnfollowRelationship, 5)
+	for i := range follows {
+		follows[i] = stream.NewFollowRelationship(feeds[i], feed)
+		unfollows[i] = stream.UnfollowRelationship{Source: feeds[i].ID(), Target: feed.ID()}
+	}
+	followed, err := client.BulkFollowMany(follows, stream.BulkConfig{ChunkSize: 1}, stream.WithFollowManyActivityCopyLimit(0))
+	assert.Error(t, err)
+	assert.Equal(t, []int{0, 1, 2, 4}, followed.Succeeded)
+	followers, err := feed.GetFollowers()
+	require.NoError(t, err)
+	assert.Len(t, followers.Results, 4)
+
+	unfollowed, err := client.BulkUnfollowMany(unfollows[:3], stream.BulkConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, unfollowed.Succeeded)
+	followers, err = feed.GetFollowers()
+	require.NoError(t, err)
+	assert.Len(t, followers.Results, 1)
+}
+
+func TestBulkCanceled(t *testing.T) {
+	client, recorder := newBulkClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	follows := make([]stream.FollowRelationship, 3)
+	for i := range follows {
+		follows[i] = stream.FollowRelationship{Source: fmt.Sprintf("timeline:user%d", i), Target: "user:bob"}
+	}
+	result, err := client.BulkFollowManyContext(ctx, follows, stream.BulkConfig{ChunkSize: 1, Concurrency: 1})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, result.Succeeded)
+	assert.Len(t, result.Failed, 3)
+	assert.Empty(t, recorder.calls)
+}
@@ -0,0 +1,232 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenTimeout      = 30 * time.Second
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// The circuit breaker states.
+const (
+	// CircuitClosed lets requests through, counting consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until the open timeout expires.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe requests through to
+	// check whether the API recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// CircuitBreakerConfig configures the circuit breakers of a Client.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed requests opening
+	// the circuit. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before letting probe
+	// requests through. Defaults to 30s.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of successful probe requests closing the
+	// circuit again. Defaults to 1.
+	HalfOpenRequests int
+	// OnStateChange, if set, is called whenever a circuit changes state. The
+	// name identifies the circuit, e.g. "api.stream-io-api.com feed".
+	OnStateChange func(name string, from, to CircuitState)
+}
+
+// WithCircuitBreaker enables circuit breaking for a given Client, with a
+// circuit for each API host and resource. Transport failures and 5xx responses
+// count as failures: once too many of them happen in a row the circuit opens,
+// and requests fail fast with a *CircuitOpenError until it closes again.
+func WithCircuitBreaker(config CircuitBreakerConfig) ClientOption {
+	return func(c *Client) {
+		if config.FailureThreshold <= 0 {
+			config.FailureThreshold = defaultCircuitFailureThreshold
+		}
+		if config.OpenTimeout <= 0 {
+			config.OpenTimeout = defaultCircuitOpenTimeout
+		}
+		if config.HalfOpenRequests <= 0 {
+			config.HalfOpenRequests = 1
+		}
+		c.breakers = newCircuitBreakers(config)
+	}
+}
+
+// CircuitOpenError is returned when a request is rejected because its circuit
+// is open.
+type CircuitOpenError struct {
+	// Circuit is the name of the open circuit.
+	Circuit string
+	// RetryAt is the time at which the circuit lets requests through again.
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s", e.Circuit)
+}
+
+type circuitBreakers struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type circuit struct {
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+func newCircuitBreakers(config CircuitBreakerConfig) *circuitBreakers {
+	return &circuitBreakers{
+		config:   config,
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+	}
+}
+
+// circuitName identifies the circuit of an endpoint.
+func circuitName(e endpoint) string {
+	return fmt.Sprintf("%s %s", e.url.Host, e.resource)
+}
+
+// allow tells whether a request can go through the given circuit, returning a
+// *CircuitOpenError if it can't.
+func (b *circuitBreakers) allow(name string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	c := b.circuit(name)
+	var changed bool
+	from := c.state
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.config.OpenTimeout)) {
+		c.state = CircuitHalfOpen
+		c.probes = 0
+		c.successes = 0
+		changed = true
+	}
+	var err error
+	switch c.state {
+	case CircuitOpen:
+		err = &CircuitOpenError{Circuit: name, RetryAt: c.openedAt.Add(b.config.OpenTimeout)}
+	case CircuitHalfOpen:
+		if c.probes+c.successes >= b.config.HalfOpenRequests {
+			err = &CircuitOpenError{Circuit: name, RetryAt: b.now()}
+		} else {
+			c.probes++
+		}
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	if changed {
+		b.notify(name, from, to)
+	}
+	return err
+}
+
+// record reports the outcome of a request which went through the given
+// circuit.
+func (b *circuitBreakers) record(name string, failed bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	c := b.circuit(name)
+	from := c.state
+	switch c.state {
+	case CircuitClosed:
+		if !failed {
+			c.failures = 0
+		} else if c.failures++; c.failures >= b.config.FailureThreshold {
+			b.open(c)
+		}
+	case CircuitHalfOpen:
+		if c.probes > 0 {
+			c.probes--
+		}
+		if failed {
+			b.open(c)
+		} else if c.successes++; c.successes >= b.config.HalfOpenRequests {
+			c.state = CircuitClosed
+			c.failures = 0
+		}
+	}
+	to := c.state
+	b.mu.Unlock()
+
+	if from != to {
+		b.notify(name, from, to)
+	}
+}
+
+// release gives back the slot taken by a request whose outcome doesn't tell
+// anything about the API health, e.g. because its context was canceled.
+func (b *circuitBreakers) release(name string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c := b.circuit(name); c.state == CircuitHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+func (b *circuitBreakers) open(c *circuit) {
+	c.state = CircuitOpen
+	c.openedAt = b.now()
+	c.failures = 0
+}
+
+func (b *circuitBreakers) circuit(name string) *circuit {
+	c, ok := b.circuits[name]
+	if !ok {
+		c = &circuit{}
+		b.circuits[name] = c
+	}
+	return c
+}
+
+func (b *circuitBreakers) notify(name string, from, to CircuitState) {
+	if b.config.OnStateChange != nil {
+		b.config.OnStateChange(name, from, to)
+	}
+}
+
+func isCircuitOpen(err error) bool {
+	var e *CircuitOpenError
+	return errors.As(err, &e)
+}
+
+// isCircuitFailure tells whether a failed attempt, identified by its (possibly
+// nil) response, is a symptom of the API being degraded.
+func isCircuitFailure(resp *http.Response) bool {
+	return resp == nil || resp.StatusCode/100 == 5
+}
@@ -0,0 +1,95 @@
+package stream
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type circuitTransition struct {
+	name     string
+	from, to CircuitState
+}
+
+func TestCircuitBreakers(t *testing.T) {
+	now := time.Unix(1546300800, 0)
+	var transitions []circuitTransition
+	b := newCircuitBreakers(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenRequests: 2,
+		OnStateChange: func(name string, from, to CircuitState) {
+			transitions = append(transitions, circuitTransition{name, from, to})
+		},
+	})
+	b.now = func() time.Time { return now }
+
+	// successes reset the consecutive failures count
+	require.NoError(t, b.allow("a"))
+	b.record("a", true)
+	require.NoError(t, b.allow("a"))
+	b.record("a", false)
+	require.NoError(t, b.allow("a"))
+	b.record("a", true)
+	assert.Empty(t, transitions)
+
+	require.NoError(t, b.allow("a"))
+	b.record("a", true)
+	assert.Equal(t, []circuitTransition{{"a", CircuitClosed, CircuitOpen}}, transitions)
+
+	err := b.allow("a")
+	require.IsType(t, &CircuitOpenError{}, err)
+	assert.Equal(t, "a", err.(*CircuitOpenError).Circuit)
+	assert.Equal(t, now.Add(10*time.Second), err.(*CircuitOpenError).RetryAt)
+	assert.Equal(t, "circuit breaker open for a", err.Error())
+	// other circuits aren't affected
+	require.NoError(t, b.allow("b"))
+
+	// a failed probe opens the circuit again
+	now = now.Add(10 * time.Second)
+	require.NoError(t, b.allow("a"))
+	b.record("a", true)
+	assert.Error(t, b.allow("a"))
+
+	// probes are limited while half-open
+	now = now.Add(10 * time.Second)
+	require.NoError(t, b.allow("a"))
+	require.NoError(t, b.allow("a"))
+	assert.Error(t, b.allow("a"))
+	b.release("a")
+	require.NoError(t, b.allow("a"))
+	b.record("a", false)
+	b.record("a", false)
+	require.NoError(t, b.allow("a"))
+
+	expected := []circuitTransition{
+		{"a", CircuitClosed, CircuitOpen},
+		{"a", CircuitOpen, CircuitHalfOpen},
+		{"a", CircuitHalfOpen, CircuitOpen},
+		{"a", CircuitOpen, CircuitHalfOpen},
+		{"a", CircuitHalfOpen, CircuitClosed},
+	}
+	assert.Equal(t, expected, transitions)
+
+	var nilBreakers *circuitBreakers
+	assert.NoError(t, nilBreakers.allow("a"))
+	nilBreakers.record("a", true)
+	nilBreakers.release("a")
+}
+
+func TestCircuitState_String(t *testing.T) {
+	assert.Equal(t, "closed", CircuitClosed.String())
+	assert.Equal(t, "open", CircuitOpen.String())
+	assert.Equal(t, "half-open", CircuitHalfOpen.String())
+	assert.Equal(t, "CircuitState(42)", CircuitState(42).String())
+}
+
+func Test_isCircuitFailure(t *testing.T) {
+	assert.True(t, isCircuitFailure(nil))
+	assert.True(t, isCircuitFailure(&http.Response{StatusCode: http.StatusBadGateway}))
+	assert.False(t, isCircuitFailure(&http.Response{StatusCode: http.StatusTooManyRequests}))
+	assert.False(t, isCircuitFailure(&http.Response{StatusCode: http.StatusNotFound}))
+}
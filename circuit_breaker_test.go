@@ -0,0 +1,86 @@
+package stream_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusInternalServerError, body: `{"detail":"boom"}`},
+		{code: http.StatusBadGateway, body: `{"detail":"bad gateway"}`},
+		{code: http.StatusOK},
+		{code: http.StatusOK},
+	}}
+	var changes []string
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRetryPolicy(stream.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+		stream.WithCircuitBreaker(stream.CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OpenTimeout:      time.Minute,
+			OnStateChange: func(name string, from, to stream.CircuitState) {
+				changes = append(changes, name+": "+from.String()+" -> "+to.String())
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	// the circuit opens during the retries
+	_, err = client.Users().Get("bob")
+	require.Error(t, err)
+	openErr, ok := err.(*stream.CircuitOpenError)
+	require.True(t, ok)
+	assert.Equal(t, "api.stream-io-api.com users", openErr.Circuit)
+	assert.Len(t, requester.reqs, 2)
+	assert.Equal(t, []string{"api.stream-io-api.com users: closed -> open"}, changes)
+
+	_, err = client.Users().Get("bob")
+	assert.IsType(t, &stream.CircuitOpenError{}, err)
+	assert.Len(t, requester.reqs, 2)
+
+	// other resources have their own circuit
+	_, err = client.Reactions().Get("abc")
+	require.NoError(t, err)
+	assert.Len(t, requester.reqs, 3)
+}
+
+func TestCircuitBreakerRateLimiter(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusInternalServerError, body: `{"detail":"boom"}`},
+	}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRetryPolicy(stream.RetryPolicy{MaxAttempts: 1}),
+		stream.WithCircuitBreaker(stream.CircuitBreakerConfig{
+			FailureThreshold: 1,
+			OpenTimeout:      time.Minute,
+		}),
+		stream.WithRateLimiter(stream.RateLimiterConfig{
+			Limits: map[stream.Resource]stream.Limit{
+				stream.ResourceUsers: {Rate: 0.1, Burst: 2},
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Users().Get("bob")
+	require.Error(t, err)
+	assert.Len(t, requester.reqs, 1)
+
+	// calls rejected by the open circuit don't use the limiter tokens, so they
+	// keep failing fast instead of waiting for the limiter
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		_, err = client.Users().GetContext(ctx, "bob")
+		assert.IsType(t, &stream.CircuitOpenError{}, err)
+	}
+	assert.Len(t, requester.reqs, 1)
+}
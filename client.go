@@ -2,6 +2,7 @@ package stream
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +10,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	jwt "gopkg.in/dgrijalva/jwt-go.v3"
 )
@@ -23,6 +27,17 @@ type Client struct {
 	urlBuilder    urlBuilder
 	region        string
 	version       string
+	baseURLs      baseURLs
+	retryPolicy   RetryPolicy
+	rateLimits    *rateLimitStore
+	limiter       *rateLimiter
+	middleware    []Middleware
+	tracer        Tracer
+	metrics       MetricsCollector
+	breakers      *circuitBreakers
+	validation    *ValidationConfig
+	compression   *CompressionConfig
+	numbers       NumberDecoding
 }
 
 var _ ClientInterface = &Client{}
@@ -44,23 +59,31 @@ func NewClient(key, secret string, opts ...ClientOption) (*Client, error) {
 			Transport: &http.Transport{},
 		},
 		authenticator: authenticator{secret: secret},
+		rateLimits:    newRateLimitStore(),
 	}
 	for _, opt := range opts {
 		opt(c)
 	}
-	c.urlBuilder = newAPIURLBuilder(c.region, c.version)
+	c.urlBuilder = newAPIURLBuilder(c.region, c.version, c.baseURLs.api)
 	return c, nil
 }
 
 // NewClientFromEnv build a new Client using environment variables values, with
 // possible values being STREAM_API_KEY, STREAM_API_SECRET, STREAM_API_REGION,
-// and STREAM_API_VERSION.
-func NewClientFromEnv() (*Client, error) {
+// STREAM_API_VERSION, and STREAM_URL (overriding the base URL of all the APIs).
+// As in previous versions, STREAM_URL is used verbatim as the URL of the
+// Personalization API, without appending its path and version.
+// Additional options can be provided, overriding the environment values.
+func NewClientFromEnv(opts ...ClientOption) (*Client, error) {
 	key := os.Getenv("STREAM_API_KEY")
 	secret := os.Getenv("STREAM_API_SECRET")
 	region := os.Getenv("STREAM_API_REGION")
 	version := os.Getenv("STREAM_API_VERSION")
-	return NewClient(key, secret, WithAPIRegion(region), WithAPIVersion(version))
+	envOpts := []ClientOption{WithAPIRegion(region), WithAPIVersion(version)}
+	if baseURL := os.Getenv("STREAM_URL"); baseURL != "" {
+		envOpts = append(envOpts, WithBaseURL(baseURL), WithAnalyticsURL(baseURL), withPersonalizationEndpoint(baseURL))
+	}
+	return NewClient(key, secret, append(envOpts, opts...)...)
 }
 
 // ClientOption is a function used for adding specific configuration options to
@@ -81,6 +104,52 @@ func WithAPIVersion(version string) ClientOption {
 	}
 }
 
+// baseURLs are the base URLs overriding the regional hosts of the Stream APIs.
+type baseURLs struct {
+	api             string
+	analytics       string
+	personalization string
+	// personalizationEndpoint, if set, is the full URL of the Personalization
+	// API, used verbatim.
+	personalizationEndpoint string
+}
+
+// WithBaseURL sets the base URL of the Stream API for a given Client (e.g.
+// "http://localhost:8000"), overriding the regional host. The API path and
+// version are appended to it.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURLs.api = baseURL
+	}
+}
+
+// WithAnalyticsURL sets the base URL of the Stream Analytics API for a given
+// Client, overriding the regional host. The API path and version are appended
+// to it.
+func WithAnalyticsURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURLs.analytics = baseURL
+	}
+}
+
+// WithPersonalizationURL sets the base URL of the Stream Personalization API for
+// a given Client, overriding the regional host. The API path and version are
+// appended to it.
+func WithPersonalizationURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURLs.personalization = baseURL
+		c.baseURLs.personalizationEndpoint = ""
+	}
+}
+
+// withPersonalizationEndpoint sets the full URL of the Stream Personalization
+// API, used verbatim, as STREAM_URL always was.
+func withPersonalizationEndpoint(endpoint string) ClientOption {
+	return func(c *Client) {
+		c.baseURLs.personalizationEndpoint = endpoint
+	}
+}
+
 // WithHTTPRequester sets the HTTP requester for a given client, used mostly for testing.
 func WithHTTPRequester(requester Requester) ClientOption {
 	return func(c *Client) {
@@ -89,7 +158,7 @@ func WithHTTPRequester(requester Requester) ClientOption {
 }
 
 // FlatFeed returns a new Flat Feed with the provided slug and userID.
-func (c *Client) FlatFeed(slug, userID string) (*FlatFeed, error) {
+func (c *Client) FlatFeed(slug, userID string) (FlatFeedInterface, error) {
 	feed, err := newFeed(slug, userID, c)
 	if err != nil {
 		return nil, err
@@ -99,7 +168,7 @@ func (c *Client) FlatFeed(slug, userID string) (*FlatFeed, error) {
 
 // AggregatedFeed returns a new Aggregated Feed with the provided slug and
 // userID.
-func (c *Client) AggregatedFeed(slug, userID string) (*AggregatedFeed, error) {
+func (c *Client) AggregatedFeed(slug, userID string) (AggregatedFeedInterface, error) {
 	feed, err := newFeed(slug, userID, c)
 	if err != nil {
 		return nil, err
@@ -109,7 +178,7 @@ func (c *Client) AggregatedFeed(slug, userID string) (*AggregatedFeed, error) {
 
 // NotificationFeed returns a new Notification Feed with the provided slug and
 // userID.
-func (c *Client) NotificationFeed(slug, userID string) (*NotificationFeed, error) {
+func (c *Client) NotificationFeed(slug, userID string) (NotificationFeedInterface, error) {
 	feed, err := newFeed(slug, userID, c)
 	if err != nil {
 		return nil, err
@@ -119,7 +188,16 @@ func (c *Client) NotificationFeed(slug, userID string) (*NotificationFeed, error
 
 // AddToMany adds an activity to multiple feeds at once.
 func (c *Client) AddToMany(activity Activity, feeds ...Feed) error {
-	endpoint := c.makeEndpoint("feed/add_to_many/")
+	return c.AddToManyContext(context.Background(), activity, feeds...)
+}
+
+// AddToManyContext is like AddToMany, using the provided context for the request.
+func (c *Client) AddToManyContext(ctx context.Context, activity Activity, feeds ...Feed) error {
+	if err := c.validateActivities(false, activity); err != nil {
+		return err
+	}
+	endpoint := c.makeEndpoint(ResourceFeed, "feed/add_to_many/")
+	endpoint.operation = "feed.add_to_many"
 	ids := make([]string, len(feeds))
 	for i := range feeds {
 		ids[i] = feeds[i].ID()
@@ -128,25 +206,37 @@ func (c *Client) AddToMany(activity Activity, feeds ...Feed) error {
 		Activity: activity,
 		FeedIDs:  ids,
 	}
-	_, err := c.post(endpoint, req, c.authenticator.feedAuth(resFeed, nil))
-	return err
+	endpoint.idempotent = activity.isIdempotent()
+	return c.post(ctx, endpoint, req, nil, c.authenticator.feedAuth(ResourceFeed, nil))
 }
 
 // FollowMany creates multiple follows at once.
 func (c *Client) FollowMany(relationships []FollowRelationship, opts ...FollowManyOption) error {
-	endpoint := c.makeEndpoint("follow_many/")
+	return c.FollowManyContext(context.Background(), relationships, opts...)
+}
+
+// FollowManyContext is like FollowMany, using the provided context for the request.
+func (c *Client) FollowManyContext(ctx context.Context, relationships []FollowRelationship, opts ...FollowManyOption) error {
+	endpoint := c.makeEndpoint(ResourceFollower, "follow_many/")
+	endpoint.operation = "feed.follow_many"
+	endpoint.idempotent = true
 	for _, opt := range opts {
 		endpoint.addQueryParam(opt)
 	}
-	_, err := c.post(endpoint, relationships, c.authenticator.feedAuth(resFollower, nil))
-	return err
+	return c.post(ctx, endpoint, relationships, nil, c.authenticator.feedAuth(ResourceFollower, nil))
 }
 
 // UnfollowMany removes multiple follow relationships at once.
 func (c *Client) UnfollowMany(relationships []UnfollowRelationship) error {
-	endpoint := c.makeEndpoint("unfollow_many/")
-	_, err := c.post(endpoint, relationships, c.authenticator.feedAuth(resFollower, nil))
-	return err
+	return c.UnfollowManyContext(context.Background(), relationships)
+}
+
+// UnfollowManyContext is like UnfollowMany, using the provided context for the request.
+func (c *Client) UnfollowManyContext(ctx context.Context, relationships []UnfollowRelationship) error {
+	endpoint := c.makeEndpoint(ResourceFollower, "unfollow_many/")
+	endpoint.operation = "feed.unfollow_many"
+	endpoint.idempotent = true
+	return c.post(ctx, endpoint, relationships, nil, c.authenticator.feedAuth(ResourceFollower, nil))
 }
 
 func (c *Client) cloneWithURLBuilder(builder urlBuilder) *Client {
@@ -155,70 +245,169 @@ func (c *Client) cloneWithURLBuilder(builder urlBuilder) *Client {
 		requester:     c.requester,
 		authenticator: c.authenticator,
 		urlBuilder:    builder,
+		region:        c.region,
+		version:       c.version,
+		baseURLs:      c.baseURLs,
+		retryPolicy:   c.retryPolicy,
+		rateLimits:    c.rateLimits,
+		limiter:       c.limiter,
+		middleware:    c.middleware,
+		tracer:        c.tracer,
+		metrics:       c.metrics,
+		breakers:      c.breakers,
+		validation:    c.validation,
+		compression:   c.compression,
+		numbers:       c.numbers,
 	}
 }
 
 // Analytics returns a new AnalyticsClient sharing the base configuration of the original Client.
-func (c *Client) Analytics() *AnalyticsClient {
-	b := newAnalyticsURLBuilder(c.region, c.version)
+func (c *Client) Analytics() AnalyticsClientInterface {
+	b := newAnalyticsURLBuilder(c.region, c.version, c.baseURLs.analytics)
 	return &AnalyticsClient{client: c.cloneWithURLBuilder(b)}
 }
 
 // Collections returns a new CollectionsClient.
-func (c *Client) Collections() *CollectionsClient {
-	b := newAPIURLBuilder(c.region, c.version)
+func (c *Client) Collections() CollectionsClientInterface {
+	b := newAPIURLBuilder(c.region, c.version, c.baseURLs.api)
 	return &CollectionsClient{client: c.cloneWithURLBuilder(b)}
 }
 
 // Users returns a new UsersClient.
-func (c *Client) Users() *UsersClient {
-	b := newAPIURLBuilder(c.region, c.version)
+func (c *Client) Users() UsersClientInterface {
+	b := newAPIURLBuilder(c.region, c.version, c.baseURLs.api)
 	return &UsersClient{client: c.cloneWithURLBuilder(b)}
 }
 
 // Reactions returns a new ReactionsClient.
-func (c *Client) Reactions() *ReactionsClient {
-	b := newAPIURLBuilder(c.region, c.version)
+func (c *Client) Reactions() ReactionsClientInterface {
+	b := newAPIURLBuilder(c.region, c.version, c.baseURLs.api)
 	return &ReactionsClient{client: c.cloneWithURLBuilder(b)}
 }
 
 // Personalization returns a new PersonalizationClient.
-func (c *Client) Personalization() *PersonalizationClient {
-	b := newPersonalizationURLBuilder(c.region)
+func (c *Client) Personalization() PersonalizationClientInterface {
+	b := newPersonalizationURLBuilder(c.region, c.baseURLs.personalization)
+	b.endpoint = c.baseURLs.personalizationEndpoint
 	return &PersonalizationClient{client: c.cloneWithURLBuilder(b)}
 }
 
 // GetActivitiesByID returns activities for the current app having the given IDs.
+// Large lookups are split in chunks, requested concurrently. Activities are
+// returned in the order of the given IDs, and the IDs not found are listed in
+// the Missing field of the response.
 func (c *Client) GetActivitiesByID(ids ...string) (*GetActivitiesResponse, error) {
-	return c.getAppActivities(makeRequestOption("ids", strings.Join(ids, ",")))
+	return c.GetActivitiesByIDContext(context.Background(), ids...)
+}
+
+// GetActivitiesByIDContext is like GetActivitiesByID, using the provided context for the request.
+func (c *Client) GetActivitiesByIDContext(ctx context.Context, ids ...string) (*GetActivitiesResponse, error) {
+	ids = uniqueStrings(ids)
+	found := make(map[string]Activity, len(ids))
+	var (
+		mu   sync.Mutex
+		resp GetActivitiesResponse
+	)
+	err := runChunkedRead(ctx, len(ids), func(ctx context.Context, chunk []int) error {
+		batch := make([]string, len(chunk))
+		for j, i := range chunk {
+			batch[j] = ids[i]
+		}
+		chunkResp, err := c.getAppActivities(ctx, makeRequestOption("ids", strings.Join(batch, ",")))
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		resp.mergeChunk(chunkResp.response)
+		for _, activity := range chunkResp.Results {
+			found[activity.ID] = activity
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Results = make([]Activity, 0, len(found))
+	for _, id := range ids {
+		if activity, ok := found[id]; ok {
+			resp.Results = append(resp.Results, activity)
+		} else {
+			resp.Missing = append(resp.Missing, id)
+		}
+	}
+	return &resp, nil
 }
 
 // GetActivitiesByForeignID returns activities for the current app having the given foreign IDs and timestamps.
+// Large lookups are split in chunks, requested concurrently. Activities are
+// returned in the order of the given pairs, and the foreign IDs of the pairs
+// not found are listed in the Missing field of the response.
 func (c *Client) GetActivitiesByForeignID(values ...ForeignIDTimePair) (*GetActivitiesResponse, error) {
-	foreignIDs := make([]string, len(values))
-	timestamps := make([]string, len(values))
-	for i, v := range values {
-		foreignIDs[i] = v.ForeignID
-		timestamps[i] = v.Timestamp.Format(TimeLayout)
-	}
-	return c.getAppActivities(
-		makeRequestOption("foreign_ids", strings.Join(foreignIDs, ",")),
-		makeRequestOption("timestamps", strings.Join(timestamps, ",")),
-	)
+	return c.GetActivitiesByForeignIDContext(context.Background(), values...)
 }
 
-func (c *Client) getAppActivities(values ...valuer) (*GetActivitiesResponse, error) {
-	endpoint := c.makeEndpoint("activities/")
+// GetActivitiesByForeignIDContext is like GetActivitiesByForeignID, using the provided context for the request.
+func (c *Client) GetActivitiesByForeignIDContext(ctx context.Context, values ...ForeignIDTimePair) (*GetActivitiesResponse, error) {
+	keys := make([]string, 0, len(values))
+	pairs := make(map[string]ForeignIDTimePair, len(values))
 	for _, v := range values {
-		endpoint.addQueryParam(v)
+		key := v.key()
+		if _, ok := pairs[key]; !ok {
+			keys = append(keys, key)
+			pairs[key] = v
+		}
 	}
-	data, err := c.get(endpoint, nil, c.authenticator.feedAuth(resActivities, nil))
+	found := make(map[string]Activity, len(keys))
+	var (
+		mu   sync.Mutex
+		resp GetActivitiesResponse
+	)
+	err := runChunkedRead(ctx, len(keys), func(ctx context.Context, chunk []int) error {
+		foreignIDs := make([]string, len(chunk))
+		timestamps := make([]string, len(chunk))
+		for j, i := range chunk {
+			foreignIDs[j] = pairs[keys[i]].ForeignID
+			timestamps[j] = pairs[keys[i]].Timestamp.Format(TimeLayout)
+		}
+		chunkResp, err := c.getAppActivities(
+			ctx,
+			makeRequestOption("foreign_ids", strings.Join(foreignIDs, ",")),
+			makeRequestOption("timestamps", strings.Join(timestamps, ",")),
+		)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		resp.mergeChunk(chunkResp.response)
+		for _, activity := range chunkResp.Results {
+			found[NewForeignIDTimePair(activity.ForeignID, activity.Time).key()] = activity
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	resp.Results = make([]Activity, 0, len(found))
+	for _, key := range keys {
+		if activity, ok := found[key]; ok {
+			resp.Results = append(resp.Results, activity)
+		} else {
+			resp.Missing = append(resp.Missing, pairs[key].ForeignID)
+		}
+	}
+	return &resp, nil
+}
+
+func (c *Client) getAppActivities(ctx context.Context, values ...valuer) (*GetActivitiesResponse, error) {
+	endpoint := c.makeEndpoint(ResourceActivities, "activities/")
+	endpoint.operation = "activities.get"
+	for _, v := range values {
+		endpoint.addQueryParam(v)
+	}
 	var resp GetActivitiesResponse
-	err = json.Unmarshal(data, &resp)
-	if err != nil {
+	if err := c.get(ctx, endpoint, nil, &resp, c.authenticator.feedAuth(ResourceActivities, nil)); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -226,42 +415,57 @@ func (c *Client) getAppActivities(values ...valuer) (*GetActivitiesResponse, err
 
 // UpdateActivities updates existing activities.
 func (c *Client) UpdateActivities(activities ...Activity) error {
+	return c.UpdateActivitiesContext(context.Background(), activities...)
+}
+
+// UpdateActivitiesContext is like UpdateActivities, using the provided context for the request.
+func (c *Client) UpdateActivitiesContext(ctx context.Context, activities ...Activity) error {
+	if err := c.validateActivities(true, activities...); err != nil {
+		return err
+	}
 	req := struct {
 		Activities []Activity `json:"activities,omitempty"`
 	}{
 		Activities: activities,
 	}
-	endpoint := c.makeEndpoint("activities/")
-	_, err := c.post(endpoint, req, c.authenticator.feedAuth(resActivities, nil))
-	return err
+	endpoint := c.makeEndpoint(ResourceActivities, "activities/")
+	endpoint.operation = "activities.update"
+	endpoint.idempotent = true
+	return c.post(ctx, endpoint, req, nil, c.authenticator.feedAuth(ResourceActivities, nil))
 }
 
 // PartialUpdateActivities performs a partial update on multiple activities with the given set and unset operations
 // specified by each changeset. This returns the affected activities.
 func (c *Client) PartialUpdateActivities(changesets ...UpdateActivityRequest) (*UpdateActivitiesResponse, error) {
+	return c.PartialUpdateActivitiesContext(context.Background(), changesets...)
+}
+
+// PartialUpdateActivitiesContext is like PartialUpdateActivities, using the provided context for the request.
+func (c *Client) PartialUpdateActivitiesContext(ctx context.Context, changesets ...UpdateActivityRequest) (*UpdateActivitiesResponse, error) {
 	req := struct {
 		Activities []UpdateActivityRequest `json:"changes,omitempty"`
 	}{
 		Activities: changesets,
 	}
-	endpoint := c.makeEndpoint("activity/")
-	data, err := c.post(endpoint, req, c.authenticator.feedAuth(resActivities, nil))
-	if err != nil {
-		return nil, err
-	}
+	endpoint := c.makeEndpoint(ResourceActivities, "activity/")
+	endpoint.operation = "activities.partial_update"
+	endpoint.idempotent = true
 	var resp UpdateActivitiesResponse
-	err = json.Unmarshal(data, &resp)
-	if err != nil {
+	if err := c.post(ctx, endpoint, req, &resp, c.authenticator.feedAuth(ResourceActivities, nil)); err != nil {
 		return nil, err
 	}
-
-	return &resp, err
+	return &resp, nil
 }
 
 // UpdateActivityByID performs a partial activity update with the given set and unset operations, returning the
 // affected activity, on the activity with the given ID.
 func (c *Client) UpdateActivityByID(id string, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error) {
-	return c.updateActivity(UpdateActivityRequest{
+	return c.UpdateActivityByIDContext(context.Background(), id, set, unset)
+}
+
+// UpdateActivityByIDContext is like UpdateActivityByID, using the provided context for the request.
+func (c *Client) UpdateActivityByIDContext(ctx context.Context, id string, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error) {
+	return c.updateActivity(ctx, UpdateActivityRequest{
 		ID:    &id,
 		Set:   set,
 		Unset: unset,
@@ -271,7 +475,12 @@ func (c *Client) UpdateActivityByID(id string, set map[string]interface{}, unset
 // UpdateActivityByForeignID performs a partial activity update with the given set and unset operations, returning the
 // affected activity, on the activity with the given foreign ID and timestamp.
 func (c *Client) UpdateActivityByForeignID(foreignID string, timestamp Time, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error) {
-	return c.updateActivity(UpdateActivityRequest{
+	return c.UpdateActivityByForeignIDContext(context.Background(), foreignID, timestamp, set, unset)
+}
+
+// UpdateActivityByForeignIDContext is like UpdateActivityByForeignID, using the provided context for the request.
+func (c *Client) UpdateActivityByForeignIDContext(ctx context.Context, foreignID string, timestamp Time, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error) {
+	return c.updateActivity(ctx, UpdateActivityRequest{
 		ForeignID: &foreignID,
 		Time:      &timestamp,
 		Set:       set,
@@ -279,15 +488,12 @@ func (c *Client) UpdateActivityByForeignID(foreignID string, timestamp Time, set
 	})
 }
 
-func (c *Client) updateActivity(req UpdateActivityRequest) (*UpdateActivityResponse, error) {
-	endpoint := c.makeEndpoint("activity/")
-	data, err := c.post(endpoint, req, c.authenticator.feedAuth(resActivities, nil))
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) updateActivity(ctx context.Context, req UpdateActivityRequest) (*UpdateActivityResponse, error) {
+	endpoint := c.makeEndpoint(ResourceActivities, "activity/")
+	endpoint.operation = "activities.partial_update"
+	endpoint.idempotent = true
 	var resp UpdateActivityResponse
-	err = json.Unmarshal(data, &resp)
-	if err != nil {
+	if err := c.post(ctx, endpoint, req, &resp, c.authenticator.feedAuth(ResourceActivities, nil)); err != nil {
 		return nil, err
 	}
 	_, ok := resp.Extra["duration"].(string)
@@ -297,25 +503,39 @@ func (c *Client) updateActivity(req UpdateActivityRequest) (*UpdateActivityRespo
 	return &resp, nil
 }
 
-func (c *Client) makeStreamError(statusCode int, body io.Reader) error {
+func (c *Client) makeStreamError(statusCode int, header http.Header, body io.Reader, requestURL string) error {
 	if body == nil {
-		return fmt.Errorf("invalid body")
+		return APIError{Detail: "invalid body", StatusCode: statusCode, Header: header, URL: requestURL}
 	}
 	errBody, err := ioutil.ReadAll(body)
 	if err != nil {
-		return err
+		return &TransportError{Op: "read response", URL: requestURL, Err: err}
 	}
 	var streamErr APIError
 	if err := json.Unmarshal(errBody, &streamErr); err != nil {
-		return fmt.Errorf("unexpected error (status code %d)", statusCode)
+		streamErr = APIError{Detail: fmt.Sprintf("unexpected error (status code %d)", statusCode)}
 	}
 	streamErr.StatusCode = statusCode
+	streamErr.Body = errBody
+	streamErr.Header = header
+	streamErr.URL = requestURL
+	streamErr.RateLimit = parseRateLimit(header)
 	return streamErr
 }
 
 type endpoint struct {
 	url   *url.URL
 	query url.Values
+	// resource is the API resource the endpoint belongs to.
+	resource Resource
+	// operation is the logical operation performed by calling the endpoint,
+	// used for tracing and metrics.
+	operation string
+	// feedID is the ID of the feed targeted by the endpoint, if any.
+	feedID string
+	// idempotent marks requests which can be safely retried regardless of
+	// their HTTP method.
+	idempotent bool
 }
 
 func (e endpoint) String() string {
@@ -330,7 +550,7 @@ func (e *endpoint) addQueryParam(v valuer) {
 	e.query.Add(v.values())
 }
 
-func (c *Client) makeEndpoint(format string, a ...interface{}) endpoint {
+func (c *Client) makeEndpoint(res Resource, format string, a ...interface{}) endpoint {
 	host := c.urlBuilder.url()
 
 	path := fmt.Sprintf(format, a...)
@@ -340,25 +560,26 @@ func (c *Client) makeEndpoint(format string, a ...interface{}) endpoint {
 	query.Set("api_key", c.key)
 
 	return endpoint{
-		url:   u,
-		query: query,
+		url:      u,
+		query:    query,
+		resource: res,
 	}
 }
 
-func (c *Client) get(endpoint endpoint, data interface{}, authFn authFunc) ([]byte, error) {
-	return c.request(http.MethodGet, endpoint, data, authFn)
+func (c *Client) get(ctx context.Context, endpoint endpoint, data, out interface{}, authFn authFunc) error {
+	return c.request(ctx, http.MethodGet, endpoint, data, out, authFn)
 }
 
-func (c *Client) post(endpoint endpoint, data interface{}, authFn authFunc) ([]byte, error) {
-	return c.request(http.MethodPost, endpoint, data, authFn)
+func (c *Client) post(ctx context.Context, endpoint endpoint, data, out interface{}, authFn authFunc) error {
+	return c.request(ctx, http.MethodPost, endpoint, data, out, authFn)
 }
 
-func (c *Client) put(endpoint endpoint, data interface{}, authFn authFunc) ([]byte, error) {
-	return c.request(http.MethodPut, endpoint, data, authFn)
+func (c *Client) put(ctx context.Context, endpoint endpoint, data, out interface{}, authFn authFunc) error {
+	return c.request(ctx, http.MethodPut, endpoint, data, out, authFn)
 }
 
-func (c *Client) delete(endpoint endpoint, data interface{}, authFn authFunc) ([]byte, error) {
-	return c.request(http.MethodDelete, endpoint, data, authFn)
+func (c *Client) delete(ctx context.Context, endpoint endpoint, data, out interface{}, authFn authFunc) error {
+	return c.request(ctx, http.MethodDelete, endpoint, data, out, authFn)
 }
 
 func (c *Client) setBaseHeaders(r *http.Request) {
@@ -366,13 +587,185 @@ func (c *Client) setBaseHeaders(r *http.Request) {
 	r.Header.Set("X-Stream-Client", fmt.Sprintf("stream-go2-client-%s", Version))
 }
 
-func (c *Client) request(method string, endpoint endpoint, data interface{}, authFn authFunc) ([]byte, error) {
-	var reader io.Reader
-	if data != nil {
-		payload, err := json.Marshal(data)
+// request performs an API call through the middleware chain, decoding the
+// response body into out unless it's nil.
+func (c *Client) request(ctx context.Context, method string, endpoint endpoint, data, out interface{}, authFn authFunc) error {
+	ctx, span := c.startSpan(ctx, endpoint.spanName())
+	span.SetAttribute(AttributeOperation, endpoint.operation)
+	if endpoint.feedID != "" {
+		span.SetAttribute(AttributeFeedID, endpoint.feedID)
+	}
+	span.SetAttribute(AttributeResource, string(endpoint.resource))
+	span.SetAttribute(AttributeMethod, method)
+
+	call := &Call{
+		Operation: endpoint.operation,
+		FeedID:    endpoint.feedID,
+		Method:    method,
+		URL:       endpoint.String(),
+		Resource:  endpoint.resource,
+		Body:      data,
+		Header:    make(http.Header),
+		Response:  out,
+	}
+	send := func(ctx context.Context, call *Call) error {
+		return c.send(ctx, call, endpoint, authFn)
+	}
+	start := time.Now()
+	err := c.chain(send)(ctx, call)
+	c.observe(ctx, call, start, err)
+
+	span.SetAttribute(AttributeStatusCode, call.StatusCode)
+	if call.Attempts > 1 {
+		span.SetAttribute(AttributeRetryCount, call.Attempts-1)
+	}
+	span.SetAttribute(AttributeRequestSize, call.RequestSize)
+	span.SetAttribute(AttributeResponseSize, call.ResponseSize)
+	span.End(err)
+	return err
+}
+
+// send performs the HTTP requests for an API call, retrying them according to
+// the retry policy.
+func (c *Client) send(ctx context.Context, call *Call, endpoint endpoint, authFn authFunc) error {
+	var payload []byte
+	if call.Body != nil {
+		var err error
+		payload, err = json.Marshal(call.Body)
 		if err != nil {
-			return nil, fmt.Errorf("cannot marshal request: %s", err)
+			return fmt.Errorf("cannot marshal request: %s", err)
 		}
+	}
+	call.RequestSize = len(payload)
+	payload, err := c.compression.compressRequest(payload, call.Header)
+	if err != nil {
+		return err
+	}
+	call.RequestWireSize = len(payload)
+
+	method := call.Method
+	retryable := endpoint.idempotent || idempotentMethods[method]
+	circuit := circuitName(endpoint)
+	for attempt := 1; ; attempt++ {
+		r, err := c.limiter.wait(ctx, method, endpoint.resource)
+		if err != nil {
+			return err
+		}
+		if err := c.breakers.allow(circuit); err != nil {
+			// the request isn't sent, so it mustn't count against the limits
+			c.limiter.cancel(r)
+			return err
+		}
+		if attempt > 1 {
+			// a previous attempt may have partially decoded its response
+			resetResponse(call.Response)
+		}
+		call.Attempts = attempt
+		resp, size, wireSize, err := c.attempt(ctx, call, endpoint, payload, authFn)
+		if err != nil && ctx.Err() != nil {
+			c.breakers.release(circuit)
+		} else {
+			c.breakers.record(circuit, err != nil && isCircuitFailure(resp))
+		}
+		call.StatusCode = 0
+		if resp != nil {
+			call.StatusCode = resp.StatusCode
+			if rl := parseRateLimit(resp.Header); rl != nil {
+				key := rateLimitKey(method, endpoint.resource)
+				c.rateLimits.set(key, *rl)
+				c.limiter.update(key, *rl)
+			}
+		}
+		if err == nil {
+			call.ResponseSize = size
+			call.ResponseWireSize = wireSize
+			return nil
+		}
+		if _, invalid := err.(*decodeError); invalid {
+			return err
+		}
+		if !retryable || !c.retryPolicy.canRetry(attempt) || !isTemporary(resp) || ctx.Err() != nil {
+			return err
+		}
+		if err := sleepContext(ctx, c.retryPolicy.backoff(attempt, resp)); err != nil {
+			return err
+		}
+	}
+}
+
+// attempt performs a single HTTP request for an API call, tracing it in its own
+// span.
+func (c *Client) attempt(ctx context.Context, call *Call, endpoint endpoint, payload []byte, authFn authFunc) (*http.Response, int, int, error) {
+	ctx, span := c.startSpan(ctx, fmt.Sprintf("HTTP %s", call.Method))
+	span.SetAttribute(AttributeAttempt, call.Attempts)
+	span.SetAttribute(AttributeMethod, call.Method)
+	span.SetAttribute(AttributeRequestSize, len(payload))
+
+	req, err := c.newRequest(ctx, call.Method, endpoint, payload, call.Header, authFn)
+	if err != nil {
+		span.End(err)
+		return nil, 0, 0, err
+	}
+	resp, size, wireSize, err := c.do(req, call.Response)
+	if resp != nil {
+		span.SetAttribute(AttributeStatusCode, resp.StatusCode)
+	}
+	span.SetAttribute(AttributeResponseSize, size)
+	span.End(err)
+	return resp, size, wireSize, err
+}
+
+// decodeError is returned when a successful response body can't be decoded.
+// Unlike read failures, it's not worth retrying.
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("cannot unmarshal response: %s", e.err)
+}
+
+// decode unmarshals a successful response body into out, reading it as a
+// stream unless numbers are decoded losslessly, attaching the rate limit
+// status to it when out is an API response.
+func (c *Client) decode(resp *http.Response, body io.Reader, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	var err error
+	if c.numbers == NumbersAsFloat64 {
+		err = json.NewDecoder(body).Decode(out)
+	} else {
+		var data []byte
+		if data, err = ioutil.ReadAll(body); err != nil {
+			return err
+		}
+		err = decodeNumbers(data, out, c.numbers)
+	}
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return &decodeError{err: err}
+	}
+	if r, ok := out.(interface{ setRateLimit(*RateLimit) }); ok {
+		r.setRateLimit(parseRateLimit(resp.Header))
+	}
+	return nil
+}
+
+// resetResponse sets the value out points to, if any, to its zero value.
+func resetResponse(out interface{}) {
+	if v := reflect.ValueOf(out); v.Kind() == reflect.Ptr && !v.IsNil() {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	}
+}
+
+// newRequest builds a new signed HTTP request. It's called for every attempt
+// so that each one gets a fresh body reader and signature.
+func (c *Client) newRequest(ctx context.Context, method string, endpoint endpoint, payload []byte, header http.Header, authFn authFunc) (*http.Request, error) {
+	var reader io.Reader
+	if payload != nil {
 		reader = bytes.NewReader(payload)
 	}
 
@@ -380,6 +773,10 @@ func (c *Client) request(method string, endpoint endpoint, data interface{}, aut
 	if err != nil {
 		return nil, fmt.Errorf("cannot create request: %s", err)
 	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = append([]string(nil), v...)
+	}
 	c.setBaseHeaders(req)
 
 	if authFn != nil {
@@ -387,32 +784,61 @@ func (c *Client) request(method string, endpoint endpoint, data interface{}, aut
 			return nil, err
 		}
 	}
+	return req, nil
+}
 
+// do performs the given request, decoding the body of successful responses
+// into out as it's read, without buffering it. It returns the response (if one
+// was received) with its body closed, along with the size of the body once
+// decompressed and as received.
+func (c *Client) do(req *http.Request, out interface{}) (*http.Response, int, int, error) {
 	resp, err := c.requester.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("cannot perform request: %s", err)
+		return nil, 0, 0, &TransportError{Op: "perform request", URL: redactURL(req.URL), Err: err}
+	}
+	var (
+		raw  = &countingReader{r: bytes.NewReader(nil)}
+		body io.Reader
+	)
+	if resp.Body != nil {
+		defer resp.Body.Close()
+		raw.r = resp.Body
+		if body, err = decodedBody(resp, raw); err != nil {
+			return resp, 0, raw.n, &TransportError{Op: "decompress response", URL: redactURL(req.URL), Err: err}
+		}
 	}
 	if resp.StatusCode/100 != 2 {
-		return nil, c.makeStreamError(resp.StatusCode, resp.Body)
+		return resp, 0, 0, c.makeStreamError(resp.StatusCode, resp.Header, body, redactURL(req.URL))
+	}
+	if body == nil {
+		body = raw
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	decoded := &countingReader{r: body}
+	err = c.decode(resp, decoded, out)
+	// the rest of the body is read so that the connection can be reused
+	if _, drainErr := io.Copy(ioutil.Discard, decoded); drainErr != nil && decoded.err == nil {
+		decoded.err = drainErr
+	}
+	if decoded.err != nil {
+		return resp, 0, raw.n, &TransportError{Op: "read response", URL: redactURL(req.URL), Err: decoded.err}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("cannot read response: %s", err)
+		return resp, 0, raw.n, err
 	}
-
-	return body, nil
+	return resp, decoded.n, raw.n, nil
 }
 
-func (c *Client) addActivity(feed Feed, activity Activity) (*AddActivityResponse, error) {
-	endpoint := c.makeEndpoint("feed/%s/%s/", feed.Slug(), feed.UserID())
-	resp, err := c.post(endpoint, activity, c.authenticator.feedAuth(resFeed, feed))
-	if err != nil {
+func (c *Client) addActivity(ctx context.Context, feed Feed, activity Activity) (*AddActivityResponse, error) {
+	if err := c.validateActivities(false, activity); err != nil {
 		return nil, err
 	}
+	endpoint := c.makeEndpoint(ResourceFeed, "feed/%s/%s/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.add_activity"
+	endpoint.feedID = feed.ID()
+	endpoint.idempotent = activity.isIdempotent()
 	var out AddActivityResponse
-	if err := json.Unmarshal(resp, &out); err != nil {
+	if err := c.post(ctx, endpoint, activity, &out, c.authenticator.feedAuth(ResourceFeed, feed)); err != nil {
 		return nil, err
 	}
 	_, ok := out.Extra["duration"].(string)
@@ -422,106 +848,183 @@ func (c *Client) addActivity(feed Feed, activity Activity) (*AddActivityResponse
 	return &out, nil
 }
 
-func (c *Client) addActivities(feed Feed, activities ...Activity) (*AddActivitiesResponse, error) {
+func (c *Client) addActivities(ctx context.Context, feed Feed, activities ...Activity) (*AddActivitiesResponse, error) {
+	if err := c.validateActivities(false, activities...); err != nil {
+		return nil, err
+	}
 	reqBody := struct {
 		Activities []Activity `json:"activities,omitempty"`
 	}{
 		Activities: activities,
 	}
-	endpoint := c.makeEndpoint("feed/%s/%s/", feed.Slug(), feed.UserID())
-	resp, err := c.post(endpoint, reqBody, c.authenticator.feedAuth(resFeed, feed))
-	if err != nil {
-		return nil, err
+	endpoint := c.makeEndpoint(ResourceFeed, "feed/%s/%s/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.add_activities"
+	endpoint.feedID = feed.ID()
+	endpoint.idempotent = true
+	for _, activity := range activities {
+		endpoint.idempotent = endpoint.idempotent && activity.isIdempotent()
 	}
 	var out AddActivitiesResponse
-	if err := json.Unmarshal(resp, &out); err != nil {
-		return nil, fmt.Errorf("cannot unmarshal response: %s", err)
+	if err := c.post(ctx, endpoint, reqBody, &out, c.authenticator.feedAuth(ResourceFeed, feed)); err != nil {
+		return nil, err
 	}
 	return &out, nil
 }
 
-func (c *Client) removeActivityByID(feed Feed, activityID string) error {
-	endpoint := c.makeEndpoint("feed/%s/%s/%s/", feed.Slug(), feed.UserID(), activityID)
-	_, err := c.delete(endpoint, nil, c.authenticator.feedAuth(resFeed, feed))
-	return err
+func (c *Client) removeActivityByID(ctx context.Context, feed Feed, activityID string) error {
+	endpoint := c.makeEndpoint(ResourceFeed, "feed/%s/%s/%s/", feed.Slug(), feed.UserID(), activityID)
+	endpoint.operation = "feed.remove_activity"
+	endpoint.feedID = feed.ID()
+	return c.delete(ctx, endpoint, nil, nil, c.authenticator.feedAuth(ResourceFeed, feed))
 }
 
-func (c *Client) removeActivityByForeignID(feed Feed, foreignID string) error {
-	endpoint := c.makeEndpoint("feed/%s/%s/%s/", feed.Slug(), feed.UserID(), foreignID)
+func (c *Client) removeActivityByForeignID(ctx context.Context, feed Feed, foreignID string) error {
+	endpoint := c.makeEndpoint(ResourceFeed, "feed/%s/%s/%s/", feed.Slug(), feed.UserID(), foreignID)
+	endpoint.operation = "feed.remove_activity"
+	endpoint.feedID = feed.ID()
 	endpoint.addQueryParam(makeRequestOption("foreign_id", 1))
-	_, err := c.delete(endpoint, nil, c.authenticator.feedAuth(resFeed, feed))
-	return err
+	return c.delete(ctx, endpoint, nil, nil, c.authenticator.feedAuth(ResourceFeed, feed))
 }
 
-func (c *Client) getActivities(feed Feed, opts ...GetActivitiesOption) ([]byte, error) {
-	endpoint := c.makeEndpoint("feed/%s/%s/", feed.Slug(), feed.UserID())
-	return c.getActivitiesInternal(endpoint, feed, opts...)
+func (c *Client) getActivities(ctx context.Context, feed Feed, out interface{}, opts ...GetActivitiesOption) error {
+	return c.getActivitiesInternal(ctx, c.activitiesEndpoint(feed), feed, out, opts...)
 }
 
-func (c *Client) getEnrichedActivities(feed Feed, opts ...GetActivitiesOption) ([]byte, error) {
-	endpoint := c.makeEndpoint("enrich/feed/%s/%s/", feed.Slug(), feed.UserID())
-	return c.getActivitiesInternal(endpoint, feed, opts...)
+func (c *Client) getEnrichedActivities(ctx context.Context, feed Feed, out interface{}, opts ...GetActivitiesOption) error {
+	return c.getActivitiesInternal(ctx, c.enrichedActivitiesEndpoint(feed), feed, out, opts...)
 }
 
-func (c *Client) getActivitiesInternal(endpoint endpoint, feed Feed, opts ...GetActivitiesOption) ([]byte, error) {
+func (c *Client) getNextActivities(ctx context.Context, feed Feed, next string, out interface{}) error {
+	return c.getNextPage(ctx, c.activitiesEndpoint(feed), next, out, c.authenticator.feedAuth(ResourceFeed, feed))
+}
+
+func (c *Client) getNextEnrichedActivities(ctx context.Context, feed Feed, next string, out interface{}) error {
+	return c.getNextPage(ctx, c.enrichedActivitiesEndpoint(feed), next, out, c.authenticator.feedAuth(ResourceFeed, feed))
+}
+
+func (c *Client) activitiesEndpoint(feed Feed) endpoint {
+	endpoint := c.makeEndpoint(ResourceFeed, "feed/%s/%s/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.get_activities"
+	endpoint.feedID = feed.ID()
+	return endpoint
+}
+
+func (c *Client) enrichedActivitiesEndpoint(feed Feed) endpoint {
+	endpoint := c.makeEndpoint(ResourceFeed, "enrich/feed/%s/%s/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.get_enriched_activities"
+	endpoint.feedID = feed.ID()
+	return endpoint
+}
+
+func (c *Client) getActivitiesInternal(ctx context.Context, endpoint endpoint, feed Feed, out interface{}, opts ...GetActivitiesOption) error {
 	for _, opt := range opts {
 		endpoint.addQueryParam(opt)
 	}
-	return c.get(endpoint, nil, c.authenticator.feedAuth(resFeed, feed))
+	return c.get(ctx, endpoint, nil, out, c.authenticator.feedAuth(ResourceFeed, feed))
 }
 
-func (c *Client) follow(feed Feed, opts *followFeedOptions) error {
-	endpoint := c.makeEndpoint("feed/%s/%s/follows/", feed.Slug(), feed.UserID())
-	_, err := c.post(endpoint, opts, c.authenticator.feedAuth(resFollower, feed))
-	return err
+// getNextPage reads into out the page referenced by the given "next" link,
+// returned by a previous call to the given endpoint.
+func (c *Client) getNextPage(ctx context.Context, endpoint endpoint, next string, out interface{}, authFn authFunc) error {
+	endpoint, err := c.nextPageEndpoint(endpoint, next)
+	if err != nil {
+		return err
+	}
+	return c.get(ctx, endpoint, nil, out, authFn)
+}
+
+// nextPageEndpoint returns the given endpoint with the query parameters of the
+// given "next" link, which must point to the same host and path. The
+// parameters are kept as they are, except for the API key which is always the
+// one of the client.
+func (c *Client) nextPageEndpoint(endpoint endpoint, next string) (endpoint, error) {
+	if next == "" {
+		return endpoint, ErrMissingNextPage
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return endpoint, ErrInvalidNextPage
+	}
+	u := endpoint.url.ResolveReference(ref)
+	if u.Host != endpoint.url.Host || !sameAPIPath(u.Path, endpoint.url.Path) {
+		return endpoint, ErrInvalidNextPage
+	}
+	query, err := url.ParseQuery(ref.RawQuery)
+	if err != nil {
+		return endpoint, ErrInvalidNextPage
+	}
+	query.Set("api_key", c.key)
+	endpoint.query = query
+	return endpoint, nil
 }
 
-func (c *Client) getFollowers(feed Feed, opts ...FollowersOption) (*FollowersResponse, error) {
-	endpoint := c.makeEndpoint("feed/%s/%s/followers/", feed.Slug(), feed.UserID())
+// sameAPIPath tells whether the given URL paths point to the same API
+// endpoint. Paths are compared relative to the API version, since the next
+// links returned by the API don't include the path prefix of a base URL set
+// with WithBaseURL.
+func sameAPIPath(a, b string) bool {
+	relA, okA := apiRelativePath(a)
+	relB, okB := apiRelativePath(b)
+	if okA && okB {
+		return relA == relB
+	}
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+func (c *Client) follow(ctx context.Context, feed Feed, opts *followFeedOptions) error {
+	endpoint := c.makeEndpoint(ResourceFollower, "feed/%s/%s/follows/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.follow"
+	endpoint.feedID = feed.ID()
+	endpoint.idempotent = true
+	return c.post(ctx, endpoint, opts, nil, c.authenticator.feedAuth(ResourceFollower, feed))
+}
+
+func (c *Client) getFollowers(ctx context.Context, feed Feed, opts ...FollowersOption) (*FollowersResponse, error) {
+	endpoint := c.makeEndpoint(ResourceFollower, "feed/%s/%s/followers/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.get_followers"
+	endpoint.feedID = feed.ID()
 	for _, opt := range opts {
 		endpoint.addQueryParam(opt)
 	}
 
-	resp, err := c.get(endpoint, nil, c.authenticator.feedAuth(resFollower, feed))
-	if err != nil {
-		return nil, err
-	}
 	var out FollowersResponse
-	if err := json.Unmarshal(resp, &out); err != nil {
+	if err := c.get(ctx, endpoint, nil, &out, c.authenticator.feedAuth(ResourceFollower, feed)); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-func (c *Client) getFollowing(feed Feed, opts ...FollowingOption) (*FollowingResponse, error) {
-	endpoint := c.makeEndpoint("feed/%s/%s/follows/", feed.Slug(), feed.UserID())
+func (c *Client) getFollowing(ctx context.Context, feed Feed, opts ...FollowingOption) (*FollowingResponse, error) {
+	endpoint := c.makeEndpoint(ResourceFollower, "feed/%s/%s/follows/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.get_following"
+	endpoint.feedID = feed.ID()
 	for _, opt := range opts {
 		endpoint.addQueryParam(opt)
 	}
 
-	resp, err := c.get(endpoint, nil, c.authenticator.feedAuth(resFollower, feed))
-	if err != nil {
-		return nil, err
-	}
 	var out FollowingResponse
-	if err := json.Unmarshal(resp, &out); err != nil {
+	if err := c.get(ctx, endpoint, nil, &out, c.authenticator.feedAuth(ResourceFollower, feed)); err != nil {
 		return nil, err
 	}
 	return &out, nil
 }
 
-func (c *Client) unfollow(feed Feed, target string, opts ...UnfollowOption) error {
-	endpoint := c.makeEndpoint("feed/%s/%s/follows/%s/", feed.Slug(), feed.UserID(), target)
+func (c *Client) unfollow(ctx context.Context, feed Feed, target string, opts ...UnfollowOption) error {
+	endpoint := c.makeEndpoint(ResourceFollower, "feed/%s/%s/follows/%s/", feed.Slug(), feed.UserID(), target)
+	endpoint.operation = "feed.unfollow"
+	endpoint.feedID = feed.ID()
 	for _, opt := range opts {
 		endpoint.addQueryParam(opt)
 	}
 
-	_, err := c.delete(endpoint, nil, c.authenticator.feedAuth(resFollower, feed))
-	return err
+	return c.delete(ctx, endpoint, nil, nil, c.authenticator.feedAuth(ResourceFollower, feed))
 }
 
-func (c *Client) updateToTargets(feed Feed, activity Activity, opts ...UpdateToTargetsOption) error {
-	endpoint := c.makeEndpoint("feed_targets/%s/%s/activity_to_targets/", feed.Slug(), feed.UserID())
+func (c *Client) updateToTargets(ctx context.Context, feed Feed, activity Activity, opts ...UpdateToTargetsOption) error {
+	endpoint := c.makeEndpoint(ResourceFeedTargets, "feed_targets/%s/%s/activity_to_targets/", feed.Slug(), feed.UserID())
+	endpoint.operation = "feed.update_to_targets"
+	endpoint.feedID = feed.ID()
+	endpoint.idempotent = true
 
 	req := &updateToTargetsRequest{
 		ForeignID: activity.ForeignID,
@@ -531,8 +1034,7 @@ func (c *Client) updateToTargets(feed Feed, activity Activity, opts ...UpdateToT
 		opt(req)
 	}
 
-	_, err := c.post(endpoint, req, c.authenticator.feedAuth(resFeedTargets, feed))
-	return err
+	return c.post(ctx, endpoint, req, nil, c.authenticator.feedAuth(ResourceFeedTargets, feed))
 }
 
 func (c *Client) GetUserSessionToken(userID string) (string, error) {
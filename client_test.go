@@ -1,8 +1,10 @@
 package stream_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strconv"
 	"testing"
 	"time"
@@ -213,3 +215,99 @@ func TestUserSessionTokenWithClaims(t *testing.T) {
 	assert.Equal(t, true, token.Valid)
 	assert.Equal(t, token.Claims, jwt.MapClaims{"user_id": "user", "client": "go"})
 }
+
+type ctxKey struct{}
+
+func TestContextPropagation(t *testing.T) {
+	client, requester := newClient(t)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	_, err := flat.GetActivitiesContext(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "value", requester.req.Context().Value(ctxKey{}))
+
+	_, err = flat.AddActivityContext(ctx, stream.Activity{Actor: "bob", Verb: "like", Object: "cake"})
+	require.NoError(t, err)
+	assert.Equal(t, "value", requester.req.Context().Value(ctxKey{}))
+
+	_, err = client.Reactions().GetContext(ctx, "id1")
+	require.NoError(t, err)
+	assert.Equal(t, "value", requester.req.Context().Value(ctxKey{}))
+
+	_, err = client.Collections().SelectContext(ctx, "col", "a")
+	require.NoError(t, err)
+	assert.Equal(t, "value", requester.req.Context().Value(ctxKey{}))
+
+	_, err = flat.GetActivities()
+	require.NoError(t, err)
+	assert.Nil(t, requester.req.Context().Value(ctxKey{}))
+}
+
+func TestContextCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	client, err := stream.NewClient("key", "secret", stream.WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = flat.GetActivitiesContext(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+}
+
+func TestGetActivitiesChunked(t *testing.T) {
+	client, recorder := newBulkClient(t)
+	recorder.callLatency = 0
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	now := stream.Time{Time: time.Now().UTC().Truncate(time.Second)}
+	activities := make([]stream.Activity, 250)
+	for i := range activities {
+		activities[i] = stream.Activity{Actor: "bob", Verb: "post", Object: "post", ForeignID: fmt.Sprintf("post:%d", i), Time: now}
+	}
+	added, err := client.BulkAddActivities(feed, activities, stream.BulkConfig{})
+	require.NoError(t, err)
+
+	// request in reverse order, with unknown and duplicate IDs
+	var ids, expected []string
+	pairs := []stream.ForeignIDTimePair{stream.NewForeignIDTimePair("post:unknown", now)}
+	for i := len(added.Activities) - 1; i >= 0; i-- {
+		ids = append(ids, added.Activities[i].ID)
+		pairs = append(pairs, stream.NewForeignIDTimePair(added.Activities[i].ForeignID, now))
+		expected = append(expected, added.Activities[i].ID)
+	}
+	ids = append(ids, "unknown", added.Activities[0].ID)
+	pairs = append(pairs, stream.NewForeignIDTimePair("post:0", now))
+
+	calls := len(recorder.calls)
+	resp, err := client.GetActivitiesByID(ids...)
+	require.NoError(t, err)
+	assert.Len(t, recorder.calls, calls+3)
+	got := make([]string, len(resp.Results))
+	for i := range resp.Results {
+		got[i] = resp.Results[i].ID
+	}
+	assert.Equal(t, expected, got)
+	assert.Equal(t, []string{"unknown"}, resp.Missing)
+
+	resp, err = client.GetActivitiesByForeignID(pairs...)
+	require.NoError(t, err)
+	assert.Len(t, recorder.calls, calls+6)
+	got = make([]string, len(resp.Results))
+	for i := range resp.Results {
+		got[i] = resp.Results[i].ID
+	}
+	assert.Equal(t, expected, got)
+	assert.Equal(t, []string{"post:unknown"}, resp.Missing)
+
+	resp, err = client.GetActivitiesByID()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	assert.Empty(t, resp.Missing)
+}
@@ -0,0 +1,297 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// The helpers below implement the JSON decoding and encoding of activities and
+// of the enriched types, field by field, avoiding the reflection-heavy
+// round-trips through generic maps.
+
+var jsonNull = []byte("null")
+
+// decodeObject splits the given JSON object into its raw fields. A nil map is
+// returned for null.
+func decodeObject(b []byte) (map[string]json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// fieldError wraps the error decoding the given field of an object.
+func fieldError(field string, err error) error {
+	return fmt.Errorf("invalid %q field: %s", field, err)
+}
+
+// jsonKind describes the kind of the given raw JSON value, for errors.
+func jsonKind(raw []byte) string {
+	raw = bytes.TrimLeft(raw, " \t\r\n")
+	if len(raw) == 0 {
+		return "nothing"
+	}
+	switch raw[0] {
+	case '"':
+		return "a string"
+	case '{':
+		return "an object"
+	case '[':
+		return "a list"
+	case 't', 'f':
+		return "a boolean"
+	case 'n':
+		return "null"
+	}
+	return "a number"
+}
+
+// decodeString decodes a raw JSON string, null being decoded as an empty
+// string. Strings without escape sequences are decoded without going through
+// encoding/json.
+func decodeString(raw json.RawMessage) (string, error) {
+	if n := len(raw); n >= 2 && raw[0] == '"' && raw[n-1] == '"' {
+		s := raw[1 : n-1]
+		if bytes.IndexByte(s, '\\') < 0 && utf8.Valid(s) {
+			return string(s), nil
+		}
+	}
+	if bytes.Equal(raw, jsonNull) {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// decodeTime decodes a raw JSON time, in any of the formats used by the API,
+// null being decoded as the zero time.
+func decodeTime(raw json.RawMessage) (Time, error) {
+	if bytes.Equal(raw, jsonNull) {
+		return Time{}, nil
+	}
+	if len(raw) == 0 || raw[0] != '"' {
+		return Time{}, fmt.Errorf("expected a string, got %s", jsonKind(raw))
+	}
+	s, err := decodeString(raw)
+	if err != nil {
+		return Time{}, err
+	}
+	return timeFromString(s)
+}
+
+// decodeValue decodes a raw JSON value of any type, as json.Unmarshal does
+// into an empty interface.
+func decodeValue(raw json.RawMessage) (interface{}, error) {
+	switch {
+	case len(raw) > 0 && raw[0] == '"':
+		return decodeString(raw)
+	case bytes.Equal(raw, jsonNull):
+		return nil, nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// setExtra adds the given field to extra, allocating it if needed.
+func setExtra(extra map[string]interface{}, key string, raw json.RawMessage) (map[string]interface{}, error) {
+	v, err := decodeValue(raw)
+	if err != nil {
+		return nil, fieldError(key, err)
+	}
+	if extra == nil {
+		extra = make(map[string]interface{})
+	}
+	extra[key] = v
+	return extra, nil
+}
+
+// objectEncoder encodes JSON objects with their fields sorted by name, as
+// encoding/json does with maps. Fields set more than once keep the last value.
+type objectEncoder struct {
+	buf    []byte
+	fields []encodedField
+}
+
+type encodedField struct {
+	key        string
+	start, end int
+}
+
+// end records the value appended to the buffer since start as the one of the
+// given field. The existing fields are only looked up when replace is set.
+func (e *objectEncoder) end(key string, start int, replace bool) {
+	if replace {
+		for i := range e.fields {
+			if e.fields[i].key == key {
+				e.fields[i].start, e.fields[i].end = start, len(e.buf)
+				return
+			}
+		}
+	}
+	e.fields = append(e.fields, encodedField{key: key, start: start, end: len(e.buf)})
+}
+
+func (e *objectEncoder) has(key string) bool {
+	for i := range e.fields {
+		if e.fields[i].key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *objectEncoder) setString(key, value string) {
+	start := len(e.buf)
+	e.buf = appendString(e.buf, value)
+	e.end(key, start, false)
+}
+
+func (e *objectEncoder) setStrings(key string, values []string) {
+	start := len(e.buf)
+	e.buf = appendStrings(e.buf, values)
+	e.end(key, start, false)
+}
+
+func (e *objectEncoder) setTime(key string, t Time) {
+	start := len(e.buf)
+	e.buf = appendString(e.buf, t.Format(TimeLayout))
+	e.end(key, start, true)
+}
+
+func (e *objectEncoder) setData(key string, d Data) error {
+	start := len(e.buf)
+	var err error
+	if e.buf, err = d.appendJSON(e.buf); err != nil {
+		return err
+	}
+	e.end(key, start, false)
+	return nil
+}
+
+// setValue sets a field to the encoding/json encoding of the given value,
+// replacing the existing field with the same name if replace is set.
+func (e *objectEncoder) setValue(key string, value interface{}, replace bool) error {
+	var err error
+	start := len(e.buf)
+	switch v := value.(type) {
+	case string:
+		e.buf = appendString(e.buf, v)
+	case bool:
+		if v {
+			e.buf = append(e.buf, "true"...)
+		} else {
+			e.buf = append(e.buf, "false"...)
+		}
+	case nil:
+		e.buf = append(e.buf, jsonNull...)
+	default:
+		var data []byte
+		if data, err = json.Marshal(v); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, data...)
+	}
+	e.end(key, start, replace)
+	return nil
+}
+
+// setExtra sets the given extra fields, which replace the standard fields
+// having the same name.
+func (e *objectEncoder) setExtra(extra map[string]interface{}, standard map[string]bool) error {
+	for k, v := range extra {
+		if err := e.setValue(k, v, standard[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encode returns the JSON encoding of the object.
+func (e *objectEncoder) encode() []byte {
+	return e.appendTo(make([]byte, 0, len(e.buf)+len(e.fields)*16+2))
+}
+
+func (e *objectEncoder) appendTo(b []byte) []byte {
+	sort.Slice(e.fields, func(i, j int) bool { return e.fields[i].key < e.fields[j].key })
+	b = append(b, '{')
+	for i, f := range e.fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, f.key)
+		b = append(b, ':')
+		b = append(b, e.buf[f.start:f.end]...)
+	}
+	return append(b, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends the JSON encoding of s to b, escaping it as
+// encoding/json does, HTML characters included.
+func appendString(b []byte, s string) []byte {
+	n := len(b)
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// invalid UTF-8 is rare enough to leave it to encoding/json
+			data, _ := json.Marshal(s)
+			return append(b[:n], data...)
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+func appendStrings(b []byte, values []string) []byte {
+	b = append(b, '[')
+	for i, v := range values {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, v)
+	}
+	return append(b, ']')
+}
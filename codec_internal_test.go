@@ -0,0 +1,351 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fatih/structs"
+	"github.com/mitchellh/mapstructure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The legacy* functions are the reflection-based implementations the
+// hand-written decoders and encoders replaced, kept as references.
+
+func legacyFixToTargets(data map[string]interface{}) error {
+	if _, ok := data["to"]; ok {
+		tos := data["to"].([]interface{})
+		simpleTos := make([]string, len(tos))
+		for i := range tos {
+			if sliceTos, isString := tos[i].(string); isString {
+				simpleTos[i] = sliceTos
+			} else if sliceTos, isSlice := tos[i].([]interface{}); isSlice {
+				tos, ok := sliceTos[0].(string)
+				if !ok {
+					return fmt.Errorf("invalid format for to targets")
+				}
+				simpleTos[i] = tos
+			}
+		}
+		data["to"] = simpleTos
+	}
+	return nil
+}
+
+func legacyDecode(b []byte, target interface{}) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	if err := legacyFixToTargets(data); err != nil {
+		return nil, err
+	}
+	meta, err := decodeData(data, target)
+	if err != nil {
+		return nil, err
+	}
+	var extra map[string]interface{}
+	if len(meta.Unused) > 0 {
+		extra = make(map[string]interface{})
+		for _, k := range meta.Unused {
+			extra[k] = data[k]
+		}
+	}
+	return extra, nil
+}
+
+func legacyUnmarshalActivity(b []byte, a *Activity) error {
+	extra, err := legacyDecode(b, a)
+	if extra != nil {
+		a.Extra = extra
+	}
+	return err
+}
+
+func legacyUnmarshalEnrichedActivity(b []byte, a *EnrichedActivity) error {
+	extra, err := legacyDecode(b, a)
+	if extra != nil {
+		a.Extra = extra
+	}
+	return err
+}
+
+func legacyUnmarshalEnrichedReaction(b []byte, r *EnrichedReaction) error {
+	_, err := legacyDecode(b, r)
+	return err
+}
+
+func legacyUnmarshalData(b []byte, d *Data) error {
+	var data map[string]interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	cfg := &mapstructure.DecoderConfig{
+		Result:   d,
+		Metadata: &mapstructure.Metadata{},
+		TagName:  "json",
+	}
+	dec, err := mapstructure.NewDecoder(cfg)
+	if err != nil {
+		return err
+	}
+	if err := dec.Decode(data); err != nil {
+		return err
+	}
+	if len(cfg.Metadata.Unused) > 0 {
+		d.Extra = make(map[string]interface{})
+		for _, k := range cfg.Metadata.Unused {
+			d.Extra[k] = data[k]
+		}
+	}
+	return nil
+}
+
+// plainEnrichedReaction is encoded with the default encoding/json encoding.
+type plainEnrichedReaction EnrichedReaction
+
+func plainReactions(reactions map[string][]*EnrichedReaction) map[string][]*plainEnrichedReaction {
+	if reactions == nil {
+		return nil
+	}
+	plain := make(map[string][]*plainEnrichedReaction, len(reactions))
+	for k, list := range reactions {
+		plain[k] = make([]*plainEnrichedReaction, len(list))
+		for i := range list {
+			plain[k][i] = (*plainEnrichedReaction)(list[i])
+		}
+	}
+	return plain
+}
+
+func legacyMarshal(v interface{}, extra map[string]interface{}, t Time) ([]byte, error) {
+	s := structs.New(v)
+	s.TagName = "json"
+	data := s.Map()
+	for k, v := range extra {
+		data[k] = v
+	}
+	if _, ok := data["time"]; ok {
+		data["time"] = t.Format(TimeLayout)
+	}
+	return json.Marshal(data)
+}
+
+func legacyMarshalActivity(a Activity) ([]byte, error) {
+	return legacyMarshal(a, a.Extra, a.Time)
+}
+
+func legacyMarshalEnrichedActivity(a EnrichedActivity) ([]byte, error) {
+	type plainActivity struct {
+		ID              string                              `json:"id,omitempty"`
+		Actor           Data                                `json:"actor,omitempty"`
+		Verb            string                              `json:"verb,omitempty"`
+		Object          Data                                `json:"object,omitempty"`
+		ForeignID       string                              `json:"foreign_id,omitempty"`
+		Target          Data                                `json:"target,omitempty"`
+		Time            Time                                `json:"time,omitempty"`
+		Origin          Data                                `json:"origin,omitempty"`
+		To              []string                            `json:"to,omitempty"`
+		Score           float64                             `json:"score,omitempty"`
+		ReactionCounts  map[string]int                      `json:"reaction_counts,omitempty"`
+		OwnReactions    map[string][]*plainEnrichedReaction `json:"own_reactions,omitempty"`
+		LatestReactions map[string][]*plainEnrichedReaction `json:"latest_reactions,omitempty"`
+	}
+	return legacyMarshal(plainActivity{
+		ID:              a.ID,
+		Actor:           a.Actor,
+		Verb:            a.Verb,
+		Object:          a.Object,
+		ForeignID:       a.ForeignID,
+		Target:          a.Target,
+		Time:            a.Time,
+		Origin:          a.Origin,
+		To:              a.To,
+		Score:           a.Score,
+		ReactionCounts:  a.ReactionCounts,
+		OwnReactions:    plainReactions(a.OwnReactions),
+		LatestReactions: plainReactions(a.LatestReactions),
+	}, a.Extra, a.Time)
+}
+
+var codecActivities = []string{
+	`{}`,
+	`{"actor":"bob","verb":"post","object":"post:1"}`,
+	`{"id":"ef696c12-69ab-11e4-8080-80003644b625","actor":"user:1","verb":"like","object":"picture:1","foreign_id":"like:1",` +
+		`"target":"album:1","time":"2020-01-02T03:04:05.123456","origin":"user:2","to":["timeline:1",["notification:1","token"]],` +
+		`"score":1.5,"popularity":42,"ratio":0.25,"published":true,"deleted":null,"tags":["a","b"],` +
+		`"size":{"width":800,"height":600,"meta":{"exif":[1,2.5,"x",null,false]}},` +
+		`"text":"<a href=\"https://example.com/?a=1&b=2\">caf\u00e9</a>\n\t\u2028 \ud83d\ude00 \\o/"}`,
+	`{"actor":"bob","verb":"post","object":"post:1","time":"2020-01-02T03:04:05.123456Z"}`,
+	`{"actor":"bob","verb":"post","object":"post:1","time":"2020-01-02 03:04:05.123456+02:00","to":[]}`,
+	`{"actor":"bob","verb":"post","object":"post:1","time":null,"to":[["timeline:1","token"],"timeline:2"]}`,
+	`{"actor":"caf\u00e9","verb":"post","object":"post:1","unicode":"\u65e5\u672c"}`,
+}
+
+var codecEnrichedActivities = []string{
+	`{}`,
+	`{"actor":"bob","verb":"post","object":"post:1","target":null}`,
+	`{"id":"1","actor":{"id":"alice","collection":"user","data":{"name":"Alice"}},"verb":"post",` +
+		`"object":{"id":"picture:1","collection":"pictures","data":{"url":"https://example.com/1.png","width":640}},` +
+		`"origin":"user:carol","foreign_id":"post:1","time":"2020-01-02T03:04:05.123456","to":["timeline:bob",["notification:carol","token"]],` +
+		`"reaction_counts":{"like":2,"comment":1},"score":3,` +
+		`"latest_reactions":{"like":[{"id":"r1","kind":"like","activity_id":"1","user_id":"bob","data":{"emoji":"<3"},` +
+		`"target_feeds":["notification:alice"],"parent":"r0","children_counts":{"like":1},` +
+		`"latest_children":{"like":[{"id":"r2","kind":"like","activity_id":"1","user_id":"carol","created_at":"2020-01-02T03:04:05.123456Z"}]},` +
+		`"user":{"id":"bob","data":{"name":"Bob"}},"created_at":"2020-01-02T03:04:05.123456","updated_at":"2020-01-02T03:04:05.123456"}]},` +
+		`"own_reactions":{},"text":"picture number 1","tags":["summer","beach"],"location":{"lat":45.5,"lng":9.2}}`,
+}
+
+func TestActivityCodecEquivalence(t *testing.T) {
+	for _, data := range codecActivities {
+		var legacy, direct Activity
+		require.NoError(t, legacyUnmarshalActivity([]byte(data), &legacy), data)
+		require.NoError(t, direct.UnmarshalJSON([]byte(data)), data)
+		assert.Equal(t, legacy, direct, data)
+
+		legacyData, err := legacyMarshalActivity(direct)
+		require.NoError(t, err)
+		directData, err := direct.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, string(legacyData), string(directData))
+	}
+
+	now := Time{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+	for _, activity := range []Activity{
+		{Actor: "bob", To: []string{}},
+		{Actor: "bob", Extra: map[string]interface{}{"time": "now", "id": "extra"}},
+		{ID: "1", Time: now, Extra: map[string]interface{}{"time": "now", "id": "extra", "nested": map[string]interface{}{"a": []interface{}{1, "b"}}}},
+		{Actor: "<bob>", Extra: map[string]interface{}{"invalid": "\xff", "when": now, "numbers": []int{1, 2}}},
+	} {
+		legacyData, err := legacyMarshalActivity(activity)
+		require.NoError(t, err)
+		directData, err := activity.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, string(legacyData), string(directData))
+	}
+}
+
+func TestEnrichedActivityCodecEquivalence(t *testing.T) {
+	for _, data := range codecEnrichedActivities {
+		var legacy, direct EnrichedActivity
+		require.NoError(t, legacyUnmarshalEnrichedActivity([]byte(data), &legacy), data)
+		require.NoError(t, direct.UnmarshalJSON([]byte(data)), data)
+		assert.Equal(t, legacy, direct, data)
+
+		// enriched objects used to be encoded without their extra fields
+		direct.Actor.Extra, direct.Object.Extra = nil, nil
+		for _, reactions := range direct.LatestReactions {
+			for _, r := range reactions {
+				r.User.Extra = nil
+			}
+		}
+		legacyData, err := legacyMarshalEnrichedActivity(direct)
+		require.NoError(t, err)
+		directData, err := direct.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, string(legacyData), string(directData))
+	}
+}
+
+func TestEnrichedReactionCodecEquivalence(t *testing.T) {
+	for _, data := range []string{
+		`{}`,
+		`{"kind":"like","activity_id":"1","user_id":"bob","user":"bob","created_at":"2020-01-02T03:04:05.123456Z","unknown":1}`,
+		`{"id":"r1","kind":"comment","activity_id":"1","user_id":"bob","data":{"text":"nice & <b>bold</b>"},"target_feeds":["user:1"],` +
+			`"parent":"r0","own_children":{"like":[{"id":"r2","kind":"like"}]},"children_counts":{"like":1},` +
+			`"user":{"id":"bob"},"created_at":"2020-01-02T03:04:05.123456","updated_at":null}`,
+	} {
+		var legacy, direct EnrichedReaction
+		require.NoError(t, legacyUnmarshalEnrichedReaction([]byte(data), &legacy), data)
+		require.NoError(t, direct.UnmarshalJSON([]byte(data)), data)
+		assert.Equal(t, legacy, direct, data)
+
+		legacyData, err := json.Marshal(plainEnrichedReaction(direct))
+		require.NoError(t, err)
+		directData, err := json.Marshal(direct)
+		require.NoError(t, err)
+		assert.Equal(t, string(legacyData), string(directData))
+	}
+}
+
+func TestDataCodec(t *testing.T) {
+	for _, data := range []string{
+		`{"id":"bob"}`,
+		`{"id":"bob","collection":"user","data":{"name":"Bob"},"followers":42}`,
+		`{"name":"anonymous"}`,
+	} {
+		var legacy, direct Data
+		require.NoError(t, legacyUnmarshalData([]byte(data), &legacy), data)
+		require.NoError(t, direct.UnmarshalJSON([]byte(data)), data)
+		assert.Equal(t, legacy, direct, data)
+
+		encoded, err := json.Marshal(direct)
+		require.NoError(t, err)
+		assert.JSONEq(t, mergeID(data, direct.ID), string(encoded))
+	}
+
+	var d Data
+	require.NoError(t, json.Unmarshal([]byte(`"bob"`), &d))
+	assert.Equal(t, Data{ID: "bob"}, d)
+	require.NoError(t, json.Unmarshal([]byte(`null`), &d))
+	assert.Equal(t, Data{ID: "bob"}, d)
+	assert.Error(t, json.Unmarshal([]byte(`42`), &d))
+	assert.Error(t, json.Unmarshal([]byte(`{"id":42}`), &d))
+}
+
+func mergeID(data, id string) string {
+	var fields map[string]interface{}
+	_ = json.Unmarshal([]byte(data), &fields)
+	fields["id"] = id
+	merged, _ := json.Marshal(fields)
+	return string(merged)
+}
+
+func BenchmarkActivityJSON(b *testing.B) {
+	data := []byte(codecActivities[2])
+	var activity Activity
+	require.NoError(b, activity.UnmarshalJSON(data))
+	benchmarkCodec(b, data,
+		func(data []byte) error { var a Activity; return legacyUnmarshalActivity(data, &a) },
+		func(data []byte) error { var a Activity; return a.UnmarshalJSON(data) },
+		func() ([]byte, error) { return legacyMarshalActivity(activity) },
+		activity.MarshalJSON,
+	)
+}
+
+func BenchmarkEnrichedActivityJSON(b *testing.B) {
+	data := []byte(codecEnrichedActivities[2])
+	var activity EnrichedActivity
+	require.NoError(b, activity.UnmarshalJSON(data))
+	benchmarkCodec(b, data,
+		func(data []byte) error { var a EnrichedActivity; return legacyUnmarshalEnrichedActivity(data, &a) },
+		func(data []byte) error { var a EnrichedActivity; return a.UnmarshalJSON(data) },
+		func() ([]byte, error) { return legacyMarshalEnrichedActivity(activity) },
+		activity.MarshalJSON,
+	)
+}
+
+func benchmarkCodec(b *testing.B, data []byte, legacyDecode, decode func([]byte) error, legacyEncode, encode func() ([]byte, error)) {
+	for _, bc := range []struct {
+		name string
+		run  func() error
+	}{
+		{"Unmarshal/legacy", func() error { return legacyDecode(data) }},
+		{"Unmarshal/direct", func() error { return decode(data) }},
+		{"Marshal/legacy", func() error { _, err := legacyEncode(); return err }},
+		{"Marshal/direct", func() error { _, err := encode(); return err }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := bc.run(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,10 @@
 package stream
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"strings"
+	"sync"
 )
 
 // CollectionsClient is a specialized client used to interact with the Collection endpoints.
@@ -13,61 +14,123 @@ type CollectionsClient struct {
 
 // Upsert creates new or updates existing objects for the given collection's name.
 func (c *CollectionsClient) Upsert(collection string, objects ...CollectionObject) error {
+	return c.UpsertContext(context.Background(), collection, objects...)
+}
+
+// UpsertContext is like Upsert, using the provided context for the request.
+func (c *CollectionsClient) UpsertContext(ctx context.Context, collection string, objects ...CollectionObject) error {
 	if collection == "" {
 		return fmt.Errorf("collection name required")
 	}
-	endpoint := c.client.makeEndpoint("collections/")
+	endpoint := c.client.makeEndpoint(ResourceCollections, "collections/")
+	endpoint.operation = "collections.upsert"
+	endpoint.idempotent = true
 	data := map[string]interface{}{
 		"data": map[string][]CollectionObject{
 			collection: objects,
 		},
 	}
-	_, err := c.client.post(endpoint, data, c.client.authenticator.collectionsAuth)
-	return err
+	return c.client.post(ctx, endpoint, data, nil, c.client.authenticator.collectionsAuth)
 }
 
 // Select returns a list of CollectionObjects for the given collection name
-// having the given IDs.
+// having the given IDs, in the order of the IDs. Large lookups are split in
+// chunks, requested concurrently.
 func (c *CollectionsClient) Select(collection string, ids ...string) ([]GetCollectionResponseObject, error) {
+	return c.SelectContext(context.Background(), collection, ids...)
+}
+
+// SelectContext is like Select, using the provided context for the request.
+func (c *CollectionsClient) SelectContext(ctx context.Context, collection string, ids ...string) ([]GetCollectionResponseObject, error) {
+	resp, err := c.SelectWithMissingContext(ctx, collection, ids...)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Results, nil
+}
+
+// SelectWithMissing is like Select, also reporting the IDs no object was found
+// for.
+func (c *CollectionsClient) SelectWithMissing(collection string, ids ...string) (*SelectCollectionResponse, error) {
+	return c.SelectWithMissingContext(context.Background(), collection, ids...)
+}
+
+// SelectWithMissingContext is like SelectWithMissing, using the provided context for the request.
+func (c *CollectionsClient) SelectWithMissingContext(ctx context.Context, collection string, ids ...string) (*SelectCollectionResponse, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("collection name required")
 	}
+	ids = uniqueStrings(ids)
 	foreignIDs := make([]string, len(ids))
 	for i := range ids {
 		foreignIDs[i] = fmt.Sprintf("%s:%s", collection, ids[i])
 	}
-	endpoint := c.client.makeEndpoint("collections/")
-	endpoint.addQueryParam(makeRequestOption("foreign_ids", strings.Join(foreignIDs, ",")))
-	resp, err := c.client.get(endpoint, nil, c.client.authenticator.collectionsAuth)
+	found := make(map[string]GetCollectionResponseObject, len(ids))
+	var mu sync.Mutex
+	err := runChunkedRead(ctx, len(ids), func(ctx context.Context, chunk []int) error {
+		batch := make([]string, len(chunk))
+		for j, i := range chunk {
+			batch[j] = foreignIDs[i]
+		}
+		endpoint := c.client.makeEndpoint(ResourceCollections, "collections/")
+		endpoint.operation = "collections.select"
+		endpoint.addQueryParam(makeRequestOption("foreign_ids", strings.Join(batch, ",")))
+		var selectResp getCollectionResponseWrap
+		if err := c.client.get(ctx, endpoint, nil, &selectResp, c.client.authenticator.collectionsAuth); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, object := range selectResp.Response.Data {
+			found[object.ForeignID] = object
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	var selectResp getCollectionResponseWrap
-	err = json.Unmarshal(resp, &selectResp)
-	if err != nil {
-		return nil, err
+	resp := &SelectCollectionResponse{Results: make([]GetCollectionResponseObject, 0, len(found))}
+	for i, id := range ids {
+		if object, ok := found[foreignIDs[i]]; ok {
+			resp.Results = append(resp.Results, object)
+		} else {
+			resp.Missing = append(resp.Missing, id)
+		}
 	}
-	return selectResp.Response.Data, nil
+	return resp, nil
 }
 
 // DeleteMany removes from a collection the objects having the given IDs.
 func (c *CollectionsClient) DeleteMany(collection string, ids ...string) error {
+	return c.DeleteManyContext(context.Background(), collection, ids...)
+}
+
+// DeleteManyContext is like DeleteMany, using the provided context for the request.
+func (c *CollectionsClient) DeleteManyContext(ctx context.Context, collection string, ids ...string) error {
 	if collection == "" {
 		return fmt.Errorf("collection name required")
 	}
-	endpoint := c.client.makeEndpoint("collections/")
+	endpoint := c.client.makeEndpoint(ResourceCollections, "collections/")
+	endpoint.operation = "collections.delete_many"
 	endpoint.addQueryParam(makeRequestOption("collection_name", collection))
 	endpoint.addQueryParam(makeRequestOption("ids", strings.Join(ids, ",")))
-	_, err := c.client.delete(endpoint, nil, c.client.authenticator.collectionsAuth)
-	return err
+	return c.client.delete(ctx, endpoint, nil, nil, c.client.authenticator.collectionsAuth)
 }
 
 //Add adds a single object to a collection.
 func (c *CollectionsClient) Add(collection string, object CollectionObject, opts ...AddObjectOption) (*CollectionObject, error) {
+	return c.AddContext(context.Background(), collection, object, opts...)
+}
+
+// AddContext is like Add, using the provided context for the request.
+func (c *CollectionsClient) AddContext(ctx context.Context, collection string, object CollectionObject, opts ...AddObjectOption) (*CollectionObject, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("collection name required")
 	}
-	endpoint := c.client.makeEndpoint("collections/%s/", collection)
+	endpoint := c.client.makeEndpoint(ResourceCollections, "collections/%s/", collection)
+	endpoint.operation = "collections.add"
+	// objects with a client-provided ID cannot be added twice
+	endpoint.idempotent = object.ID != ""
 
 	req := addCollectionRequest{}
 
@@ -78,12 +141,8 @@ func (c *CollectionsClient) Add(collection string, object CollectionObject, opts
 	req.ID = object.ID
 	req.Data = object.Data
 
-	resp, err := c.client.post(endpoint, req, c.client.authenticator.collectionsAuth)
-	if err != nil {
-		return nil, err
-	}
 	result := &CollectionObject{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.post(ctx, endpoint, req, result, c.client.authenticator.collectionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -92,17 +151,19 @@ func (c *CollectionsClient) Add(collection string, object CollectionObject, opts
 
 //Get retrives a collection object having the given ID.
 func (c *CollectionsClient) Get(collection string, id string) (*CollectionObject, error) {
+	return c.GetContext(context.Background(), collection, id)
+}
+
+// GetContext is like Get, using the provided context for the request.
+func (c *CollectionsClient) GetContext(ctx context.Context, collection string, id string) (*CollectionObject, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("collection name required")
 	}
-	endpoint := c.client.makeEndpoint("collections/%s/%s/", collection, id)
+	endpoint := c.client.makeEndpoint(ResourceCollections, "collections/%s/%s/", collection, id)
+	endpoint.operation = "collections.get"
 
-	resp, err := c.client.get(endpoint, nil, c.client.authenticator.collectionsAuth)
-	if err != nil {
-		return nil, err
-	}
 	result := &CollectionObject{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.get(ctx, endpoint, nil, result, c.client.authenticator.collectionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -111,20 +172,22 @@ func (c *CollectionsClient) Get(collection string, id string) (*CollectionObject
 
 //Update updates the given collection object's data.
 func (c *CollectionsClient) Update(collection string, id string, data map[string]interface{}) (*CollectionObject, error) {
+	return c.UpdateContext(context.Background(), collection, id, data)
+}
+
+// UpdateContext is like Update, using the provided context for the request.
+func (c *CollectionsClient) UpdateContext(ctx context.Context, collection string, id string, data map[string]interface{}) (*CollectionObject, error) {
 	if collection == "" {
 		return nil, fmt.Errorf("collection name required")
 	}
-	endpoint := c.client.makeEndpoint("collections/%s/%s/", collection, id)
+	endpoint := c.client.makeEndpoint(ResourceCollections, "collections/%s/%s/", collection, id)
+	endpoint.operation = "collections.update"
 	reqData := map[string]interface{}{
 		"data": data,
 	}
 
-	resp, err := c.client.put(endpoint, reqData, c.client.authenticator.collectionsAuth)
-	if err != nil {
-		return nil, err
-	}
 	result := &CollectionObject{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.put(ctx, endpoint, reqData, result, c.client.authenticator.collectionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -133,13 +196,18 @@ func (c *CollectionsClient) Update(collection string, id string, data map[string
 
 // Delete removes from a collection the object having the given ID.
 func (c *CollectionsClient) Delete(collection string, id string) error {
+	return c.DeleteContext(context.Background(), collection, id)
+}
+
+// DeleteContext is like Delete, using the provided context for the request.
+func (c *CollectionsClient) DeleteContext(ctx context.Context, collection string, id string) error {
 	if collection == "" {
 		return fmt.Errorf("collection name required")
 	}
-	endpoint := c.client.makeEndpoint("collections/%s/%s/", collection, id)
+	endpoint := c.client.makeEndpoint(ResourceCollections, "collections/%s/%s/", collection, id)
+	endpoint.operation = "collections.delete"
 
-	_, err := c.client.delete(endpoint, nil, c.client.authenticator.collectionsAuth)
-	return err
+	return c.client.delete(ctx, endpoint, nil, nil, c.client.authenticator.collectionsAuth)
 }
 
 // CreateReference returns a new reference string in the form SO:<collection>:<id>.
@@ -1,6 +1,9 @@
 package stream_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
@@ -187,3 +190,37 @@ func TestUpdateCollectionObject(t *testing.T) {
 	expectedBody := `{"data":{"name":"Jane"}}`
 	testRequest(t, requester.req, http.MethodPut, "https://api.stream-io-api.com/api/v1.0/collections/test-collection/123/?api_key=key", expectedBody)
 }
+
+func TestSelectCollectionObjectsChunked(t *testing.T) {
+	client, recorder := newBulkClient(t)
+	recorder.callLatency = 0
+	collections := client.Collections()
+	var ids []string
+	for i := 0; i < 150; i++ {
+		id := fmt.Sprintf("item%d", i)
+		_, err := collections.Add("things", stream.CollectionObject{ID: id, Data: map[string]interface{}{"n": i}})
+		require.NoError(t, err)
+		ids = append([]string{id}, ids...)
+	}
+
+	calls := len(recorder.calls)
+	resp, err := collections.SelectWithMissing("things", append(ids, "unknown", "item0")...)
+	require.NoError(t, err)
+	assert.Len(t, recorder.calls, calls+2)
+	require.Len(t, resp.Results, 150)
+	for i, object := range resp.Results {
+		assert.Equal(t, "things:"+ids[i], object.ForeignID)
+	}
+	assert.Equal(t, []string{"unknown"}, resp.Missing)
+
+	objects, err := collections.Select("things", "item3", "unknown", "item1")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "things:item3", objects[0].ForeignID)
+	assert.Equal(t, "things:item1", objects[1].ForeignID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = collections.SelectContext(ctx, "things", ids...)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
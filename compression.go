@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionThreshold is the default minimum size, in bytes, of the
+// request bodies compressed when compression is enabled.
+const DefaultCompressionThreshold = 1024
+
+// CompressionConfig configures the compression of the requests and responses
+// of a Client.
+type CompressionConfig struct {
+	// Threshold is the minimum size, in bytes, of the request bodies to
+	// compress. Defaults to DefaultCompressionThreshold, negative values
+	// disable request compression, only asking for compressed responses.
+	Threshold int
+	// Level is the gzip compression level, as defined by the compress/gzip
+	// package. Defaults to gzip.DefaultCompression.
+	Level int
+}
+
+// WithCompression enables gzip compression for a given Client: request bodies
+// larger than the threshold are compressed, and compressed responses are asked
+// for. Compressed responses are decoded regardless of this option. The sizes
+// of the bodies as sent and received are reported in the RequestWireSize and
+// ResponseWireSize fields of Call, and to the metrics collector.
+func WithCompression(config CompressionConfig) ClientOption {
+	return func(c *Client) {
+		if config.Threshold == 0 {
+			config.Threshold = DefaultCompressionThreshold
+		}
+		if config.Level == 0 {
+			config.Level = gzip.DefaultCompression
+		}
+		c.compression = &config
+	}
+}
+
+// compressRequest returns the payload to send for the given request body,
+// compressed if it's large enough, setting the related headers. The payload is
+// returned as it is when compression is disabled.
+func (cc *CompressionConfig) compressRequest(payload []byte, header http.Header) ([]byte, error) {
+	if cc == nil {
+		return payload, nil
+	}
+	header.Set("Accept-Encoding", "gzip")
+	if cc.Threshold < 0 || len(payload) < cc.Threshold {
+		return payload, nil
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, cc.Level)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compress request: %s", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return nil, fmt.Errorf("cannot compress request: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("cannot compress request: %s", err)
+	}
+	header.Set("Content-Encoding", "gzip")
+	return buf.Bytes(), nil
+}
+
+// countingReader counts the bytes read from the underlying reader, keeping
+// track of the first read failure.
+type countingReader struct {
+	r   io.Reader
+	n   int
+	err error
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	if err != nil && err != io.EOF && r.err == nil {
+		r.err = err
+	}
+	return n, err
+}
+
+// gzipMagic are the first bytes of gzip streams.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decodedBody returns a reader of the decoded body of the given response.
+// Bodies are decompressed when gzip-encoded: the content encoding is trusted
+// only if the body starts like a gzip stream, as some proxies keep the header
+// of responses they decompressed.
+func decodedBody(resp *http.Response, body io.Reader) (io.Reader, error) {
+	if !strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		return body, nil
+	}
+	br := bufio.NewReader(body)
+	magic, err := br.Peek(len(gzipMagic))
+	if err != nil || !bytes.Equal(magic, gzipMagic) {
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
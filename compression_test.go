@@ -0,0 +1,220 @@
+package stream_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// encodedRequester records the request bodies and replies with a fixed
+// response body and headers.
+type encodedRequester struct {
+	bodies  [][]byte
+	headers []http.Header
+	code    int
+	body    []byte
+	header  http.Header
+}
+
+func (r *encodedRequester) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+	}
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header)
+	code := r.code
+	if code == 0 {
+		code = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     r.header,
+		Body:       ioutil.NopCloser(bytes.NewReader(r.body)),
+	}, nil
+}
+
+func TestCompressRequests(t *testing.T) {
+	requester := &encodedRequester{body: []byte("{}"), header: http.Header{}}
+	recorder := &metricsRecorder{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithMetricsCollector(recorder),
+		stream.WithCompression(stream.CompressionConfig{Threshold: 100}),
+	)
+	require.NoError(t, err)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+
+	_, err = feed.AddActivity(stream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	require.NoError(t, err)
+	large := stream.Activity{Actor: "bob", Verb: "post", Object: "post:2", Extra: map[string]interface{}{"text": strings.Repeat("a", 1000)}}
+	_, err = feed.AddActivity(large)
+	require.NoError(t, err)
+
+	require.Len(t, requester.bodies, 2)
+	assert.Equal(t, "gzip", requester.headers[0].Get("Accept-Encoding"))
+	assert.Empty(t, requester.headers[0].Get("Content-Encoding"))
+	assert.Contains(t, string(requester.bodies[0]), "post:1")
+
+	assert.Equal(t, "gzip", requester.headers[1].Get("Content-Encoding"))
+	zr, err := gzip.NewReader(bytes.NewReader(requester.bodies[1]))
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+	var sent stream.Activity
+	require.NoError(t, sent.UnmarshalJSON(data))
+	assert.Equal(t, large.Extra, sent.Extra)
+
+	require.Len(t, recorder.metrics, 2)
+	m := recorder.metrics[1]
+	assert.Equal(t, len(data), m.BytesSent)
+	assert.Equal(t, len(requester.bodies[1]), m.WireBytesSent)
+	assert.True(t, m.WireBytesSent < m.BytesSent)
+	assert.Equal(t, recorder.metrics[0].BytesSent, recorder.metrics[0].WireBytesSent)
+}
+
+func TestCompressRequestsDisabled(t *testing.T) {
+	requester := &encodedRequester{body: []byte("{}"), header: http.Header{}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithCompression(stream.CompressionConfig{Threshold: -1}),
+	)
+	require.NoError(t, err)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	_, err = feed.AddActivity(stream.Activity{Actor: "bob", Verb: "post", Object: strings.Repeat("a", 10000)})
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", requester.headers[0].Get("Accept-Encoding"))
+	assert.Empty(t, requester.headers[0].Get("Content-Encoding"))
+}
+
+func TestDecompressResponses(t *testing.T) {
+	const body = `{"results":[{"id":"1","actor":"bob","verb":"post","object":"post:1"}]}`
+	gzipHeader := http.Header{"Content-Encoding": []string{"gzip"}}
+	testCases := []struct {
+		name   string
+		code   int
+		body   []byte
+		header http.Header
+		check  func(t *testing.T, resp *stream.FlatFeedResponse, err error)
+	}{
+		{
+			name:   "gzip",
+			body:   gzipped(t, body),
+			header: gzipHeader,
+			check: func(t *testing.T, resp *stream.FlatFeedResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, resp.Results, 1)
+				assert.Equal(t, "post:1", resp.Results[0].Object)
+			},
+		},
+		{
+			name:   "already decompressed",
+			body:   []byte(body),
+			header: gzipHeader,
+			check: func(t *testing.T, resp *stream.FlatFeedResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, resp.Results, 1)
+			},
+		},
+		{
+			name:   "corrupt",
+			body:   append([]byte{0x1f, 0x8b}, "garbage"...),
+			header: gzipHeader,
+			check: func(t *testing.T, _ *stream.FlatFeedResponse, err error) {
+				var terr *stream.TransportError
+				require.True(t, errors.As(err, &terr))
+				assert.Equal(t, "decompress response", terr.Op)
+			},
+		},
+		{
+			name:   "truncated",
+			body:   gzipped(t, body)[:20],
+			header: gzipHeader,
+			check: func(t *testing.T, _ *stream.FlatFeedResponse, err error) {
+				var terr *stream.TransportError
+				require.True(t, errors.As(err, &terr))
+				assert.Equal(t, "read response", terr.Op)
+			},
+		},
+		{
+			name:   "error",
+			code:   http.StatusNotFound,
+			body:   gzipped(t, `{"detail":"feed not found","status_code":404}`),
+			header: gzipHeader,
+			check: func(t *testing.T, _ *stream.FlatFeedResponse, err error) {
+				apiErr, ok := stream.ToAPIError(err)
+				require.True(t, ok)
+				assert.Equal(t, "feed not found", apiErr.Detail)
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requester := &encodedRequester{code: tc.code, body: tc.body, header: tc.header}
+			client, err := stream.NewClient("key", "secret", stream.WithHTTPRequester(requester))
+			require.NoError(t, err)
+			feed, err := client.FlatFeed("user", "bob")
+			require.NoError(t, err)
+			resp, err := feed.GetActivities()
+			tc.check(t, resp, err)
+		})
+	}
+}
+
+func TestCompressionMetrics(t *testing.T) {
+	body := `{"results":[` + strings.Repeat(`{"id":"1","actor":"bob","verb":"post","object":"post:1"},`, 50) + `{}]}`
+	compressed := gzipped(t, body)
+	requester := &encodedRequester{body: compressed, header: http.Header{"Content-Encoding": []string{"gzip"}}}
+	recorder := &metricsRecorder{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithMetricsCollector(recorder),
+		stream.WithCompression(stream.CompressionConfig{}),
+	)
+	require.NoError(t, err)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	resp, err := feed.GetActivities()
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 51)
+
+	require.Len(t, recorder.metrics, 1)
+	assert.Equal(t, len(body), recorder.metrics[0].BytesReceived)
+	assert.Equal(t, len(compressed), recorder.metrics[0].WireBytesReceived)
+}
+
+func TestCompressionEndToEnd(t *testing.T) {
+	client, err := streamtest.New().NewClient(stream.WithCompression(stream.CompressionConfig{Threshold: 1}))
+	require.NoError(t, err)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	_, err = feed.AddActivity(stream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	require.NoError(t, err)
+	resp, err := feed.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "post:1", resp.Results[0].Object)
+}
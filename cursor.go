@@ -0,0 +1,269 @@
+package stream
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidCursor is returned when resuming a read from a cursor which is
+// malformed, was not signed with the secret of the client, or belongs to
+// another feed or reactions filter.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursor is the content of a pagination cursor: the path of the paginated
+// endpoint, relative to the API version, and the query of the page.
+type cursor struct {
+	Path  string `json:"p"`
+	Query string `json:"q,omitempty"`
+}
+
+// NewCursor returns an opaque cursor for the page referenced by the given
+// "next" link, found in the Next field of feed and reaction filter responses.
+// The cursor holds the feed or filter, the read options (enrichment included)
+// and the position of the page, and is signed with the secret of the client:
+// it can be handed out to third parties and used later, possibly by another
+// process, to resume reading with GetActivitiesFromCursor,
+// GetEnrichedActivitiesFromCursor or FilterFromCursor.
+//
+// ErrMissingNextPage is returned if the link is empty, and ErrInvalidNextPage
+// if it doesn't point to a feed or reactions filter.
+func (c *Client) NewCursor(next string) (string, error) {
+	if next == "" {
+		return "", ErrMissingNextPage
+	}
+	u, err := url.Parse(next)
+	if err != nil {
+		return "", ErrInvalidNextPage
+	}
+	path, ok := apiRelativePath(u.Path)
+	if !ok || !(strings.HasPrefix(path, "feed/") || strings.HasPrefix(path, "enrich/feed/") || strings.HasPrefix(path, "reaction/")) {
+		return "", ErrInvalidNextPage
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return "", ErrInvalidNextPage
+	}
+	query.Del("api_key")
+	payload, err := json.Marshal(cursor{Path: path, Query: query.Encode()})
+	if err != nil {
+		return "", err
+	}
+	return c.authenticator.signCursor(payload), nil
+}
+
+// apiRelativePath returns the given URL path relative to the API version
+// (as in "feed/user/bob/" for "/api/v1.0/feed/user/bob"), always ending with
+// a slash.
+func apiRelativePath(path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+2 < len(segments); i++ {
+		if segments[i] == "api" && strings.HasPrefix(segments[i+1], "v") {
+			return strings.Join(segments[i+2:], "/") + "/", true
+		}
+	}
+	return "", false
+}
+
+// signCursor encodes the given cursor payload along with its signature.
+func (a authenticator) signCursor(payload []byte) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(a.cursorMAC(payload))
+}
+
+// parseCursor verifies the signature of the given cursor and returns its
+// content.
+func (a authenticator) parseCursor(s string) (*cursor, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return nil, ErrInvalidCursor
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := enc.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(mac, a.cursorMAC(payload)) {
+		return nil, ErrInvalidCursor
+	}
+	var cur cursor
+	if err := json.Unmarshal(payload, &cur); err != nil || cur.Path == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &cur, nil
+}
+
+func (a authenticator) cursorMAC(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(a.secret))
+	mac.Write([]byte("stream-cursor:"))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// cursorEndpoint returns the given endpoint with the query of the given
+// cursor, which must have been created for the same endpoint.
+func (c *Client) cursorEndpoint(endpoint endpoint, cur *cursor) (endpoint, error) {
+	if path, ok := apiRelativePath(endpoint.url.Path); !ok || path != cur.Path {
+		return endpoint, ErrInvalidCursor
+	}
+	endpoint, err := c.nextPageEndpoint(endpoint, "?"+cur.Query)
+	if err != nil {
+		return endpoint, ErrInvalidCursor
+	}
+	return endpoint, nil
+}
+
+// getCursorPage reads into out the page referenced by the given cursor, which
+// must have been created for the given endpoint.
+func (c *Client) getCursorPage(ctx context.Context, endpoint endpoint, s string, out interface{}, authFn authFunc) error {
+	cur, err := c.authenticator.parseCursor(s)
+	if err != nil {
+		return err
+	}
+	endpoint, err = c.cursorEndpoint(endpoint, cur)
+	if err != nil {
+		return err
+	}
+	return c.get(ctx, endpoint, nil, out, authFn)
+}
+
+func (c *Client) getActivitiesFromCursor(ctx context.Context, feed Feed, cursor string, out interface{}) error {
+	return c.getCursorPage(ctx, c.activitiesEndpoint(feed), cursor, out, c.authenticator.feedAuth(ResourceFeed, feed))
+}
+
+func (c *Client) getEnrichedActivitiesFromCursor(ctx context.Context, feed Feed, cursor string, out interface{}) error {
+	return c.getCursorPage(ctx, c.enrichedActivitiesEndpoint(feed), cursor, out, c.authenticator.feedAuth(ResourceFeed, feed))
+}
+
+// GetActivitiesFromCursor returns the activities of the FlatFeed at the page
+// referenced by the given cursor, created by Client.NewCursor from a previous
+// response for the same feed.
+func (f *FlatFeed) GetActivitiesFromCursor(cursor string) (*FlatFeedResponse, error) {
+	return f.GetActivitiesFromCursorContext(context.Background(), cursor)
+}
+
+// GetActivitiesFromCursorContext is like GetActivitiesFromCursor, using the provided context for the request.
+func (f *FlatFeed) GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*FlatFeedResponse, error) {
+	var resp FlatFeedResponse
+	if err := f.client.getActivitiesFromCursor(ctx, f, cursor, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetEnrichedActivitiesFromCursor returns the enriched activities of the
+// FlatFeed at the page referenced by the given cursor, created by
+// Client.NewCursor from a previous enriched response for the same feed.
+func (f *FlatFeed) GetEnrichedActivitiesFromCursor(cursor string) (*EnrichedFlatFeedResponse, error) {
+	return f.GetEnrichedActivitiesFromCursorContext(context.Background(), cursor)
+}
+
+// GetEnrichedActivitiesFromCursorContext is like GetEnrichedActivitiesFromCursor, using the provided context for the request.
+func (f *FlatFeed) GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*EnrichedFlatFeedResponse, error) {
+	var resp EnrichedFlatFeedResponse
+	if err := f.client.getEnrichedActivitiesFromCursor(ctx, f, cursor, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetActivitiesFromCursor returns the activities of the AggregatedFeed at the
+// page referenced by the given cursor, created by Client.NewCursor from a
+// previous response for the same feed.
+func (f *AggregatedFeed) GetActivitiesFromCursor(cursor string) (*AggregatedFeedResponse, error) {
+	return f.GetActivitiesFromCursorContext(context.Background(), cursor)
+}
+
+// GetActivitiesFromCursorContext is like GetActivitiesFromCursor, using the provided context for the request.
+func (f *AggregatedFeed) GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*AggregatedFeedResponse, error) {
+	var resp AggregatedFeedResponse
+	if err := f.client.getActivitiesFromCursor(ctx, f, cursor, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetEnrichedActivitiesFromCursor returns the enriched activities of the
+// AggregatedFeed at the page referenced by the given cursor, created by
+// Client.NewCursor from a previous enriched response for the same feed.
+func (f *AggregatedFeed) GetEnrichedActivitiesFromCursor(cursor string) (*EnrichedAggregatedFeedResponse, error) {
+	return f.GetEnrichedActivitiesFromCursorContext(context.Background(), cursor)
+}
+
+// GetEnrichedActivitiesFromCursorContext is like GetEnrichedActivitiesFromCursor, using the provided context for the request.
+func (f *AggregatedFeed) GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*EnrichedAggregatedFeedResponse, error) {
+	var resp EnrichedAggregatedFeedResponse
+	if err := f.client.getEnrichedActivitiesFromCursor(ctx, f, cursor, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetActivitiesFromCursor returns the activities of the NotificationFeed at
+// the page referenced by the given cursor, created by Client.NewCursor from a
+// previous response for the same feed.
+func (f *NotificationFeed) GetActivitiesFromCursor(cursor string) (*NotificationFeedResponse, error) {
+	return f.GetActivitiesFromCursorContext(context.Background(), cursor)
+}
+
+// GetActivitiesFromCursorContext is like GetActivitiesFromCursor, using the provided context for the request.
+func (f *NotificationFeed) GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*NotificationFeedResponse, error) {
+	var resp NotificationFeedResponse
+	if err := f.client.getActivitiesFromCursor(ctx, f, cursor, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetEnrichedActivitiesFromCursor returns the enriched activities of the
+// NotificationFeed at the page referenced by the given cursor, created by
+// Client.NewCursor from a previous enriched response for the same feed.
+func (f *NotificationFeed) GetEnrichedActivitiesFromCursor(cursor string) (*EnrichedNotificationFeedResponse, error) {
+	return f.GetEnrichedActivitiesFromCursorContext(context.Background(), cursor)
+}
+
+// GetEnrichedActivitiesFromCursorContext is like GetEnrichedActivitiesFromCursor, using the provided context for the request.
+func (f *NotificationFeed) GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*EnrichedNotificationFeedResponse, error) {
+	var resp EnrichedNotificationFeedResponse
+	if err := f.client.getEnrichedActivitiesFromCursor(ctx, f, cursor, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// FilterFromCursor returns the reactions at the page referenced by the given
+// cursor, created by Client.NewCursor from a previous *FilterReactionResponse
+// response.
+func (c *ReactionsClient) FilterFromCursor(cursor string) (*FilterReactionResponse, error) {
+	return c.FilterFromCursorContext(context.Background(), cursor)
+}
+
+// FilterFromCursorContext is like FilterFromCursor, using the provided context for the request.
+func (c *ReactionsClient) FilterFromCursorContext(ctx context.Context, cursor string) (*FilterReactionResponse, error) {
+	cur, err := c.client.authenticator.parseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(cur.Path, "reaction/") {
+		return nil, ErrInvalidCursor
+	}
+	filter := strings.TrimSuffix(strings.TrimPrefix(cur.Path, "reaction/"), "/")
+	attr := FilterReactionsAttribute(func() string { return filter })
+	endpoint, err := c.client.cursorEndpoint(c.filterEndpoint(attr), cur)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FilterReactionResponse{}
+	if err := c.client.get(ctx, endpoint, nil, result, c.client.authenticator.reactionsAuth); err != nil {
+		return nil, err
+	}
+	result.meta.attr = attr
+	return result, nil
+}
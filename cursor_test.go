@@ -0,0 +1,127 @@
+package stream_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedCursors(t *testing.T) {
+	server := streamtest.New()
+	client, err := server.NewClient()
+	require.NoError(t, err)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	ids := addActivities(t, feed, 5)
+
+	resp, err := feed.GetActivities(stream.WithActivitiesLimit(2))
+	require.NoError(t, err)
+	cursor, err := client.NewCursor(resp.Next)
+	require.NoError(t, err)
+
+	// resume from another client sharing the same secret
+	other, err := server.NewClient()
+	require.NoError(t, err)
+	resumed, err := other.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	page, err := resumed.GetActivitiesFromCursor(cursor)
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, ids[2:4], []string{page.Results[0].ID, page.Results[1].ID})
+	next, err := resumed.GetNextPageActivities(page)
+	require.NoError(t, err)
+	require.Len(t, next.Results, 1)
+	assert.Equal(t, ids[4], next.Results[0].ID)
+
+	enriched, err := feed.GetEnrichedActivities(stream.WithActivitiesLimit(4))
+	require.NoError(t, err)
+	cursor, err = client.NewCursor(enriched.Next)
+	require.NoError(t, err)
+	_, err = resumed.GetActivitiesFromCursor(cursor)
+	assert.Equal(t, stream.ErrInvalidCursor, err)
+	enrichedPage, err := resumed.GetEnrichedActivitiesFromCursor(cursor)
+	require.NoError(t, err)
+	require.Len(t, enrichedPage.Results, 1)
+	assert.Equal(t, ids[4], enrichedPage.Results[0].ID)
+
+	alice, err := client.FlatFeed("user", "alice")
+	require.NoError(t, err)
+	_, err = alice.GetEnrichedActivitiesFromCursor(cursor)
+	assert.Equal(t, stream.ErrInvalidCursor, err)
+	_, err = client.Reactions().FilterFromCursor(cursor)
+	assert.Equal(t, stream.ErrInvalidCursor, err)
+}
+
+func TestReactionsCursors(t *testing.T) {
+	client := newFakeClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	activity, err := feed.AddActivity(stream.Activity{Actor: "bob", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := client.Reactions().Add(stream.AddReactionRequestObject{Kind: "like", ActivityID: activity.ID, UserID: fmt.Sprintf("user%d", i)})
+		require.NoError(t, err)
+	}
+
+	resp, err := client.Reactions().Filter(stream.ByActivityID(activity.ID).ByKind("like"), stream.WithLimit(2))
+	require.NoError(t, err)
+	cursor, err := client.NewCursor(resp.Next)
+	require.NoError(t, err)
+
+	page, err := client.Reactions().FilterFromCursor(cursor)
+	require.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+	next, err := client.Reactions().GetNextPageFilteredReactions(page)
+	require.NoError(t, err)
+	assert.Len(t, next.Results, 1)
+
+	_, err = feed.GetActivitiesFromCursor(cursor)
+	assert.Equal(t, stream.ErrInvalidCursor, err)
+}
+
+func TestInvalidCursors(t *testing.T) {
+	client := newFakeClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+
+	_, err = client.NewCursor("")
+	assert.Equal(t, stream.ErrMissingNextPage, err)
+	_, err = client.NewCursor("/api/v1.0/user/bob/")
+	assert.Equal(t, stream.ErrInvalidNextPage, err)
+	_, err = client.NewCursor("/feed/user/bob/?id_lt=1")
+	assert.Equal(t, stream.ErrInvalidNextPage, err)
+
+	cursor, err := client.NewCursor("/api/v1.0/feed/user/bob/?limit=2&id_lt=1&api_key=key")
+	require.NoError(t, err)
+	_, err = feed.GetActivitiesFromCursor(cursor)
+	require.NoError(t, err)
+
+	otherSecret, err := stream.NewClient(streamtest.APIKey, "other", stream.WithHTTPRequester(streamtest.New()))
+	require.NoError(t, err)
+	otherFeed, err := otherSecret.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	_, err = otherFeed.GetActivitiesFromCursor(cursor)
+	assert.Equal(t, stream.ErrInvalidCursor, err)
+
+	parts := strings.Split(cursor, ".")
+	forged, err := client.NewCursor("/api/v1.0/feed/user/alice/?limit=2&id_lt=1")
+	require.NoError(t, err)
+	testCases := []string{
+		"",
+		"garbage",
+		parts[0],
+		parts[0] + ".",
+		parts[0] + "." + parts[1] + "x",
+		strings.Split(forged, ".")[0] + "." + parts[1],
+		cursor + "." + parts[1],
+	}
+	for _, tc := range testCases {
+		_, err := feed.GetActivitiesFromCursor(tc)
+		assert.Equal(t, stream.ErrInvalidCursor, err, tc)
+	}
+}
@@ -2,9 +2,6 @@ package stream
 
 import (
 	"encoding/json"
-	"fmt"
-
-	"github.com/fatih/structs"
 )
 
 // EnrichedActivity is an enriched Stream activity entity.
@@ -43,60 +40,229 @@ type EnrichedReaction struct {
 }
 
 // UnmarshalJSON decodes the provided JSON payload into the EnrichedActivity. It's required
-// because of the custom JSON fields and time formats.
+// because of the custom JSON fields and time formats. The standard fields are
+// decoded directly into their types, while the other ones are kept in Extra.
 func (a *EnrichedActivity) UnmarshalJSON(b []byte) error {
-	var data map[string]interface{}
-	if err := json.Unmarshal(b, &data); err != nil {
+	fields, err := decodeObject(b)
+	if err != nil {
 		return err
 	}
-
-	if _, ok := data["to"]; ok {
-		tos := data["to"].([]interface{})
-		simpleTos := make([]string, len(tos))
-		for i := range tos {
-			if sliceTos, isString := tos[i].(string); isString {
-				simpleTos[i] = sliceTos
-			} else if sliceTos, isSlice := tos[i].([]interface{}); isSlice {
-				tos, ok := sliceTos[0].(string)
-				if !ok {
-					return fmt.Errorf("invalid format for to targets")
-				}
-				simpleTos[i] = tos
-			}
+	var extra map[string]interface{}
+	for k, raw := range fields {
+		switch k {
+		case "id":
+			a.ID, err = decodeString(raw)
+		case "actor":
+			err = a.Actor.UnmarshalJSON(raw)
+		case "verb":
+			a.Verb, err = decodeString(raw)
+		case "object":
+			err = a.Object.UnmarshalJSON(raw)
+		case "foreign_id":
+			a.ForeignID, err = decodeString(raw)
+		case "target":
+			err = a.Target.UnmarshalJSON(raw)
+		case "time":
+			a.Time, err = decodeTime(raw)
+		case "origin":
+			err = a.Origin.UnmarshalJSON(raw)
+		case "to":
+			a.To, err = decodeToTargets(raw)
+		case "score":
+			err = json.Unmarshal(raw, &a.Score)
+		case "reaction_counts":
+			err = json.Unmarshal(raw, &a.ReactionCounts)
+		case "own_reactions":
+			err = json.Unmarshal(raw, &a.OwnReactions)
+		case "latest_reactions":
+			err = json.Unmarshal(raw, &a.LatestReactions)
+		default:
+			extra, err = setExtra(extra, k, raw)
+		}
+		if err != nil {
+			return fieldError(k, err)
 		}
-		data["to"] = simpleTos
 	}
-
-	return a.decode(data)
+	if extra != nil {
+		a.Extra = extra
+	}
+	return nil
 }
 
 // MarshalJSON encodes the EnrichedActivity to a valid JSON bytes slice. It's required because of
-// the custom JSON fields and time formats.
+// the custom JSON fields and time formats. Fields are sorted by name, and the
+// extra fields take precedence over the standard ones having the same name.
 func (a EnrichedActivity) MarshalJSON() ([]byte, error) {
-	data := structs.New(a).Map()
-	for k, v := range a.Extra {
-		data[k] = v
+	e := objectEncoder{buf: make([]byte, 0, 512)}
+	for _, f := range []struct{ key, value string }{
+		{"id", a.ID}, {"verb", a.Verb}, {"foreign_id", a.ForeignID},
+	} {
+		if f.value != "" {
+			e.setString(f.key, f.value)
+		}
+	}
+	for _, f := range []struct {
+		key   string
+		value Data
+	}{
+		{"actor", a.Actor}, {"object", a.Object}, {"target", a.Target}, {"origin", a.Origin},
+	} {
+		if f.value.ID == "" && f.value.Extra == nil {
+			continue
+		}
+		if err := e.setData(f.key, f.value); err != nil {
+			return nil, err
+		}
+	}
+	if !a.Time.IsZero() {
+		e.setTime("time", a.Time)
+	}
+	if a.To != nil {
+		e.setStrings("to", a.To)
+	}
+	for _, f := range []struct {
+		key   string
+		value interface{}
+		set   bool
+	}{
+		{"score", a.Score, a.Score != 0},
+		{"reaction_counts", a.ReactionCounts, a.ReactionCounts != nil},
+		{"own_reactions", a.OwnReactions, a.OwnReactions != nil},
+		{"latest_reactions", a.LatestReactions, a.LatestReactions != nil},
+	} {
+		if !f.set {
+			continue
+		}
+		if err := e.setValue(f.key, f.value, false); err != nil {
+			return nil, err
+		}
 	}
-	if _, ok := data["time"]; ok {
-		data["time"] = a.Time.Format(TimeLayout)
+	if err := e.setExtra(a.Extra, enrichedActivityFields); err != nil {
+		return nil, err
 	}
-	return json.Marshal(data)
+	if e.has("time") {
+		e.setTime("time", a.Time)
+	}
+	return e.encode(), nil
 }
 
-func (a *EnrichedActivity) decode(data map[string]interface{}) error {
-	meta, err := decodeData(data, a)
+// UnmarshalJSON decodes the provided JSON payload into the EnrichedReaction.
+func (r *EnrichedReaction) UnmarshalJSON(b []byte) error {
+	fields, err := decodeObject(b)
 	if err != nil {
 		return err
 	}
-	if len(meta.Unused) > 0 {
-		a.Extra = make(map[string]interface{})
-		for _, k := range meta.Unused {
-			a.Extra[k] = data[k]
+	for k, raw := range fields {
+		switch k {
+		case "id":
+			r.ID, err = decodeString(raw)
+		case "kind":
+			r.Kind, err = decodeString(raw)
+		case "activity_id":
+			r.ActivityID, err = decodeString(raw)
+		case "user_id":
+			r.UserID, err = decodeString(raw)
+		case "data":
+			err = json.Unmarshal(raw, &r.Data)
+		case "target_feeds":
+			err = json.Unmarshal(raw, &r.TargetFeeds)
+		case "parent":
+			r.ParentID, err = decodeString(raw)
+		case "latest_children":
+			err = json.Unmarshal(raw, &r.ChildrenReactions)
+		case "own_children":
+			err = json.Unmarshal(raw, &r.OwnChildren)
+		case "children_counts":
+			err = json.Unmarshal(raw, &r.ChildrenCounters)
+		case "user":
+			err = r.User.UnmarshalJSON(raw)
+		case "created_at":
+			r.CreatedAt, err = decodeTime(raw)
+		case "updated_at":
+			r.UpdatedAt, err = decodeTime(raw)
+		}
+		if err != nil {
+			return fieldError(k, err)
 		}
 	}
 	return nil
 }
 
+// MarshalJSON encodes the EnrichedReaction to a valid JSON bytes slice, with
+// the fields in the order they're declared.
+func (r EnrichedReaction) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 256)
+	b = append(b, '{')
+	if r.ID != "" {
+		b = append(b, `"id":`...)
+		b = appendString(b, r.ID)
+		b = append(b, ',')
+	}
+	b = append(b, `"kind":`...)
+	b = appendString(b, r.Kind)
+	b = append(b, `,"activity_id":`...)
+	b = appendString(b, r.ActivityID)
+	b = append(b, `,"user_id":`...)
+	b = appendString(b, r.UserID)
+	if len(r.Data) > 0 {
+		data, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, `,"data":`...)
+		b = append(b, data...)
+	}
+	if len(r.TargetFeeds) > 0 {
+		b = append(b, `,"target_feeds":`...)
+		b = appendStrings(b, r.TargetFeeds)
+	}
+	if r.ParentID != "" {
+		b = append(b, `,"parent":`...)
+		b = appendString(b, r.ParentID)
+	}
+	for _, f := range []struct {
+		key   string
+		value interface{}
+		set   bool
+	}{
+		{"latest_children", r.ChildrenReactions, len(r.ChildrenReactions) > 0},
+		{"own_children", r.OwnChildren, len(r.OwnChildren) > 0},
+		{"children_counts", r.ChildrenCounters, len(r.ChildrenCounters) > 0},
+	} {
+		if !f.set {
+			continue
+		}
+		data, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, ',')
+		b = appendString(b, f.key)
+		b = append(b, ':')
+		b = append(b, data...)
+	}
+	b = append(b, `,"user":`...)
+	var err error
+	if b, err = r.User.appendJSON(b); err != nil {
+		return nil, err
+	}
+	b = append(b, `,"created_at":`...)
+	b = appendString(b, r.CreatedAt.Format(TimeLayout))
+	b = append(b, `,"updated_at":`...)
+	b = appendString(b, r.UpdatedAt.Format(TimeLayout))
+	return append(b, '}'), nil
+}
+
+// enrichedActivityFields are the JSON fields of EnrichedActivity.
+var enrichedActivityFields = jsonFieldNames(EnrichedActivity{})
+
+// DecodeExtra decodes the extra fields of the EnrichedActivity into v, which is
+// usually a pointer to a struct describing the custom fields of a given verb.
+// Fields are matched following the rules of json.Unmarshal.
+func (a EnrichedActivity) DecodeExtra(v interface{}) error {
+	return decodeExtra(a.Extra, v)
+}
+
 // EnrichedActivityGroup is a group of enriched Activities obtained from aggregated feeds.
 type EnrichedActivityGroup struct {
 	baseActivityGroup
@@ -64,6 +64,42 @@ func TestEnrichedActivityUnmarshalJSON_toTargets(t *testing.T) {
 			data:        []byte(`{"to":[[123]]}`),
 			shouldError: true,
 		},
+		{
+			activity: stream.EnrichedActivity{},
+			data:     []byte(`{"to":null}`),
+		},
+		{
+			data:        []byte(`{"to":"abcd"}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[[]]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[["abcd", "foo"], [null]]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[42]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[{}]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[true]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":[null]}`),
+			shouldError: true,
+		},
+		{
+			data:        []byte(`{"to":["abcd", 42]}`),
+			shouldError: true,
+		},
 	}
 	for _, tc := range testCases {
 		var out stream.EnrichedActivity
@@ -76,3 +112,52 @@ func TestEnrichedActivityUnmarshalJSON_toTargets(t *testing.T) {
 		}
 	}
 }
+
+func TestEnrichedActivityDecodeExtra(t *testing.T) {
+	client := newFakeClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	extra := pictureExtra{URL: "http://example.com/1.jpg", Size: map[string]int{"width": 800, "height": 600}, Tags: []string{"cat"}}
+	activity := stream.Activity{Actor: "bob", Verb: "upload", Object: "picture:1"}
+	require.NoError(t, activity.EncodeExtra(extra))
+	_, err = feed.AddActivity(activity)
+	require.NoError(t, err)
+
+	resp, err := feed.GetEnrichedActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	var decoded pictureExtra
+	require.NoError(t, resp.Results[0].DecodeExtra(&decoded))
+	assert.Equal(t, extra, decoded)
+}
+
+func TestEnrichedActivityUnmarshalJSON_enrichedFields(t *testing.T) {
+	data := []byte(`{"id":"1","actor":{"id":"alice","data":{"name":"Alice"}},"verb":"post","object":"picture:1","target":null,"time":null,` +
+		`"reaction_counts":{"like":1},"latest_reactions":{"like":[{"id":"r1","kind":"like","activity_id":"1","user_id":"bob",` +
+		`"user":{"id":"bob","data":{"name":"Bob"}},"created_at":"2020-01-02T03:04:05.123456","data":{"emoji":"+1"}}]},"text":"hello"}`)
+	var out stream.EnrichedActivity
+	require.NoError(t, json.Unmarshal(data, &out))
+	created := time.Date(2020, 1, 2, 3, 4, 5, 123456000, time.UTC)
+	expected := stream.EnrichedActivity{
+		ID:             "1",
+		Actor:          stream.Data{ID: "alice", Extra: map[string]interface{}{"data": map[string]interface{}{"name": "Alice"}}},
+		Verb:           "post",
+		Object:         stream.Data{ID: "picture:1"},
+		ReactionCounts: map[string]int{"like": 1},
+		LatestReactions: map[string][]*stream.EnrichedReaction{
+			"like": {{
+				ID:         "r1",
+				Kind:       "like",
+				ActivityID: "1",
+				UserID:     "bob",
+				User:       stream.Data{ID: "bob", Extra: map[string]interface{}{"data": map[string]interface{}{"name": "Bob"}}},
+				CreatedAt:  stream.Time{Time: created},
+				Data:       map[string]interface{}{"emoji": "+1"},
+			}},
+		},
+		Extra: map[string]interface{}{"text": "hello"},
+	}
+	assert.Equal(t, expected, out)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"actor":42}`), &out))
+}
@@ -1,7 +1,11 @@
 package stream
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 )
 
 var (
@@ -9,6 +13,26 @@ var (
 	errInvalidUserID      = fmt.Errorf("invalid userID provided")
 )
 
+// Sentinel errors API calls can be matched against with errors.Is.
+var (
+	// ErrNotFound matches API errors having a 404 (Not Found) status code.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized matches API errors having a 401 (Unauthorized) or 403
+	// (Forbidden) status code.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited matches API errors having a 429 (Too Many Requests) status
+	// code.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrValidation matches API errors having a 400 (Bad Request) or 422
+	// (Unprocessable Entity) status code, returned for invalid input.
+	ErrValidation = errors.New("validation error")
+	// ErrServer matches API errors having a 5xx status code.
+	ErrServer = errors.New("server error")
+	// ErrTransport matches transport errors, i.e. failures to send a request
+	// or to receive its response.
+	ErrTransport = errors.New("transport error")
+)
+
 // APIError is an error returned by Stream API when the request cannot be
 // performed or errored server side.
 type APIError struct {
@@ -18,15 +42,102 @@ type APIError struct {
 	Exception       string                   `json:"exception,omitempty"`
 	ExceptionFields map[string][]interface{} `json:"exception_fields,omitempty"`
 	StatusCode      int                      `json:"status_code,omitempty"`
+	// RateLimit is the rate limit status reported by the API along with the
+	// error, if any.
+	RateLimit *RateLimit `json:"-"`
+	// Body is the raw response body.
+	Body []byte `json:"-"`
+	// Header holds the response headers.
+	Header http.Header `json:"-"`
+	// URL is the request URL, with credentials redacted.
+	URL string `json:"-"`
 }
 
 func (e APIError) Error() string {
 	return e.Detail
 }
 
-// ToAPIError tries to cast the provided error to APIError type, returning the
-// obtained APIError and whether the operation was successful.
+// Is tells whether the APIError matches the given sentinel error, based on its
+// status code.
+func (e APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrValidation:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrServer:
+		return e.StatusCode/100 == 5
+	}
+	return false
+}
+
+// TransportError is returned when a request cannot be sent or its response
+// cannot be read, e.g. because of network failures. It matches ErrTransport
+// and wraps the underlying error.
+type TransportError struct {
+	// Op is the operation which failed, e.g. "perform request".
+	Op string
+	// URL is the request URL, with credentials redacted.
+	URL string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("cannot %s: %s", e.Op, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Is tells whether the error matches the given sentinel error.
+func (e *TransportError) Is(target error) bool {
+	return target == ErrTransport
+}
+
+// ToAPIError tries to cast the provided error, or any error it wraps, to
+// APIError type, returning the obtained APIError and whether the operation was
+// successful.
 func ToAPIError(err error) (APIError, bool) {
-	se, ok := err.(APIError)
+	var se APIError
+	ok := errors.As(err, &se)
 	return se, ok
 }
+
+// IsRetryable tells whether the given error, returned by an API call, is
+// temporary and the call might succeed if performed again: that's the case of
+// transport errors, of rate limited calls and of 5xx API errors. Errors caused
+// by a canceled context or an expired deadline aren't retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return errors.Is(err, ErrTransport) || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer)
+}
+
+// redactedQueryParams are the query parameters holding credentials.
+var redactedQueryParams = []string{"api_key", "authorization"}
+
+// redactURL returns the given URL as a string, with the values of the query
+// parameters holding credentials redacted.
+func redactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	redacted := *u
+	query := redacted.Query()
+	for _, k := range redactedQueryParams {
+		if _, ok := query[k]; ok {
+			query.Set(k, "REDACTED")
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+	return redacted.String()
+}
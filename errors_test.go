@@ -1,13 +1,17 @@
 package stream_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/GetStream/stream-go2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestErrorUnmarshal(t *testing.T) {
@@ -43,6 +47,10 @@ func TestToAPIError(t *testing.T) {
 			err:   stream.APIError{},
 			match: true,
 		},
+		{
+			err:   fmt.Errorf("wrapped: %w", stream.APIError{}),
+			match: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -53,3 +61,92 @@ func TestToAPIError(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{
+		stream.ErrNotFound,
+		stream.ErrUnauthorized,
+		stream.ErrRateLimited,
+		stream.ErrValidation,
+		stream.ErrServer,
+		stream.ErrTransport,
+	}
+	testCases := []struct {
+		statusCode int
+		expected   error
+	}{
+		{statusCode: http.StatusNotFound, expected: stream.ErrNotFound},
+		{statusCode: http.StatusUnauthorized, expected: stream.ErrUnauthorized},
+		{statusCode: http.StatusForbidden, expected: stream.ErrUnauthorized},
+		{statusCode: http.StatusTooManyRequests, expected: stream.ErrRateLimited},
+		{statusCode: http.StatusBadRequest, expected: stream.ErrValidation},
+		{statusCode: http.StatusUnprocessableEntity, expected: stream.ErrValidation},
+		{statusCode: http.StatusInternalServerError, expected: stream.ErrServer},
+		{statusCode: http.StatusServiceUnavailable, expected: stream.ErrServer},
+		{statusCode: http.StatusConflict},
+	}
+	for _, tc := range testCases {
+		err := fmt.Errorf("wrapped: %w", stream.APIError{StatusCode: tc.statusCode})
+		for _, sentinel := range sentinels {
+			assert.Equal(t, sentinel == tc.expected, errors.Is(err, sentinel), "%d: %s", tc.statusCode, sentinel)
+		}
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := error(&stream.TransportError{Op: "perform request", Err: cause})
+	assert.Equal(t, "cannot perform request: connection refused", err.Error())
+	assert.True(t, errors.Is(err, stream.ErrTransport))
+	assert.True(t, errors.Is(err, cause))
+	assert.False(t, errors.Is(err, stream.ErrServer))
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{err: nil},
+		{err: errors.New("boom")},
+		{err: context.Canceled},
+		{err: &stream.TransportError{Op: "perform request", Err: context.DeadlineExceeded}},
+		{err: &stream.TransportError{Op: "perform request", Err: errors.New("boom")}, expected: true},
+		{err: stream.APIError{StatusCode: http.StatusTooManyRequests}, expected: true},
+		{err: stream.APIError{StatusCode: http.StatusBadGateway}, expected: true},
+		{err: fmt.Errorf("wrapped: %w", stream.APIError{StatusCode: http.StatusInternalServerError}), expected: true},
+		{err: stream.APIError{StatusCode: http.StatusNotFound}},
+		{err: stream.APIError{StatusCode: http.StatusBadRequest}},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, stream.IsRetryable(tc.err), "%v", tc.err)
+	}
+}
+
+func TestAPIErrorDetails(t *testing.T) {
+	header := http.Header{"X-Request-Id": []string{"abc"}}
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusBadGateway, body: `<html>bad gateway</html>`, header: header},
+		{err: errors.New("connection refused")},
+	}}
+	client := newRetryingClient(t, requester, 1)
+
+	_, err := client.Users().Get("bob")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, stream.ErrServer))
+	apiErr, ok := stream.ToAPIError(err)
+	require.True(t, ok)
+	assert.Equal(t, "unexpected error (status code 502)", apiErr.Error())
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, []byte(`<html>bad gateway</html>`), apiErr.Body)
+	assert.Equal(t, header, apiErr.Header)
+	assert.Equal(t, "https://api.stream-io-api.com/api/v1.0/user/bob/?api_key=REDACTED", apiErr.URL)
+
+	_, err = client.Users().Get("bob")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, stream.ErrTransport))
+	assert.True(t, stream.IsRetryable(err))
+	var transportErr *stream.TransportError
+	require.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, "https://api.stream-io-api.com/api/v1.0/user/bob/?api_key=REDACTED", transportErr.URL)
+}
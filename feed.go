@@ -1,6 +1,7 @@
 package stream
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 )
@@ -11,15 +12,23 @@ type Feed interface {
 	ID() string
 	Slug() string
 	UserID() string
-	AddActivity(Activity) (*AddActivityResponse, error)
-	AddActivities(...Activity) (*AddActivitiesResponse, error)
-	RemoveActivityByID(string) error
-	RemoveActivityByForeignID(string) error
-	Follow(*FlatFeed, ...FollowFeedOption) error
-	GetFollowing(...FollowingOption) (*FollowingResponse, error)
-	Unfollow(Feed, ...UnfollowOption) error
-	UpdateToTargets(Activity, ...UpdateToTargetsOption) error
-	RealtimeToken(bool) string
+	AddActivity(activity Activity) (*AddActivityResponse, error)
+	AddActivityContext(ctx context.Context, activity Activity) (*AddActivityResponse, error)
+	AddActivities(activities ...Activity) (*AddActivitiesResponse, error)
+	AddActivitiesContext(ctx context.Context, activities ...Activity) (*AddActivitiesResponse, error)
+	RemoveActivityByID(id string) error
+	RemoveActivityByIDContext(ctx context.Context, id string) error
+	RemoveActivityByForeignID(foreignID string) error
+	RemoveActivityByForeignIDContext(ctx context.Context, foreignID string) error
+	Follow(feed FlatFeedInterface, opts ...FollowFeedOption) error
+	FollowContext(ctx context.Context, feed FlatFeedInterface, opts ...FollowFeedOption) error
+	GetFollowing(opts ...FollowingOption) (*FollowingResponse, error)
+	GetFollowingContext(ctx context.Context, opts ...FollowingOption) (*FollowingResponse, error)
+	Unfollow(target Feed, opts ...UnfollowOption) error
+	UnfollowContext(ctx context.Context, target Feed, opts ...UnfollowOption) error
+	UpdateToTargets(activity Activity, opts ...UpdateToTargetsOption) error
+	UpdateToTargetsContext(ctx context.Context, activity Activity, opts ...UpdateToTargetsOption) error
+	RealtimeToken(readonly bool) string
 }
 
 type feed struct {
@@ -54,29 +63,54 @@ func newFeed(slug, userID string, client *Client) (*feed, error) {
 
 // AddActivity adds a new Activity to the feed.
 func (f *feed) AddActivity(activity Activity) (*AddActivityResponse, error) {
-	return f.client.addActivity(f, activity)
+	return f.AddActivityContext(context.Background(), activity)
+}
+
+// AddActivityContext is like AddActivity, using the provided context for the request.
+func (f *feed) AddActivityContext(ctx context.Context, activity Activity) (*AddActivityResponse, error) {
+	return f.client.addActivity(ctx, f, activity)
 }
 
 // AddActivities adds multiple activities to the feed.
 func (f *feed) AddActivities(activities ...Activity) (*AddActivitiesResponse, error) {
-	return f.client.addActivities(f, activities...)
+	return f.AddActivitiesContext(context.Background(), activities...)
+}
+
+// AddActivitiesContext is like AddActivities, using the provided context for the request.
+func (f *feed) AddActivitiesContext(ctx context.Context, activities ...Activity) (*AddActivitiesResponse, error) {
+	return f.client.addActivities(ctx, f, activities...)
 }
 
 // RemoveActivityByID removes an activity from the feed (if present), using the provided
 // id string argument as the ID field of the activity.
 func (f *feed) RemoveActivityByID(id string) error {
-	return f.client.removeActivityByID(f, id)
+	return f.RemoveActivityByIDContext(context.Background(), id)
+}
+
+// RemoveActivityByIDContext is like RemoveActivityByID, using the provided context for the request.
+func (f *feed) RemoveActivityByIDContext(ctx context.Context, id string) error {
+	return f.client.removeActivityByID(ctx, f, id)
 }
 
 // RemoveActivityByID removes an activity from the feed (if present), using the provided
 // foreignID string argument as the foreign_id field of the activity.
 func (f *feed) RemoveActivityByForeignID(foreignID string) error {
-	return f.client.removeActivityByForeignID(f, foreignID)
+	return f.RemoveActivityByForeignIDContext(context.Background(), foreignID)
+}
+
+// RemoveActivityByForeignIDContext is like RemoveActivityByForeignID, using the provided context for the request.
+func (f *feed) RemoveActivityByForeignIDContext(ctx context.Context, foreignID string) error {
+	return f.client.removeActivityByForeignID(ctx, f, foreignID)
 }
 
 // Follow follows the provided feed (which must be a FlatFeed), applying the provided FollowFeedOptions,
 // if any.
-func (f *feed) Follow(feed *FlatFeed, opts ...FollowFeedOption) error {
+func (f *feed) Follow(feed FlatFeedInterface, opts ...FollowFeedOption) error {
+	return f.FollowContext(context.Background(), feed, opts...)
+}
+
+// FollowContext is like Follow, using the provided context for the request.
+func (f *feed) FollowContext(ctx context.Context, feed FlatFeedInterface, opts ...FollowFeedOption) error {
 	followOptions := &followFeedOptions{
 		Target:            fmt.Sprintf("%s:%s", feed.Slug(), feed.UserID()),
 		ActivityCopyLimit: defaultActivityCopyLimit,
@@ -84,24 +118,39 @@ func (f *feed) Follow(feed *FlatFeed, opts ...FollowFeedOption) error {
 	for _, opt := range opts {
 		opt(followOptions)
 	}
-	return f.client.follow(f, followOptions)
+	return f.client.follow(ctx, f, followOptions)
 }
 
 // GetFollowing returns the list of the feeds following the feed, applying the provided FollowingOptions,
 // if any.
 func (f *feed) GetFollowing(opts ...FollowingOption) (*FollowingResponse, error) {
-	return f.client.getFollowing(f, opts...)
+	return f.GetFollowingContext(context.Background(), opts...)
+}
+
+// GetFollowingContext is like GetFollowing, using the provided context for the request.
+func (f *feed) GetFollowingContext(ctx context.Context, opts ...FollowingOption) (*FollowingResponse, error) {
+	return f.client.getFollowing(ctx, f, opts...)
 }
 
 // Unfollow unfollows the provided feed, applying the provided UnfollowOptions, if any.
 func (f *feed) Unfollow(target Feed, opts ...UnfollowOption) error {
-	return f.client.unfollow(f, target.ID(), opts...)
+	return f.UnfollowContext(context.Background(), target, opts...)
+}
+
+// UnfollowContext is like Unfollow, using the provided context for the request.
+func (f *feed) UnfollowContext(ctx context.Context, target Feed, opts ...UnfollowOption) error {
+	return f.client.unfollow(ctx, f, target.ID(), opts...)
 }
 
 // UpdateToTargets updates the "to" targets for the provided activity, with the options passed
 // as argument for replacing, adding, or removing to targets.
 func (f *feed) UpdateToTargets(activity Activity, opts ...UpdateToTargetsOption) error {
-	return f.client.updateToTargets(f, activity, opts...)
+	return f.UpdateToTargetsContext(context.Background(), activity, opts...)
+}
+
+// UpdateToTargetsContext is like UpdateToTargets, using the provided context for the request.
+func (f *feed) UpdateToTargetsContext(ctx context.Context, activity Activity, opts ...UpdateToTargetsOption) error {
+	return f.client.updateToTargets(ctx, f, activity, opts...)
 }
 
 // RealtimeToken returns a token that can be used client-side to listen in real-time to feed changes.
@@ -113,7 +162,7 @@ func (f *feed) RealtimeToken(readonly bool) string {
 		action = actionWrite
 	}
 	id := f.client.authenticator.feedID(f)
-	claims := f.client.authenticator.jwtFeedClaims(resFeed, action, id)
+	claims := f.client.authenticator.jwtFeedClaims(ResourceFeed, action, id)
 	token, err := f.client.authenticator.jwtSignatureFromClaims(claims)
 	if err != nil {
 		return ""
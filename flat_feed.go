@@ -1,6 +1,6 @@
 package stream
 
-import "encoding/json"
+import "context"
 
 // FlatFeed is a Stream flat feed.
 type FlatFeed struct {
@@ -10,12 +10,13 @@ type FlatFeed struct {
 // GetActivities returns the activities for the given FlatFeed, filtering
 // results with the provided GetActivitiesOption parameters.
 func (f *FlatFeed) GetActivities(opts ...GetActivitiesOption) (*FlatFeedResponse, error) {
-	body, err := f.client.getActivities(f, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return f.GetActivitiesContext(context.Background(), opts...)
+}
+
+// GetActivitiesContext is like GetActivities, using the provided context for the request.
+func (f *FlatFeed) GetActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*FlatFeedResponse, error) {
 	var resp FlatFeedResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := f.client.getActivities(ctx, f, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -24,33 +25,49 @@ func (f *FlatFeed) GetActivities(opts ...GetActivitiesOption) (*FlatFeedResponse
 // GetNextPageActivities returns the activities for the given FlatFeed at the "next" page
 // of a previous *FlatFeedResponse response, if any.
 func (f *FlatFeed) GetNextPageActivities(resp *FlatFeedResponse) (*FlatFeedResponse, error) {
-	opts, err := resp.parseNext()
-	if err != nil {
+	return f.GetNextPageActivitiesContext(context.Background(), resp)
+}
+
+// GetNextPageActivitiesContext is like GetNextPageActivities, using the provided context for the request.
+func (f *FlatFeed) GetNextPageActivitiesContext(ctx context.Context, resp *FlatFeedResponse) (*FlatFeedResponse, error) {
+	var next FlatFeedResponse
+	if err := f.client.getNextActivities(ctx, f, resp.Next, &next); err != nil {
 		return nil, err
 	}
-	return f.GetActivities(opts...)
+	return &next, nil
 }
 
 // GetActivitiesWithRanking returns the activities (filtered) for the given FlatFeed,
 // using the provided ranking method.
 func (f *FlatFeed) GetActivitiesWithRanking(ranking string, opts ...GetActivitiesOption) (*FlatFeedResponse, error) {
-	return f.GetActivities(append(opts, withActivitiesRanking(ranking))...)
+	return f.GetActivitiesWithRankingContext(context.Background(), ranking, opts...)
+}
+
+// GetActivitiesWithRankingContext is like GetActivitiesWithRanking, using the provided context for the request.
+func (f *FlatFeed) GetActivitiesWithRankingContext(ctx context.Context, ranking string, opts ...GetActivitiesOption) (*FlatFeedResponse, error) {
+	return f.GetActivitiesContext(ctx, append(opts, withActivitiesRanking(ranking))...)
 }
 
 // GetFollowers returns the feeds following the given FlatFeed.
 func (f *FlatFeed) GetFollowers(opts ...FollowersOption) (*FollowersResponse, error) {
-	return f.client.getFollowers(f, opts...)
+	return f.GetFollowersContext(context.Background(), opts...)
+}
+
+// GetFollowersContext is like GetFollowers, using the provided context for the request.
+func (f *FlatFeed) GetFollowersContext(ctx context.Context, opts ...FollowersOption) (*FollowersResponse, error) {
+	return f.client.getFollowers(ctx, f, opts...)
 }
 
 // GetEnrichedActivities returns the enriched activities for the given FlatFeed, filtering
 // results with the provided GetActivitiesOption parameters.
 func (f *FlatFeed) GetEnrichedActivities(opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error) {
-	body, err := f.client.getEnrichedActivities(f, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return f.GetEnrichedActivitiesContext(context.Background(), opts...)
+}
+
+// GetEnrichedActivitiesContext is like GetEnrichedActivities, using the provided context for the request.
+func (f *FlatFeed) GetEnrichedActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error) {
 	var resp EnrichedFlatFeedResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := f.client.getEnrichedActivities(ctx, f, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -59,15 +76,25 @@ func (f *FlatFeed) GetEnrichedActivities(opts ...GetActivitiesOption) (*Enriched
 // GetNextPageEnrichedActivities returns the enriched activities for the given FlatFeed at the "next" page
 // of a previous *EnrichedFlatFeedResponse response, if any.
 func (f *FlatFeed) GetNextPageEnrichedActivities(resp *EnrichedFlatFeedResponse) (*EnrichedFlatFeedResponse, error) {
-	opts, err := resp.parseNext()
-	if err != nil {
+	return f.GetNextPageEnrichedActivitiesContext(context.Background(), resp)
+}
+
+// GetNextPageEnrichedActivitiesContext is like GetNextPageEnrichedActivities, using the provided context for the request.
+func (f *FlatFeed) GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *EnrichedFlatFeedResponse) (*EnrichedFlatFeedResponse, error) {
+	var next EnrichedFlatFeedResponse
+	if err := f.client.getNextEnrichedActivities(ctx, f, resp.Next, &next); err != nil {
 		return nil, err
 	}
-	return f.GetEnrichedActivities(opts...)
+	return &next, nil
 }
 
 // GetEnrichedActivitiesWithRanking returns the enriched activities (filtered) for the given FlatFeed,
 // using the provided ranking method.
 func (f *FlatFeed) GetEnrichedActivitiesWithRanking(ranking string, opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error) {
-	return f.GetEnrichedActivities(append(opts, withActivitiesRanking(ranking))...)
+	return f.GetEnrichedActivitiesWithRankingContext(context.Background(), ranking, opts...)
+}
+
+// GetEnrichedActivitiesWithRankingContext is like GetEnrichedActivitiesWithRanking, using the provided context for the request.
+func (f *FlatFeed) GetEnrichedActivitiesWithRankingContext(ctx context.Context, ranking string, opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error) {
+	return f.GetEnrichedActivitiesContext(ctx, append(opts, withActivitiesRanking(ranking))...)
 }
@@ -1,8 +1,12 @@
 package stream_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 
 	stream "github.com/GetStream/stream-go2"
@@ -103,3 +107,101 @@ func TestFlatFeedGetNextPageActivities(t *testing.T) {
 	_, err = flat.GetNextPageActivities(resp)
 	require.Error(t, err)
 }
+
+func TestFlatFeedGetNextPageActivities_basePath(t *testing.T) {
+	requester := &mockRequester{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithBaseURL("https://proxy.example.com/stream"),
+		stream.WithHTTPRequester(requester),
+	)
+	require.NoError(t, err)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	requester.resp = `{"next":"/api/v1.0/feed/flat/123/?id_lt=78c1a709-aff2-11e7-b3a7-a45e60be7d3b&limit=25"}`
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	_, err = flat.GetNextPageActivities(resp)
+	require.NoError(t, err)
+	testRequest(t, requester.req, http.MethodGet, "https://proxy.example.com/stream/api/v1.0/feed/flat/123/?api_key=key&id_lt=78c1a709-aff2-11e7-b3a7-a45e60be7d3b&limit=25", "")
+}
+
+// staticRequester replies to every request with the same response body.
+type staticRequester struct {
+	body []byte
+}
+
+func (r staticRequester) Do(*http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(r.body)),
+	}, nil
+}
+
+// enrichedPage returns the body of a page of enriched activities, each having
+// an enriched actor and object, reactions and custom fields.
+func enrichedPage(n int) []byte {
+	var b strings.Builder
+	b.WriteString(`{"next":"/api/v1.0/enrich/feed/flat/123/?id_lt=0","duration":"12.34ms","results":[`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `{"id":"%[1]d","actor":{"id":"alice","collection":"user","data":{"name":"Alice","avatar":"https://example.com/alice.png"}},`+
+			`"verb":"post","object":{"id":"picture:%[1]d","collection":"pictures","data":{"url":"https://example.com/%[1]d.png","width":640,"height":480}},`+
+			`"foreign_id":"post:%[1]d","time":"2020-01-02T03:04:05.123456","to":["timeline:bob","notification:carol"],`+
+			`"reaction_counts":{"like":2,"comment":1},`+
+			`"latest_reactions":{"like":[{"id":"r%[1]d","kind":"like","activity_id":"%[1]d","user_id":"bob","data":{},"created_at":"2020-01-02T03:04:05.123456","updated_at":"2020-01-02T03:04:05.123456","user":{"id":"bob","data":{"name":"Bob"}}}]},`+
+			`"own_reactions":{},"text":"picture number %[1]d","tags":["summer","beach"],"location":{"lat":45.5,"lng":9.2}}`, i)
+	}
+	b.WriteString("]}")
+	return []byte(b.String())
+}
+
+// BenchmarkFlatFeedGetEnrichedActivities compares decoding the response as it's
+// read by the client with buffering it and unmarshaling it, as done before.
+func BenchmarkFlatFeedGetEnrichedActivities(b *testing.B) {
+	for _, n := range []int{10, 100} {
+		body := enrichedPage(n)
+		requester := staticRequester{body: body}
+		client, err := stream.NewClient("key", "secret", stream.WithHTTPRequester(requester))
+		require.NoError(b, err)
+		flat, err := client.FlatFeed("flat", "123")
+		require.NoError(b, err)
+		for _, bc := range []struct {
+			name string
+			get  func() (*stream.EnrichedFlatFeedResponse, error)
+		}{
+			{"buffered", func() (*stream.EnrichedFlatFeedResponse, error) {
+				resp, err := requester.Do(nil)
+				if err != nil {
+					return nil, err
+				}
+				defer resp.Body.Close()
+				data, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					return nil, err
+				}
+				var out stream.EnrichedFlatFeedResponse
+				if err := json.Unmarshal(data, &out); err != nil {
+					return nil, err
+				}
+				return &out, nil
+			}},
+			{"streaming", func() (*stream.EnrichedFlatFeedResponse, error) { return flat.GetEnrichedActivities() }},
+		} {
+			b.Run(fmt.Sprintf("%d/%s", n, bc.name), func(b *testing.B) {
+				b.SetBytes(int64(len(body)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					resp, err := bc.get()
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(resp.Results) != n {
+						b.Fatalf("got %d activities, want %d", len(resp.Results), n)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,217 @@
+//go:build go1.18
+// +build go1.18
+
+package stream_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/require"
+)
+
+// responseSeeds returns the real API responses of testdata/responses.
+func responseSeeds(f *testing.F) [][]byte {
+	paths, err := filepath.Glob(filepath.Join("testdata", "responses", "*.json"))
+	require.NoError(f, err)
+	require.NotEmpty(f, paths)
+	seeds := make([][]byte, len(paths))
+	for i, path := range paths {
+		seeds[i], err = ioutil.ReadFile(path)
+		require.NoError(f, err)
+	}
+	return seeds
+}
+
+// activitySeeds returns the activities of the real API responses, including
+// the ones of aggregated and notification groups.
+func activitySeeds(f *testing.F) [][]byte {
+	var seeds [][]byte
+	for _, resp := range responseSeeds(f) {
+		var page struct {
+			Results []json.RawMessage `json:"results"`
+		}
+		require.NoError(f, json.Unmarshal(resp, &page))
+		for _, result := range page.Results {
+			var group struct {
+				Activities []json.RawMessage `json:"activities"`
+			}
+			require.NoError(f, json.Unmarshal(result, &group))
+			seeds = append(seeds, result)
+			for _, activity := range group.Activities {
+				seeds = append(seeds, activity)
+			}
+		}
+	}
+	return seeds
+}
+
+// malformedActivities are activities the API shouldn't return, but which
+// must be rejected or decoded without panicking.
+var malformedActivities = []string{
+	`null`,
+	`[]`,
+	`"activity"`,
+	`{"to":null}`,
+	`{"to":"timeline:alice"}`,
+	`{"to":[[]]}`,
+	`{"to":[[null]]}`,
+	`{"to":[null,42,{}]}`,
+	`{"time":42}`,
+	`{"time":"yesterday"}`,
+	`{"time":null,"score":null}`,
+	`{"score":"high"}`,
+	`{"actor":{"id":42}}`,
+	`{"actor":[],"object":null}`,
+	`{"latest_reactions":{"like":[{"user":"alice","data":null}]}}`,
+	`{"id":"\ud800","verb":" <>&"}`,
+}
+
+// roundTrip checks that the decoded value encodes to JSON that decodes back
+// to a value with the same encoding.
+func roundTrip(t *testing.T, v interface{}, decoded interface{}) {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, decoded), string(data))
+	again, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(again))
+}
+
+func FuzzActivityUnmarshalJSON(f *testing.F) {
+	for _, seed := range activitySeeds(f) {
+		f.Add(seed)
+	}
+	for _, seed := range malformedActivities {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var activity stream.Activity
+		if err := json.Unmarshal(data, &activity); err != nil {
+			return
+		}
+		roundTrip(t, activity, &stream.Activity{})
+	})
+}
+
+func FuzzEnrichedActivityUnmarshalJSON(f *testing.F) {
+	for _, seed := range activitySeeds(f) {
+		f.Add(seed)
+	}
+	for _, seed := range malformedActivities {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var activity stream.EnrichedActivity
+		if err := json.Unmarshal(data, &activity); err != nil {
+			return
+		}
+		roundTrip(t, activity, &stream.EnrichedActivity{})
+	})
+}
+
+func FuzzDataUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{
+		`"bob"`,
+		`{"id":"bob","data":{"name":"Bob"},"created_at":"2019-01-09T13:55:35.962557Z"}`,
+		`{"collection":"pictures","id":"1","data":{"url":"https://example.com/1.jpg"}}`,
+		`null`,
+		`{"id":null}`,
+		`{"id":42}`,
+		`42`,
+		`[]`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var d stream.Data
+		if err := json.Unmarshal(data, &d); err != nil {
+			return
+		}
+		roundTrip(t, d, &stream.Data{})
+	})
+}
+
+func FuzzTimeUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{
+		`"2014-07-01T13:20:45.185000"`,
+		`"2019-01-09T13:55:35.962557Z"`,
+		`"2019-01-09 13:55:35.962557+00:00"`,
+		`"2019-01-09"`,
+		`""`,
+		`"`,
+		`null`,
+		`42`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var tt stream.Time
+		if err := tt.UnmarshalJSON(data); err != nil {
+			return
+		}
+		roundTrip(t, tt, &stream.Time{})
+	})
+}
+
+func FuzzDurationUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{
+		`"9.45ms"`,
+		`"1h30m"`,
+		`"-1.5s"`,
+		`1.5`,
+		`1e300`,
+		`"forever"`,
+		`null`,
+		`{}`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var d stream.Duration
+		if err := d.UnmarshalJSON(data); err != nil {
+			return
+		}
+		roundTrip(t, d, &stream.Duration{})
+	})
+}
+
+// FuzzResponses decodes arbitrary response bodies through the client, with
+// every number decoding, checking that it never panics.
+func FuzzResponses(f *testing.F) {
+	for _, seed := range responseSeeds(f) {
+		f.Add(seed)
+	}
+	for _, seed := range malformedActivities {
+		f.Add([]byte(`{"results":[` + seed + `]}`))
+		f.Add([]byte(`{"results":[{"activities":[` + seed + `]}]}`))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, decoding := range []stream.NumberDecoding{stream.NumbersAsFloat64, stream.NumbersAsJSONNumber, stream.NumbersAsInt64} {
+			client, err := stream.NewClient("key", "secret",
+				stream.WithHTTPRequester(staticRequester{body: data}),
+				stream.WithNumberDecoding(decoding),
+			)
+			require.NoError(t, err)
+			flat, err := client.FlatFeed("user", "bob")
+			require.NoError(t, err)
+			aggregated, err := client.AggregatedFeed("aggregated", "bob")
+			require.NoError(t, err)
+			notification, err := client.NotificationFeed("notification", "bob")
+			require.NoError(t, err)
+
+			_, _ = flat.GetActivities()
+			_, _ = flat.GetEnrichedActivities()
+			_, _ = aggregated.GetActivities()
+			_, _ = aggregated.GetEnrichedActivities()
+			_, _ = notification.GetActivities()
+			_, _ = notification.GetEnrichedActivities()
+			_, _ = client.Personalization().Get("follow_recommendations", nil)
+			_, _ = client.Reactions().Get("r1")
+			_, _ = client.Users().Get("bob")
+		}
+	})
+}
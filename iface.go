@@ -1,45 +1,358 @@
 package stream
 
+import "context"
+
+// ClientInterface is the interface implemented by Client, exposing every API
+// call along with the sub-clients and the feeds.
 type ClientInterface interface {
 	// FlatFeed returns a new Flat Feed with the provided slug and userID.
-	FlatFeed(slug, userID string) (*FlatFeed, error)
+	FlatFeed(slug, userID string) (FlatFeedInterface, error)
 	// AggregatedFeed returns a new Aggregated Feed with the provided slug and
 	// userID.
-	AggregatedFeed(slug, userID string) (*AggregatedFeed, error)
+	AggregatedFeed(slug, userID string) (AggregatedFeedInterface, error)
 	// NotificationFeed returns a new Notification Feed with the provided slug and
 	// userID.
-	NotificationFeed(slug, userID string) (*NotificationFeed, error)
+	NotificationFeed(slug, userID string) (NotificationFeedInterface, error)
 	// AddToMany adds an activity to multiple feeds at once.
 	AddToMany(activity Activity, feeds ...Feed) error
+	// AddToManyContext is like AddToMany, using the provided context for the request.
+	AddToManyContext(ctx context.Context, activity Activity, feeds ...Feed) error
 	// FollowMany creates multiple follows at once.
 	FollowMany(relationships []FollowRelationship, opts ...FollowManyOption) error
+	// FollowManyContext is like FollowMany, using the provided context for the request.
+	FollowManyContext(ctx context.Context, relationships []FollowRelationship, opts ...FollowManyOption) error
 	// UnfollowMany removes multiple follow relationships at once.
 	UnfollowMany(relationships []UnfollowRelationship) error
+	// UnfollowManyContext is like UnfollowMany, using the provided context for the request.
+	UnfollowManyContext(ctx context.Context, relationships []UnfollowRelationship) error
+	// BulkAddActivities adds activities to a feed in chunks, reporting which ones failed.
+	BulkAddActivities(feed Feed, activities []Activity, config BulkConfig) (*BulkActivitiesResult, error)
+	// BulkAddActivitiesContext is like BulkAddActivities, using the provided context for the requests.
+	BulkAddActivitiesContext(ctx context.Context, feed Feed, activities []Activity, config BulkConfig) (*BulkActivitiesResult, error)
+	// BulkUpdateActivities updates activities in chunks, reporting which ones failed.
+	BulkUpdateActivities(activities []Activity, config BulkConfig) (*BulkResult, error)
+	// BulkUpdateActivitiesContext is like BulkUpdateActivities, using the provided context for the requests.
+	BulkUpdateActivitiesContext(ctx context.Context, activities []Activity, config BulkConfig) (*BulkResult, error)
+	// BulkPartialUpdateActivities performs partial updates in chunks, reporting which ones failed.
+	BulkPartialUpdateActivities(changesets []UpdateActivityRequest, config BulkConfig) (*BulkActivitiesResult, error)
+	// BulkPartialUpdateActivitiesContext is like BulkPartialUpdateActivities, using the provided context for the requests.
+	BulkPartialUpdateActivitiesContext(ctx context.Context, changesets []UpdateActivityRequest, config BulkConfig) (*BulkActivitiesResult, error)
+	// BulkAddToMany adds an activity to feeds in chunks, reporting which feeds failed.
+	BulkAddToMany(activity Activity, feeds []Feed, config BulkConfig) (*BulkResult, error)
+	// BulkAddToManyContext is like BulkAddToMany, using the provided context for the requests.
+	BulkAddToManyContext(ctx context.Context, activity Activity, feeds []Feed, config BulkConfig) (*BulkResult, error)
+	// BulkFollowMany creates follow relationships in chunks, reporting which ones failed.
+	BulkFollowMany(relationships []FollowRelationship, config BulkConfig, opts ...FollowManyOption) (*BulkResult, error)
+	// BulkFollowManyContext is like BulkFollowMany, using the provided context for the requests.
+	BulkFollowManyContext(ctx context.Context, relationships []FollowRelationship, config BulkConfig, opts ...FollowManyOption) (*BulkResult, error)
+	// BulkUnfollowMany removes follow relationships in chunks, reporting which ones failed.
+	BulkUnfollowMany(relationships []UnfollowRelationship, config BulkConfig) (*BulkResult, error)
+	// BulkUnfollowManyContext is like BulkUnfollowMany, using the provided context for the requests.
+	BulkUnfollowManyContext(ctx context.Context, relationships []UnfollowRelationship, config BulkConfig) (*BulkResult, error)
 	// Analytics returns a new AnalyticsClient sharing the base configuration of the original Client.
-	Analytics() *AnalyticsClient
+	Analytics() AnalyticsClientInterface
 	// Collections returns a new CollectionsClient.
-	Collections() *CollectionsClient
+	Collections() CollectionsClientInterface
 	// Users returns a new UsersClient.
-	Users() *UsersClient
+	Users() UsersClientInterface
 	// Reactions returns a new ReactionsClient.
-	Reactions() *ReactionsClient
+	Reactions() ReactionsClientInterface
 	// Personalization returns a new PersonalizationClient.
-	Personalization() *PersonalizationClient
+	Personalization() PersonalizationClientInterface
 	// GetActivitiesByID returns activities for the current app having the given IDs.
 	GetActivitiesByID(ids ...string) (*GetActivitiesResponse, error)
+	// GetActivitiesByIDContext is like GetActivitiesByID, using the provided context for the request.
+	GetActivitiesByIDContext(ctx context.Context, ids ...string) (*GetActivitiesResponse, error)
 	// GetActivitiesByForeignID returns activities for the current app having the given foreign IDs and timestamps.
 	GetActivitiesByForeignID(values ...ForeignIDTimePair) (*GetActivitiesResponse, error)
+	// GetActivitiesByForeignIDContext is like GetActivitiesByForeignID, using the provided context for the request.
+	GetActivitiesByForeignIDContext(ctx context.Context, values ...ForeignIDTimePair) (*GetActivitiesResponse, error)
 	// UpdateActivities updates existing activities.
 	UpdateActivities(activities ...Activity) error
+	// UpdateActivitiesContext is like UpdateActivities, using the provided context for the request.
+	UpdateActivitiesContext(ctx context.Context, activities ...Activity) error
 	// PartialUpdateActivities performs a partial update on multiple activities with the given set and unset operations
 	// specified by each changeset. This returns the affected activities.
 	PartialUpdateActivities(changesets ...UpdateActivityRequest) (*UpdateActivitiesResponse, error)
+	// PartialUpdateActivitiesContext is like PartialUpdateActivities, using the provided context for the request.
+	PartialUpdateActivitiesContext(ctx context.Context, changesets ...UpdateActivityRequest) (*UpdateActivitiesResponse, error)
 	// UpdateActivityByID performs a partial activity update with the given set and unset operations, returning the
 	// affected activity, on the activity with the given ID.
 	UpdateActivityByID(id string, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error)
+	// UpdateActivityByIDContext is like UpdateActivityByID, using the provided context for the request.
+	UpdateActivityByIDContext(ctx context.Context, id string, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error)
 	// UpdateActivityByForeignID performs a partial activity update with the given set and unset operations, returning the
 	// affected activity, on the activity with the given foreign ID and timestamp.
 	UpdateActivityByForeignID(foreignID string, timestamp Time, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error)
+	// UpdateActivityByForeignIDContext is like UpdateActivityByForeignID, using the provided context for the request.
+	UpdateActivityByForeignIDContext(ctx context.Context, foreignID string, timestamp Time, set map[string]interface{}, unset []string) (*UpdateActivityResponse, error)
+	// GetUserSessionToken returns a JWT for the given user, to be used by
+	// client-side applications.
 	GetUserSessionToken(userID string) (string, error)
+	// GetUserSessionTokenWithClaims is like GetUserSessionToken, adding the
+	// given claims to the token.
 	GetUserSessionTokenWithClaims(userID string, claims map[string]interface{}) (string, error)
+	// NewCursor returns an opaque, signed cursor for the page referenced by the
+	// given "next" link of a feed or reactions filter response.
+	NewCursor(next string) (string, error)
+}
+
+// FlatFeedInterface is the interface implemented by FlatFeed.
+type FlatFeedInterface interface {
+	Feed
+	// GetActivities returns the activities for the given FlatFeed, filtering
+	// results with the provided GetActivitiesOption parameters.
+	GetActivities(opts ...GetActivitiesOption) (*FlatFeedResponse, error)
+	// GetActivitiesContext is like GetActivities, using the provided context for the request.
+	GetActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*FlatFeedResponse, error)
+	// GetNextPageActivities returns the activities for the given FlatFeed at the "next" page
+	// of a previous *FlatFeedResponse response, if any.
+	GetNextPageActivities(resp *FlatFeedResponse) (*FlatFeedResponse, error)
+	// GetNextPageActivitiesContext is like GetNextPageActivities, using the provided context for the request.
+	GetNextPageActivitiesContext(ctx context.Context, resp *FlatFeedResponse) (*FlatFeedResponse, error)
+	// GetActivitiesWithRanking returns the activities (filtered) for the given FlatFeed,
+	// using the provided ranking method.
+	GetActivitiesWithRanking(ranking string, opts ...GetActivitiesOption) (*FlatFeedResponse, error)
+	// GetActivitiesWithRankingContext is like GetActivitiesWithRanking, using the provided context for the request.
+	GetActivitiesWithRankingContext(ctx context.Context, ranking string, opts ...GetActivitiesOption) (*FlatFeedResponse, error)
+	// GetFollowers returns the feeds following the given FlatFeed.
+	GetFollowers(opts ...FollowersOption) (*FollowersResponse, error)
+	// GetFollowersContext is like GetFollowers, using the provided context for the request.
+	GetFollowersContext(ctx context.Context, opts ...FollowersOption) (*FollowersResponse, error)
+	// GetEnrichedActivities returns the enriched activities for the given FlatFeed, filtering
+	// results with the provided GetActivitiesOption parameters.
+	GetEnrichedActivities(opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error)
+	// GetEnrichedActivitiesContext is like GetEnrichedActivities, using the provided context for the request.
+	GetEnrichedActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error)
+	// GetNextPageEnrichedActivities returns the enriched activities for the given FlatFeed at the "next" page
+	// of a previous *EnrichedFlatFeedResponse response, if any.
+	GetNextPageEnrichedActivities(resp *EnrichedFlatFeedResponse) (*EnrichedFlatFeedResponse, error)
+	// GetNextPageEnrichedActivitiesContext is like GetNextPageEnrichedActivities, using the provided context for the request.
+	GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *EnrichedFlatFeedResponse) (*EnrichedFlatFeedResponse, error)
+	// GetEnrichedActivitiesWithRanking returns the enriched activities (filtered) for the given FlatFeed,
+	// using the provided ranking method.
+	GetEnrichedActivitiesWithRanking(ranking string, opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error)
+	// GetEnrichedActivitiesWithRankingContext is like GetEnrichedActivitiesWithRanking, using the provided context for the request.
+	GetEnrichedActivitiesWithRankingContext(ctx context.Context, ranking string, opts ...GetActivitiesOption) (*EnrichedFlatFeedResponse, error)
+	// GetActivitiesFromCursor returns the activities of the FlatFeed at the page
+	// referenced by the given cursor, created by Client.NewCursor.
+	GetActivitiesFromCursor(cursor string) (*FlatFeedResponse, error)
+	// GetActivitiesFromCursorContext is like GetActivitiesFromCursor, using the provided context for the request.
+	GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*FlatFeedResponse, error)
+	// GetEnrichedActivitiesFromCursor returns the enriched activities of the FlatFeed at
+	// the page referenced by the given cursor, created by Client.NewCursor.
+	GetEnrichedActivitiesFromCursor(cursor string) (*EnrichedFlatFeedResponse, error)
+	// GetEnrichedActivitiesFromCursorContext is like GetEnrichedActivitiesFromCursor, using the provided context for the request.
+	GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*EnrichedFlatFeedResponse, error)
+}
+
+// AggregatedFeedInterface is the interface implemented by AggregatedFeed.
+type AggregatedFeedInterface interface {
+	Feed
+	// GetActivities returns the activities for the given AggregatedFeed, filtering
+	// results with the provided GetActivitiesOption parameters.
+	GetActivities(opts ...GetActivitiesOption) (*AggregatedFeedResponse, error)
+	// GetActivitiesContext is like GetActivities, using the provided context for the request.
+	GetActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*AggregatedFeedResponse, error)
+	// GetNextPageActivities returns the activities for the given AggregatedFeed at the "next" page
+	// of a previous *AggregatedFeedResponse response, if any.
+	GetNextPageActivities(resp *AggregatedFeedResponse) (*AggregatedFeedResponse, error)
+	// GetNextPageActivitiesContext is like GetNextPageActivities, using the provided context for the request.
+	GetNextPageActivitiesContext(ctx context.Context, resp *AggregatedFeedResponse) (*AggregatedFeedResponse, error)
+	// GetEnrichedActivities returns the enriched activities for the given AggregatedFeed, filtering
+	// results with the provided GetActivitiesOption parameters.
+	GetEnrichedActivities(opts ...GetActivitiesOption) (*EnrichedAggregatedFeedResponse, error)
+	// GetEnrichedActivitiesContext is like GetEnrichedActivities, using the provided context for the request.
+	GetEnrichedActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*EnrichedAggregatedFeedResponse, error)
+	// GetNextPageEnrichedActivities returns the enriched activities for the given AggregatedFeed at the "next" page
+	// of a previous *EnrichedAggregatedFeedResponse response, if any.
+	GetNextPageEnrichedActivities(resp *EnrichedAggregatedFeedResponse) (*EnrichedAggregatedFeedResponse, error)
+	// GetNextPageEnrichedActivitiesContext is like GetNextPageEnrichedActivities, using the provided context for the request.
+	GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *EnrichedAggregatedFeedResponse) (*EnrichedAggregatedFeedResponse, error)
+	// GetActivitiesFromCursor returns the activities of the AggregatedFeed at the page
+	// referenced by the given cursor, created by Client.NewCursor.
+	GetActivitiesFromCursor(cursor string) (*AggregatedFeedResponse, error)
+	// GetActivitiesFromCursorContext is like GetActivitiesFromCursor, using the provided context for the request.
+	GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*AggregatedFeedResponse, error)
+	// GetEnrichedActivitiesFromCursor returns the enriched activities of the AggregatedFeed at
+	// the page referenced by the given cursor, created by Client.NewCursor.
+	GetEnrichedActivitiesFromCursor(cursor string) (*EnrichedAggregatedFeedResponse, error)
+	// GetEnrichedActivitiesFromCursorContext is like GetEnrichedActivitiesFromCursor, using the provided context for the request.
+	GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*EnrichedAggregatedFeedResponse, error)
+}
+
+// NotificationFeedInterface is the interface implemented by NotificationFeed.
+type NotificationFeedInterface interface {
+	Feed
+	// GetActivities returns the activities for the given NotificationFeed, filtering
+	// results with the provided GetActivitiesOption parameters.
+	GetActivities(opts ...GetActivitiesOption) (*NotificationFeedResponse, error)
+	// GetActivitiesContext is like GetActivities, using the provided context for the request.
+	GetActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*NotificationFeedResponse, error)
+	// GetNextPageActivities returns the activities for the given NotificationFeed at the "next" page
+	// of a previous *NotificationFeedResponse response, if any.
+	GetNextPageActivities(resp *NotificationFeedResponse) (*NotificationFeedResponse, error)
+	// GetNextPageActivitiesContext is like GetNextPageActivities, using the provided context for the request.
+	GetNextPageActivitiesContext(ctx context.Context, resp *NotificationFeedResponse) (*NotificationFeedResponse, error)
+	// GetEnrichedActivities returns the enriched activities for the given NotificationFeed, filtering
+	// results with the provided GetActivitiesOption parameters.
+	GetEnrichedActivities(opts ...GetActivitiesOption) (*EnrichedNotificationFeedResponse, error)
+	// GetEnrichedActivitiesContext is like GetEnrichedActivities, using the provided context for the request.
+	GetEnrichedActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*EnrichedNotificationFeedResponse, error)
+	// GetNextPageEnrichedActivities returns the enriched activities for the given NotificationFeed at the "next" page
+	// of a previous *EnrichedNotificationFeedResponse response, if any.
+	GetNextPageEnrichedActivities(resp *EnrichedNotificationFeedResponse) (*EnrichedNotificationFeedResponse, error)
+	// GetNextPageEnrichedActivitiesContext is like GetNextPageEnrichedActivities, using the provided context for the request.
+	GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *EnrichedNotificationFeedResponse) (*EnrichedNotificationFeedResponse, error)
+	// GetActivitiesFromCursor returns the activities of the NotificationFeed at the page
+	// referenced by the given cursor, created by Client.NewCursor.
+	GetActivitiesFromCursor(cursor string) (*NotificationFeedResponse, error)
+	// GetActivitiesFromCursorContext is like GetActivitiesFromCursor, using the provided context for the request.
+	GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*NotificationFeedResponse, error)
+	// GetEnrichedActivitiesFromCursor returns the enriched activities of the NotificationFeed at
+	// the page referenced by the given cursor, created by Client.NewCursor.
+	GetEnrichedActivitiesFromCursor(cursor string) (*EnrichedNotificationFeedResponse, error)
+	// GetEnrichedActivitiesFromCursorContext is like GetEnrichedActivitiesFromCursor, using the provided context for the request.
+	GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*EnrichedNotificationFeedResponse, error)
+}
+
+// AnalyticsClientInterface is the interface implemented by AnalyticsClient.
+type AnalyticsClientInterface interface {
+	// TrackEngagement is used to send and track analytics EngagementEvents.
+	TrackEngagement(events ...EngagementEvent) error
+	// TrackEngagementContext is like TrackEngagement, using the provided context for the request.
+	TrackEngagementContext(ctx context.Context, events ...EngagementEvent) error
+	// TrackImpression is used to send and track analytics ImpressionEvents.
+	TrackImpression(eventsData ImpressionEventsData) error
+	// TrackImpressionContext is like TrackImpression, using the provided context for the request.
+	TrackImpressionContext(ctx context.Context, eventsData ImpressionEventsData) error
+	// RedirectAndTrack is used to send and track analytics ImpressionEvents. It tracks
+	// the events data (either EngagementEvent or ImpressionEvent) and redirects to the provided
+	// URL string.
+	RedirectAndTrack(url string, events ...map[string]interface{}) (string, error)
 }
+
+// CollectionsClientInterface is the interface implemented by CollectionsClient.
+type CollectionsClientInterface interface {
+	// Upsert creates new or updates existing objects for the given collection's name.
+	Upsert(collection string, objects ...CollectionObject) error
+	// UpsertContext is like Upsert, using the provided context for the request.
+	UpsertContext(ctx context.Context, collection string, objects ...CollectionObject) error
+	// Select returns a list of CollectionObjects for the given collection name
+	// having the given IDs.
+	Select(collection string, ids ...string) ([]GetCollectionResponseObject, error)
+	// SelectContext is like Select, using the provided context for the request.
+	SelectContext(ctx context.Context, collection string, ids ...string) ([]GetCollectionResponseObject, error)
+	// SelectWithMissing is like Select, also reporting the IDs no object was found for.
+	SelectWithMissing(collection string, ids ...string) (*SelectCollectionResponse, error)
+	// SelectWithMissingContext is like SelectWithMissing, using the provided context for the request.
+	SelectWithMissingContext(ctx context.Context, collection string, ids ...string) (*SelectCollectionResponse, error)
+	// DeleteMany removes from a collection the objects having the given IDs.
+	DeleteMany(collection string, ids ...string) error
+	// DeleteManyContext is like DeleteMany, using the provided context for the request.
+	DeleteManyContext(ctx context.Context, collection string, ids ...string) error
+	// Add adds a single object to a collection.
+	Add(collection string, object CollectionObject, opts ...AddObjectOption) (*CollectionObject, error)
+	// AddContext is like Add, using the provided context for the request.
+	AddContext(ctx context.Context, collection string, object CollectionObject, opts ...AddObjectOption) (*CollectionObject, error)
+	// Get retrives a collection object having the given ID.
+	Get(collection string, id string) (*CollectionObject, error)
+	// GetContext is like Get, using the provided context for the request.
+	GetContext(ctx context.Context, collection string, id string) (*CollectionObject, error)
+	// Update updates the given collection object's data.
+	Update(collection string, id string, data map[string]interface{}) (*CollectionObject, error)
+	// UpdateContext is like Update, using the provided context for the request.
+	UpdateContext(ctx context.Context, collection string, id string, data map[string]interface{}) (*CollectionObject, error)
+	// Delete removes from a collection the object having the given ID.
+	Delete(collection string, id string) error
+	// DeleteContext is like Delete, using the provided context for the request.
+	DeleteContext(ctx context.Context, collection string, id string) error
+	// CreateReference returns a new reference string in the form SO:<collection>:<id>.
+	CreateReference(collection, id string) string
+}
+
+// UsersClientInterface is the interface implemented by UsersClient.
+type UsersClientInterface interface {
+	// Add adds a new user with the specified id and optional extra data.
+	Add(user User, getOrCreate bool) (*User, error)
+	// AddContext is like Add, using the provided context for the request.
+	AddContext(ctx context.Context, user User, getOrCreate bool) (*User, error)
+	// Update updates the user's data.
+	Update(id string, data map[string]interface{}) (*User, error)
+	// UpdateContext is like Update, using the provided context for the request.
+	UpdateContext(ctx context.Context, id string, data map[string]interface{}) (*User, error)
+	// Get retrieves a user having the given ID.
+	Get(id string) (*User, error)
+	// GetContext is like Get, using the provided context for the request.
+	GetContext(ctx context.Context, id string) (*User, error)
+	// Delete deletes a user having the given ID.
+	Delete(id string) error
+	// DeleteContext is like Delete, using the provided context for the request.
+	DeleteContext(ctx context.Context, id string) error
+	// CreateReference returns a new reference string in the form SU:<id>.
+	CreateReference(id string) string
+}
+
+// ReactionsClientInterface is the interface implemented by ReactionsClient.
+type ReactionsClientInterface interface {
+	// Add adds a reaction.
+	Add(r AddReactionRequestObject) (*Reaction, error)
+	// AddContext is like Add, using the provided context for the request.
+	AddContext(ctx context.Context, r AddReactionRequestObject) (*Reaction, error)
+	// AddChild adds a child reaction to the provided parent.
+	AddChild(parentID string, r AddReactionRequestObject) (*Reaction, error)
+	// AddChildContext is like AddChild, using the provided context for the request.
+	AddChildContext(ctx context.Context, parentID string, r AddReactionRequestObject) (*Reaction, error)
+	// Update updates the reaction's data and/or target feeds.
+	Update(id string, data map[string]interface{}, targetFeeds []string) (*Reaction, error)
+	// UpdateContext is like Update, using the provided context for the request.
+	UpdateContext(ctx context.Context, id string, data map[string]interface{}, targetFeeds []string) (*Reaction, error)
+	// Get retrieves a reaction having the given id.
+	Get(id string) (*Reaction, error)
+	// GetContext is like Get, using the provided context for the request.
+	GetContext(ctx context.Context, id string) (*Reaction, error)
+	// Delete deletes a reaction having the given id.
+	Delete(id string) error
+	// DeleteContext is like Delete, using the provided context for the request.
+	DeleteContext(ctx context.Context, id string) error
+	// Filter lists reactions based on the provided criteria and with the specified pagination.
+	Filter(attr FilterReactionsAttribute, opts ...FilterReactionsOption) (*FilterReactionResponse, error)
+	// FilterContext is like Filter, using the provided context for the request.
+	FilterContext(ctx context.Context, attr FilterReactionsAttribute, opts ...FilterReactionsOption) (*FilterReactionResponse, error)
+	// GetNextPageFilteredReactions returns the reactions at the "next" page of a previous *FilterReactionResponse response, if any.
+	GetNextPageFilteredReactions(resp *FilterReactionResponse) (*FilterReactionResponse, error)
+	// GetNextPageFilteredReactionsContext is like GetNextPageFilteredReactions, using the provided context for the request.
+	GetNextPageFilteredReactionsContext(ctx context.Context, resp *FilterReactionResponse) (*FilterReactionResponse, error)
+	// FilterFromCursor returns the reactions at the page referenced by the given cursor, created by Client.NewCursor.
+	FilterFromCursor(cursor string) (*FilterReactionResponse, error)
+	// FilterFromCursorContext is like FilterFromCursor, using the provided context for the request.
+	FilterFromCursorContext(ctx context.Context, cursor string) (*FilterReactionResponse, error)
+}
+
+// PersonalizationClientInterface is the interface implemented by
+// PersonalizationClient.
+type PersonalizationClientInterface interface {
+	// Get obtains a PersonalizationResponse for the given resource and params.
+	Get(resource string, params map[string]interface{}) (*PersonalizationResponse, error)
+	// GetContext is like Get, using the provided context for the request.
+	GetContext(ctx context.Context, resource string, params map[string]interface{}) (*PersonalizationResponse, error)
+	// Post sends data to the given resource, adding the given params to the request.
+	Post(resource string, params map[string]interface{}, data map[string]interface{}) error
+	// PostContext is like Post, using the provided context for the request.
+	PostContext(ctx context.Context, resource string, params map[string]interface{}, data map[string]interface{}) error
+	// Delete removes data from the given resource, adding the given params to the request.
+	Delete(resource string, params map[string]interface{}) error
+	// DeleteContext is like Delete, using the provided context for the request.
+	DeleteContext(ctx context.Context, resource string, params map[string]interface{}) error
+}
+
+var (
+	_ FlatFeedInterface              = (*FlatFeed)(nil)
+	_ AggregatedFeedInterface        = (*AggregatedFeed)(nil)
+	_ NotificationFeedInterface      = (*NotificationFeed)(nil)
+	_ AnalyticsClientInterface       = (*AnalyticsClient)(nil)
+	_ CollectionsClientInterface     = (*CollectionsClient)(nil)
+	_ UsersClientInterface           = (*UsersClient)(nil)
+	_ ReactionsClientInterface       = (*ReactionsClient)(nil)
+	_ PersonalizationClientInterface = (*PersonalizationClient)(nil)
+)
@@ -0,0 +1,466 @@
+package stream
+
+import (
+	"context"
+	"strconv"
+)
+
+// defaultFollowPageSize is the number of followers or followings requested
+// per page by follow iterators when no limit is given.
+const defaultFollowPageSize = 25
+
+// maxFollowPageSize is the maximum number of followers or followings returned
+// per page by the API, larger limits being lowered to it.
+const maxFollowPageSize = 500
+
+// pager implements the pagination logic shared by all the iterators, which
+// only need to provide a function loading the next page.
+type pager struct {
+	ctx context.Context
+	// fetch loads the next page, returning its number of items and whether
+	// it's the last one.
+	fetch func(ctx context.Context) (n int, last bool, err error)
+	max   int
+	count int
+	pos   int
+	size  int
+	last  bool
+	err   error
+}
+
+func newPager(ctx context.Context) pager {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return pager{ctx: ctx, pos: -1}
+}
+
+// SetMaxItems caps the number of items returned by the iterator, so that
+// it stops after returning n items even if more are available. Values lower
+// than or equal to zero mean no cap. It must be called before the first call
+// to Next.
+func (p *pager) SetMaxItems(n int) {
+	p.max = n
+}
+
+// Next advances the iterator to the next item, fetching the next page if
+// needed, and tells whether an item is available. It returns false when no
+// items are left, when the maximum number of items was returned, or when an
+// error occurred, which is then returned by Err.
+func (p *pager) Next() bool {
+	if p.err != nil || (p.max > 0 && p.count >= p.max) {
+		return false
+	}
+	for p.pos+1 >= p.size {
+		if p.last {
+			return false
+		}
+		if err := p.ctx.Err(); err != nil {
+			p.err = err
+			return false
+		}
+		size, last, err := p.fetch(p.ctx)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.size, p.last, p.pos = size, last || size == 0, -1
+	}
+	p.pos++
+	p.count++
+	return true
+}
+
+// Err returns the error which stopped the iteration, if any. Reaching the
+// end of the results isn't an error.
+func (p *pager) Err() error {
+	return p.err
+}
+
+// FlatFeedIterator iterates over the activities of a flat feed, fetching
+// pages as needed.
+//
+//	it := stream.NewFlatFeedIterator(ctx, feed, stream.WithActivitiesLimit(100))
+//	for it.Next() {
+//		activity := it.Value()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type FlatFeedIterator struct {
+	pager
+	page *FlatFeedResponse
+}
+
+// NewFlatFeedIterator returns a new iterator over the activities of the given
+// flat feed, read with the given options and context.
+func NewFlatFeedIterator(ctx context.Context, feed FlatFeedInterface, opts ...GetActivitiesOption) *FlatFeedIterator {
+	it := &FlatFeedIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *FlatFeedResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = feed.GetActivitiesContext(ctx, opts...)
+		} else {
+			page, err = feed.GetNextPageActivitiesContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current activity.
+func (it *FlatFeedIterator) Value() Activity {
+	return it.page.Results[it.pos]
+}
+
+// EnrichedFlatFeedIterator iterates over the enriched activities of a flat
+// feed, fetching pages as needed.
+type EnrichedFlatFeedIterator struct {
+	pager
+	page *EnrichedFlatFeedResponse
+}
+
+// NewEnrichedFlatFeedIterator returns a new iterator over the enriched
+// activities of the given flat feed, read with the given options and context.
+func NewEnrichedFlatFeedIterator(ctx context.Context, feed FlatFeedInterface, opts ...GetActivitiesOption) *EnrichedFlatFeedIterator {
+	it := &EnrichedFlatFeedIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *EnrichedFlatFeedResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = feed.GetEnrichedActivitiesContext(ctx, opts...)
+		} else {
+			page, err = feed.GetNextPageEnrichedActivitiesContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current enriched activity.
+func (it *EnrichedFlatFeedIterator) Value() EnrichedActivity {
+	return it.page.Results[it.pos]
+}
+
+// AggregatedFeedIterator iterates over the activity groups of an aggregated
+// feed, fetching pages as needed.
+type AggregatedFeedIterator struct {
+	pager
+	page *AggregatedFeedResponse
+}
+
+// NewAggregatedFeedIterator returns a new iterator over the activity groups of
+// the given aggregated feed, read with the given options and context.
+func NewAggregatedFeedIterator(ctx context.Context, feed AggregatedFeedInterface, opts ...GetActivitiesOption) *AggregatedFeedIterator {
+	it := &AggregatedFeedIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *AggregatedFeedResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = feed.GetActivitiesContext(ctx, opts...)
+		} else {
+			page, err = feed.GetNextPageActivitiesContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current activity group.
+func (it *AggregatedFeedIterator) Value() ActivityGroup {
+	return it.page.Results[it.pos]
+}
+
+// EnrichedAggregatedFeedIterator iterates over the enriched activity groups
+// of an aggregated feed, fetching pages as needed.
+type EnrichedAggregatedFeedIterator struct {
+	pager
+	page *EnrichedAggregatedFeedResponse
+}
+
+// NewEnrichedAggregatedFeedIterator returns a new iterator over the enriched
+// activity groups of the given aggregated feed, read with the given options
+// and context.
+func NewEnrichedAggregatedFeedIterator(ctx context.Context, feed AggregatedFeedInterface, opts ...GetActivitiesOption) *EnrichedAggregatedFeedIterator {
+	it := &EnrichedAggregatedFeedIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *EnrichedAggregatedFeedResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = feed.GetEnrichedActivitiesContext(ctx, opts...)
+		} else {
+			page, err = feed.GetNextPageEnrichedActivitiesContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current enriched activity group.
+func (it *EnrichedAggregatedFeedIterator) Value() EnrichedActivityGroup {
+	return it.page.Results[it.pos]
+}
+
+// NotificationFeedIterator iterates over the notification groups of a
+// notification feed, fetching pages as needed.
+type NotificationFeedIterator struct {
+	pager
+	page *NotificationFeedResponse
+}
+
+// NewNotificationFeedIterator returns a new iterator over the notification
+// groups of the given notification feed, read with the given options and
+// context.
+func NewNotificationFeedIterator(ctx context.Context, feed NotificationFeedInterface, opts ...GetActivitiesOption) *NotificationFeedIterator {
+	it := &NotificationFeedIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *NotificationFeedResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = feed.GetActivitiesContext(ctx, opts...)
+		} else {
+			page, err = feed.GetNextPageActivitiesContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current notification group.
+func (it *NotificationFeedIterator) Value() NotificationFeedResult {
+	return it.page.Results[it.pos]
+}
+
+// Unseen returns the number of unseen notification groups, as of the last
+// fetched page.
+func (it *NotificationFeedIterator) Unseen() int {
+	if it.page == nil {
+		return 0
+	}
+	return it.page.Unseen
+}
+
+// Unread returns the number of unread notification groups, as of the last
+// fetched page.
+func (it *NotificationFeedIterator) Unread() int {
+	if it.page == nil {
+		return 0
+	}
+	return it.page.Unread
+}
+
+// EnrichedNotificationFeedIterator iterates over the enriched notification
+// groups of a notification feed, fetching pages as needed.
+type EnrichedNotificationFeedIterator struct {
+	pager
+	page *EnrichedNotificationFeedResponse
+}
+
+// NewEnrichedNotificationFeedIterator returns a new iterator over the enriched
+// notification groups of the given notification feed, read with the given
+// options and context.
+func NewEnrichedNotificationFeedIterator(ctx context.Context, feed NotificationFeedInterface, opts ...GetActivitiesOption) *EnrichedNotificationFeedIterator {
+	it := &EnrichedNotificationFeedIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *EnrichedNotificationFeedResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = feed.GetEnrichedActivitiesContext(ctx, opts...)
+		} else {
+			page, err = feed.GetNextPageEnrichedActivitiesContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current enriched notification group.
+func (it *EnrichedNotificationFeedIterator) Value() EnrichedNotificationFeedResult {
+	return it.page.Results[it.pos]
+}
+
+// ReactionsIterator iterates over the reactions returned by a reactions
+// filter, fetching pages as needed.
+type ReactionsIterator struct {
+	pager
+	page *FilterReactionResponse
+}
+
+// NewReactionsIterator returns a new iterator over the reactions matching the
+// given attribute, read with the given options and context.
+func NewReactionsIterator(ctx context.Context, reactions ReactionsClientInterface, attr FilterReactionsAttribute, opts ...FilterReactionsOption) *ReactionsIterator {
+	it := &ReactionsIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		var (
+			page *FilterReactionResponse
+			err  error
+		)
+		if it.page == nil {
+			page, err = reactions.FilterContext(ctx, attr, opts...)
+		} else {
+			page, err = reactions.GetNextPageFilteredReactionsContext(ctx, it.page)
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		it.page = page
+		return len(page.Results), page.Next == "", nil
+	}
+	return it
+}
+
+// Value returns the current reaction.
+func (it *ReactionsIterator) Value() Reaction {
+	return it.page.Results[it.pos]
+}
+
+// Activity returns the activity the reactions belong to, as of the last
+// fetched page, when filtering by activity ID using WithActivityData.
+func (it *ReactionsIterator) Activity() map[string]interface{} {
+	if it.page == nil {
+		return nil
+	}
+	return it.page.Activity
+}
+
+// FollowIterator iterates over the followers or the followings of a feed,
+// fetching pages as needed. Since the follow endpoints are paginated by
+// offset, a follow created or removed during the iteration may cause items to
+// be skipped or returned twice.
+type FollowIterator struct {
+	pager
+	results []Follower
+}
+
+// NewFollowersIterator returns a new iterator over the followers of the given
+// feed, read with the given options and context. Pages are fetched starting
+// from the offset given with WithFollowersOffset, if any, and have the size
+// given with WithFollowersLimit, if any.
+func NewFollowersIterator(ctx context.Context, feed FlatFeedInterface, opts ...FollowersOption) *FollowIterator {
+	params := make([]requestOption, len(opts))
+	for i, opt := range opts {
+		params[i] = opt.requestOption
+	}
+	return newFollowIterator(ctx, params, func(ctx context.Context, limit, offset requestOption, params []requestOption) ([]Follower, error) {
+		opts := make([]FollowersOption, 0, len(params)+2)
+		for _, p := range params {
+			opts = append(opts, FollowersOption{p})
+		}
+		opts = append(opts, FollowersOption{limit}, FollowersOption{offset})
+		resp, err := feed.GetFollowersContext(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Results, nil
+	})
+}
+
+// NewFollowingIterator returns a new iterator over the feeds followed by the
+// given feed, read with the given options and context. Pages are fetched
+// starting from the offset given with WithFollowingOffset, if any, and have
+// the size given with WithFollowingLimit, if any.
+func NewFollowingIterator(ctx context.Context, feed Feed, opts ...FollowingOption) *FollowIterator {
+	params := make([]requestOption, len(opts))
+	for i, opt := range opts {
+		params[i] = opt.requestOption
+	}
+	return newFollowIterator(ctx, params, func(ctx context.Context, limit, offset requestOption, params []requestOption) ([]Follower, error) {
+		opts := make([]FollowingOption, 0, len(params)+2)
+		for _, p := range params {
+			opts = append(opts, FollowingOption{p})
+		}
+		opts = append(opts, FollowingOption{limit}, FollowingOption{offset})
+		resp, err := feed.GetFollowingContext(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return resp.Results, nil
+	})
+}
+
+type followPageFunc func(ctx context.Context, limit, offset requestOption, params []requestOption) ([]Follower, error)
+
+// newFollowIterator returns a FollowIterator requesting pages with the given
+// function, moving the offset forward after each page. The limit and offset
+// are removed from the given parameters, and used as the page size, capped to
+// the maximum page size of the API, and the initial offset.
+func newFollowIterator(ctx context.Context, params []requestOption, page followPageFunc) *FollowIterator {
+	limit, offset := defaultFollowPageSize, 0
+	var rest []requestOption
+	for _, p := range params {
+		if p == nil || !p.valid() {
+			continue
+		}
+		key, value := p.values()
+		switch key {
+		case "limit":
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				limit = n
+			}
+			if limit > maxFollowPageSize {
+				limit = maxFollowPageSize
+			}
+		case "offset":
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				offset = n
+			}
+		default:
+			rest = append(rest, p)
+		}
+	}
+
+	it := &FollowIterator{pager: newPager(ctx)}
+	it.fetch = func(ctx context.Context) (int, bool, error) {
+		results, err := page(ctx, withLimit(limit), withOffset(offset), rest)
+		if err != nil {
+			return 0, false, err
+		}
+		it.results = results
+		offset += len(results)
+		return len(results), len(results) < limit, nil
+	}
+	return it
+}
+
+// Value returns the current follow relationship.
+func (it *FollowIterator) Value() Follower {
+	return it.results[it.pos]
+}
@@ -0,0 +1,230 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeClient(t *testing.T) *stream.Client {
+	client, err := streamtest.New().NewClient()
+	require.NoError(t, err)
+	return client
+}
+
+func addActivities(t *testing.T, feed stream.Feed, n int) []string {
+	ids := make([]string, n)
+	for i := 0; i < n; i++ {
+		resp, err := feed.AddActivity(stream.Activity{Actor: "bob", Verb: fmt.Sprintf("verb%d", i%2), Object: fmt.Sprintf("object:%d", i)})
+		require.NoError(t, err)
+		// feeds are read newest first
+		ids[n-i-1] = resp.ID
+	}
+	return ids
+}
+
+func TestFlatFeedIterator(t *testing.T) {
+	client := newFakeClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	ids := addActivities(t, feed, 7)
+
+	var got []string
+	it := stream.NewFlatFeedIterator(context.Background(), feed, stream.WithActivitiesLimit(3))
+	for it.Next() {
+		got = append(got, it.Value().ID)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, ids, got)
+	assert.False(t, it.Next())
+
+	got = nil
+	enriched := stream.NewEnrichedFlatFeedIterator(context.Background(), feed, stream.WithActivitiesLimit(2))
+	enriched.SetMaxItems(5)
+	for enriched.Next() {
+		got = append(got, enriched.Value().ID)
+	}
+	require.NoError(t, enriched.Err())
+	assert.Equal(t, ids[:5], got)
+
+	empty, err := client.FlatFeed("user", "empty")
+	require.NoError(t, err)
+	it = stream.NewFlatFeedIterator(context.Background(), empty)
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+}
+
+func TestAggregatedFeedIterators(t *testing.T) {
+	client := newFakeClient(t)
+	aggregated, err := client.AggregatedFeed("aggregated", "bob")
+	require.NoError(t, err)
+	addActivities(t, aggregated, 4)
+
+	var groups int
+	it := stream.NewAggregatedFeedIterator(context.Background(), aggregated, stream.WithActivitiesLimit(1))
+	for it.Next() {
+		groups++
+		assert.Len(t, it.Value().Activities, 2)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 2, groups)
+
+	groups = 0
+	enriched := stream.NewEnrichedAggregatedFeedIterator(context.Background(), aggregated, stream.WithActivitiesLimit(1))
+	for enriched.Next() {
+		groups++
+	}
+	require.NoError(t, enriched.Err())
+	assert.Equal(t, 2, groups)
+
+	notification, err := client.NotificationFeed("notification", "bob")
+	require.NoError(t, err)
+	addActivities(t, notification, 4)
+
+	groups = 0
+	nit := stream.NewNotificationFeedIterator(context.Background(), notification, stream.WithActivitiesLimit(1))
+	for nit.Next() {
+		groups++
+		assert.False(t, nit.Value().IsSeen)
+	}
+	require.NoError(t, nit.Err())
+	assert.Equal(t, 2, groups)
+	assert.Equal(t, 2, nit.Unseen())
+	assert.Equal(t, 2, nit.Unread())
+
+	groups = 0
+	enrichedNotifications := stream.NewEnrichedNotificationFeedIterator(context.Background(), notification)
+	for enrichedNotifications.Next() {
+		groups++
+	}
+	require.NoError(t, enrichedNotifications.Err())
+	assert.Equal(t, 2, groups)
+}
+
+func TestFollowIterators(t *testing.T) {
+	client := newFakeClient(t)
+	bob, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	var followers []string
+	for i := 0; i < 7; i++ {
+		timeline, err := client.FlatFeed("timeline", fmt.Sprintf("user%d", i))
+		require.NoError(t, err)
+		require.NoError(t, timeline.Follow(bob))
+		followers = append(followers, timeline.ID())
+	}
+
+	var got []string
+	it := stream.NewFollowersIterator(context.Background(), bob, stream.WithFollowersLimit(3))
+	for it.Next() {
+		got = append(got, it.Value().FeedID)
+		assert.Equal(t, "user:bob", it.Value().TargetID)
+	}
+	require.NoError(t, it.Err())
+	assert.ElementsMatch(t, followers, got)
+
+	got = nil
+	it = stream.NewFollowersIterator(context.Background(), bob, stream.WithFollowersLimit(2), stream.WithFollowersOffset(5))
+	for it.Next() {
+		got = append(got, it.Value().FeedID)
+	}
+	require.NoError(t, it.Err())
+	assert.Len(t, got, 2)
+
+	timeline, err := client.FlatFeed("timeline", "user0")
+	require.NoError(t, err)
+	for _, id := range []string{"alice", "carol", "dave"} {
+		feed, err := client.FlatFeed("user", id)
+		require.NoError(t, err)
+		require.NoError(t, timeline.Follow(feed))
+	}
+	got = nil
+	it = stream.NewFollowingIterator(context.Background(), timeline, stream.WithFollowingLimit(1), stream.WithFollowingFilter("user:alice", "user:bob", "user:dave"))
+	for it.Next() {
+		got = append(got, it.Value().TargetID)
+	}
+	require.NoError(t, it.Err())
+	assert.ElementsMatch(t, []string{"user:alice", "user:bob", "user:dave"}, got)
+}
+
+func TestFollowIterators_largeLimit(t *testing.T) {
+	client := newFakeClient(t)
+	bob, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	for i := 0; i < 600; i++ {
+		timeline, err := client.FlatFeed("timeline", fmt.Sprintf("user%d", i))
+		require.NoError(t, err)
+		require.NoError(t, timeline.Follow(bob))
+	}
+
+	count := 0
+	it := stream.NewFollowersIterator(context.Background(), bob, stream.WithFollowersLimit(1000))
+	for it.Next() {
+		count++
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, 600, count)
+}
+
+func TestReactionsIterator(t *testing.T) {
+	client := newFakeClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	activity, err := feed.AddActivity(stream.Activity{Actor: "bob", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+	var ids []string
+	for i := 0; i < 7; i++ {
+		reaction, err := client.Reactions().Add(stream.AddReactionRequestObject{Kind: "like", ActivityID: activity.ID, UserID: fmt.Sprintf("user%d", i)})
+		require.NoError(t, err)
+		ids = append([]string{reaction.ID}, ids...)
+	}
+
+	var got []string
+	it := stream.NewReactionsIterator(context.Background(), client.Reactions(), stream.ByActivityID(activity.ID).ByKind("like"), stream.WithLimit(2), stream.WithActivityData())
+	for it.Next() {
+		got = append(got, it.Value().ID)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, ids, got)
+	assert.Equal(t, activity.ID, it.Activity()["id"])
+}
+
+func TestIteratorErrors(t *testing.T) {
+	feed := streamtest.NewMockFlatFeed("user", "bob")
+	feed.GetActivitiesContextFunc = func(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+		return &stream.FlatFeedResponse{Results: []stream.Activity{{ID: "1"}, {ID: "2"}}}, nil
+	}
+	feed.GetNextPageActivitiesContextFunc = func(ctx context.Context, resp *stream.FlatFeedResponse) (*stream.FlatFeedResponse, error) {
+		return nil, errors.New("boom")
+	}
+
+	it := stream.NewFlatFeedIterator(context.Background(), feed)
+	assert.True(t, it.Next())
+	assert.True(t, it.Next())
+	// an empty next link ends the iteration
+	assert.False(t, it.Next())
+	assert.NoError(t, it.Err())
+
+	feed.GetActivitiesContextFunc = func(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+		resp := &stream.FlatFeedResponse{Results: []stream.Activity{{ID: "1"}}}
+		resp.Next = "/api/v1.0/feed/user/bob/?id_lt=1"
+		return resp, nil
+	}
+	it = stream.NewFlatFeedIterator(context.Background(), feed)
+	assert.True(t, it.Next())
+	assert.False(t, it.Next())
+	assert.EqualError(t, it.Err(), "boom")
+	assert.False(t, it.Next())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it = stream.NewFlatFeedIterator(ctx, feed)
+	assert.False(t, it.Next())
+	assert.Equal(t, context.Canceled, it.Err())
+	assert.Len(t, feed.CallsTo("GetActivitiesContext"), 2)
+}
@@ -0,0 +1,192 @@
+package stream
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is the maximum rate at which requests are sent to an API resource.
+type Limit struct {
+	// Rate is the average number of requests per second. Values lower than or
+	// equal to zero mean no limit.
+	Rate float64
+	// Burst is the maximum number of requests which can be sent at once.
+	// Defaults to 1.
+	Burst int
+}
+
+// RateLimiterConfig configures the client-side rate limiter of a Client.
+type RateLimiterConfig struct {
+	// Limits are the limits for specific resources.
+	Limits map[Resource]Limit
+	// Default is the limit for the resources missing from Limits. The zero value
+	// means no limit.
+	Default Limit
+	// Adaptive makes the limiter follow the rate limit status reported by the
+	// API: once the requests left for an endpoint are exhausted, the following
+	// ones wait for the rate limit window to reset.
+	Adaptive bool
+}
+
+// WithRateLimiter sets a client-side rate limiter for a given Client. Before
+// being sent, requests wait for the limiter to allow them, or for the context to
+// be done. The limiter is shared by all the specialized clients obtained from
+// the Client.
+func WithRateLimiter(config RateLimiterConfig) ClientOption {
+	return func(c *Client) {
+		c.limiter = newRateLimiter(config)
+	}
+}
+
+// rateLimiter is a token bucket rate limiter keeping a bucket per resource. In
+// adaptive mode it also keeps track of the requests left for each endpoint, as
+// reported by the API.
+type rateLimiter struct {
+	mu      sync.Mutex
+	config  RateLimiterConfig
+	buckets map[Resource]*tokenBucket
+	quotas  map[string]RateLimit
+	now     func() time.Time
+}
+
+func newRateLimiter(config RateLimiterConfig) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		buckets: make(map[Resource]*tokenBucket),
+		quotas:  make(map[string]RateLimit),
+		now:     time.Now,
+	}
+}
+
+// wait blocks until a request to the given endpoint is allowed, or until the
+// context is done. The returned reservation must be given back with cancel if
+// the request isn't sent after all.
+func (l *rateLimiter) wait(ctx context.Context, method string, res Resource) (reservation, error) {
+	if l == nil {
+		return reservation{}, nil
+	}
+	d, r := l.reserve(method, res)
+	if d <= 0 {
+		return r, nil
+	}
+	if err := sleepContext(ctx, d); err != nil {
+		l.cancel(r)
+		return reservation{}, err
+	}
+	return r, nil
+}
+
+// reservation is a request booked by reserve, which can be given back with
+// cancel if it isn't sent.
+type reservation struct {
+	res Resource
+	// key and reset identify the quota the request was counted against, if any.
+	key   string
+	reset time.Time
+}
+
+// reserve books a request to the given endpoint, returning how long the caller
+// must wait before sending it.
+func (l *rateLimiter) reserve(method string, res Resource) (time.Duration, reservation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	r := reservation{res: res}
+	var d time.Duration
+	if b := l.bucket(res, now); b != nil {
+		d = b.reserve(now)
+	}
+	key := rateLimitKey(method, res)
+	if q, ok := l.quotas[key]; ok && now.Before(q.Reset) {
+		if q.Remaining > 0 {
+			q.Remaining--
+			l.quotas[key] = q
+			r.key, r.reset = key, q.Reset
+		} else if w := q.Reset.Sub(now); w > d {
+			d = w
+		}
+	}
+	return d, r
+}
+
+// cancel gives back the token and the quota taken by a reservation which
+// wasn't used. The quota is only given back if it wasn't updated since.
+func (l *rateLimiter) cancel(r reservation) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.buckets[r.res]; ok && b != nil {
+		b.tokens = math.Min(b.tokens+1, b.burst)
+	}
+	if q, ok := l.quotas[r.key]; ok && r.key != "" && q.Reset.Equal(r.reset) {
+		q.Remaining++
+		l.quotas[r.key] = q
+	}
+}
+
+// update records the rate limit status reported by the API for an endpoint.
+func (l *rateLimiter) update(key string, rl RateLimit) {
+	if l == nil || !l.config.Adaptive {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.quotas[key] = rl
+}
+
+// bucket returns the bucket for the given resource, or nil if the resource
+// isn't limited.
+func (l *rateLimiter) bucket(res Resource, now time.Time) *tokenBucket {
+	if b, ok := l.buckets[res]; ok {
+		return b
+	}
+	limit, ok := l.config.Limits[res]
+	if !ok {
+		limit = l.config.Default
+	}
+	var b *tokenBucket
+	if limit.Rate > 0 {
+		b = newTokenBucket(limit, now)
+	}
+	l.buckets[res] = b
+	return b
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit Limit, now time.Time) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// reserve takes a token from the bucket, returning how long to wait before it
+// becomes available.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.tokens+elapsed.Seconds()*b.rate, b.burst)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRateLimiter(config RateLimiterConfig, now *time.Time) *rateLimiter {
+	l := newRateLimiter(config)
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+// reserveDelay books a request, returning how long it must wait.
+func reserveDelay(l *rateLimiter, method string, res Resource) time.Duration {
+	d, _ := l.reserve(method, res)
+	return d
+}
+
+func TestRateLimiter_tokenBucket(t *testing.T) {
+	now := time.Unix(1546300800, 0)
+	l := newTestRateLimiter(RateLimiterConfig{
+		Limits:  map[Resource]Limit{ResourceFeed: {Rate: 2, Burst: 2}},
+		Default: Limit{Rate: 1},
+	}, &now)
+
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodPost, ResourceFeed))
+	assert.Equal(t, 500*time.Millisecond, reserveDelay(l, http.MethodGet, ResourceFeed))
+	assert.Equal(t, time.Second, reserveDelay(l, http.MethodGet, ResourceFeed))
+
+	now = now.Add(time.Second)
+	d, r := l.reserve(http.MethodGet, ResourceFeed)
+	assert.Equal(t, 500*time.Millisecond, d)
+	l.cancel(r)
+	assert.Equal(t, 500*time.Millisecond, reserveDelay(l, http.MethodGet, ResourceFeed))
+
+	// other resources use the default limit
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceUsers))
+	assert.Equal(t, time.Second, reserveDelay(l, http.MethodGet, ResourceUsers))
+
+	// refills never exceed the burst
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+	assert.Equal(t, 500*time.Millisecond, reserveDelay(l, http.MethodGet, ResourceFeed))
+}
+
+func TestRateLimiter_unlimited(t *testing.T) {
+	now := time.Unix(1546300800, 0)
+	l := newTestRateLimiter(RateLimiterConfig{
+		Limits: map[Resource]Limit{ResourceFeed: {Rate: 1}},
+	}, &now)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceReactions))
+	}
+	_, r := l.reserve(http.MethodGet, ResourceReactions)
+	l.cancel(r)
+
+	var nilLimiter *rateLimiter
+	_, err := nilLimiter.wait(context.Background(), http.MethodGet, ResourceFeed)
+	assert.NoError(t, err)
+	nilLimiter.cancel(reservation{})
+	nilLimiter.update("GET feed", RateLimit{})
+}
+
+func TestRateLimiter_adaptive(t *testing.T) {
+	now := time.Unix(1546300800, 0)
+	reset := now.Add(10 * time.Second)
+	l := newTestRateLimiter(RateLimiterConfig{Adaptive: true}, &now)
+
+	l.update("GET feed", RateLimit{Limit: 10, Remaining: 2, Reset: reset})
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+	assert.Equal(t, 10*time.Second, reserveDelay(l, http.MethodGet, ResourceFeed))
+	// other endpoints have their own quota
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodPost, ResourceFeed))
+
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, 6*time.Second, reserveDelay(l, http.MethodGet, ResourceFeed))
+
+	// cancelled requests give back the quota they took
+	l.update("GET feed", RateLimit{Limit: 10, Remaining: 1, Reset: reset})
+	_, r := l.reserve(http.MethodGet, ResourceFeed)
+	assert.Equal(t, 0, l.quotas["GET feed"].Remaining)
+	l.cancel(r)
+	assert.Equal(t, 1, l.quotas["GET feed"].Remaining)
+	// unless the quota was updated in the meantime
+	_, r = l.reserve(http.MethodGet, ResourceFeed)
+	l.update("GET feed", RateLimit{Limit: 10, Remaining: 5, Reset: reset.Add(time.Second)})
+	l.cancel(r)
+	assert.Equal(t, 5, l.quotas["GET feed"].Remaining)
+
+	// the quota doesn't apply once the window is over
+	now = reset
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+
+	l = newTestRateLimiter(RateLimiterConfig{}, &now)
+	l.update("GET feed", RateLimit{Limit: 10, Remaining: 0, Reset: now.Add(time.Minute)})
+	assert.Equal(t, time.Duration(0), reserveDelay(l, http.MethodGet, ResourceFeed))
+}
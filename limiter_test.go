@@ -0,0 +1,64 @@
+package stream_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK},
+		{code: http.StatusOK},
+		{code: http.StatusOK},
+	}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRateLimiter(stream.RateLimiterConfig{
+			Limits: map[stream.Resource]stream.Limit{
+				stream.ResourceReactions: {Rate: 0.1},
+			},
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.Reactions().GetContext(ctx, "a")
+	require.NoError(t, err)
+	// the limiter is shared with new specialized clients
+	_, err = client.Reactions().GetContext(ctx, "b")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, requester.reqs, 1)
+
+	// other resources aren't limited
+	_, err = client.Users().Get("bob")
+	require.NoError(t, err)
+	assert.Len(t, requester.reqs, 2)
+}
+
+func TestRateLimiterAdaptive(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK, header: rateLimitHeader(10, 0, time.Now().Add(time.Minute))},
+		{code: http.StatusOK},
+	}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRateLimiter(stream.RateLimiterConfig{Adaptive: true}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Users().Get("bob")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.Users().GetContext(ctx, "bob")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, requester.reqs, 1)
+}
@@ -0,0 +1,318 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorClass is a coarse classification of the errors API calls fail with.
+type ErrorClass string
+
+// The error classes.
+const (
+	// ErrorClassNone is the class of successful calls.
+	ErrorClassNone ErrorClass = ""
+	// ErrorClassCanceled is the class of calls whose context was canceled or
+	// timed out.
+	ErrorClassCanceled ErrorClass = "canceled"
+	// ErrorClassCircuitOpen is the class of calls rejected by an open circuit
+	// breaker.
+	ErrorClassCircuitOpen ErrorClass = "circuit_open"
+	// ErrorClassTransport is the class of calls which didn't receive any
+	// response.
+	ErrorClassTransport ErrorClass = "transport"
+	// ErrorClassRateLimited is the class of calls rejected because of rate
+	// limits.
+	ErrorClassRateLimited ErrorClass = "rate_limited"
+	// ErrorClassClient is the class of calls rejected with a 4xx status code.
+	ErrorClassClient ErrorClass = "client_error"
+	// ErrorClassServer is the class of calls failed with a 5xx status code.
+	ErrorClassServer ErrorClass = "server_error"
+	// ErrorClassOther is the class of calls failed for any other reason, such
+	// as an invalid response.
+	ErrorClassOther ErrorClass = "other"
+)
+
+// CallMetrics are the measures of a completed API call.
+type CallMetrics struct {
+	// Operation is the logical operation performed by the call, e.g.
+	// "feed.add_activity" or "reactions.filter".
+	Operation string
+	// Resource is the API resource of the call.
+	Resource Resource
+	// Method is the HTTP method of the call.
+	Method string
+	// StatusCode is the HTTP status code of the last response received, zero
+	// if none was received.
+	StatusCode int
+	// Latency is the total duration of the call, including retries.
+	Latency time.Duration
+	// BytesSent is the size in bytes of the request body.
+	BytesSent int
+	// BytesReceived is the size in bytes of the response body.
+	BytesReceived int
+	// WireBytesSent is the size in bytes of the request body as sent, smaller
+	// than BytesSent when compressed.
+	WireBytesSent int
+	// WireBytesReceived is the size in bytes of the response body as
+	// received, smaller than BytesReceived when compressed.
+	WireBytesReceived int
+	// Attempts is the number of HTTP requests performed for the call.
+	Attempts int
+	// ErrorClass is the class of the error the call failed with, if any.
+	ErrorClass ErrorClass
+}
+
+// MetricsCollector receives the measures of every API call performed by a
+// Client. It must be safe for concurrent use.
+type MetricsCollector interface {
+	Observe(m CallMetrics)
+}
+
+// WithMetricsCollector sets the MetricsCollector for a given Client.
+func WithMetricsCollector(collector MetricsCollector) ClientOption {
+	return func(c *Client) {
+		c.metrics = collector
+	}
+}
+
+// observe reports the measures of a completed API call to the metrics
+// collector, if any.
+func (c *Client) observe(ctx context.Context, call *Call, start time.Time, err error) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.Observe(CallMetrics{
+		Operation:         call.Operation,
+		Resource:          call.Resource,
+		Method:            call.Method,
+		StatusCode:        call.StatusCode,
+		Latency:           time.Since(start),
+		BytesSent:         call.RequestSize,
+		BytesReceived:     call.ResponseSize,
+		WireBytesSent:     call.RequestWireSize,
+		WireBytesReceived: call.ResponseWireSize,
+		Attempts:          call.Attempts,
+		ErrorClass:        classifyError(ctx, call, err),
+	})
+}
+
+func classifyError(ctx context.Context, call *Call, err error) ErrorClass {
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case ctx.Err() != nil:
+		return ErrorClassCanceled
+	case isCircuitOpen(err):
+		return ErrorClassCircuitOpen
+	case call.StatusCode == 0:
+		return ErrorClassTransport
+	case call.StatusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case call.StatusCode/100 == 4:
+		return ErrorClassClient
+	case call.StatusCode/100 == 5:
+		return ErrorClassServer
+	}
+	return ErrorClassOther
+}
+
+// DefaultLatencyBuckets are the default upper bounds, in seconds, of the
+// latency histogram buckets of InMemoryMetrics.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// InMemoryMetrics is a MetricsCollector keeping the metrics in memory, which
+// can be exported in the Prometheus text format. It exposes:
+//
+//   - stream_requests_total, a counter of API calls by operation, method,
+//     status code and error class;
+//   - stream_request_duration_seconds, a histogram of the API calls latency by
+//     operation and method;
+//   - stream_request_bytes_total and stream_response_bytes_total, counters of
+//     the bytes sent and received by operation and method;
+//   - stream_request_wire_bytes_total and stream_response_wire_bytes_total,
+//     counters of the bytes sent and received after compression by operation
+//     and method.
+type InMemoryMetrics struct {
+	mu         sync.Mutex
+	buckets    []float64
+	requests   map[requestLabels]uint64
+	operations map[operationLabels]*operationStats
+}
+
+type requestLabels struct {
+	operation  string
+	method     string
+	statusCode int
+	errorClass ErrorClass
+}
+
+type operationLabels struct {
+	operation string
+	method    string
+}
+
+type operationStats struct {
+	counts        []uint64 // per bucket, plus +Inf
+	count         uint64
+	sum           float64
+	bytesSent     uint64
+	bytesReceived uint64
+	wireSent      uint64
+	wireReceived  uint64
+}
+
+// NewInMemoryMetrics returns a new InMemoryMetrics using the given latency
+// histogram buckets upper bounds, in seconds, or DefaultLatencyBuckets if none
+// are provided.
+func NewInMemoryMetrics(buckets ...float64) *InMemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &InMemoryMetrics{
+		buckets:    b,
+		requests:   make(map[requestLabels]uint64),
+		operations: make(map[operationLabels]*operationStats),
+	}
+}
+
+// Observe records the measures of an API call.
+func (m *InMemoryMetrics) Observe(cm CallMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[requestLabels{
+		operation:  cm.Operation,
+		method:     cm.Method,
+		statusCode: cm.StatusCode,
+		errorClass: cm.ErrorClass,
+	}]++
+
+	key := operationLabels{operation: cm.Operation, method: cm.Method}
+	stats, ok := m.operations[key]
+	if !ok {
+		stats = &operationStats{counts: make([]uint64, len(m.buckets)+1)}
+		m.operations[key] = stats
+	}
+	latency := cm.Latency.Seconds()
+	i := sort.SearchFloat64s(m.buckets, latency)
+	stats.counts[i]++
+	stats.count++
+	stats.sum += latency
+	stats.bytesSent += uint64(cm.BytesSent)
+	stats.bytesReceived += uint64(cm.BytesReceived)
+	stats.wireSent += uint64(cm.WireBytesSent)
+	stats.wireReceived += uint64(cm.WireBytesReceived)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format.
+func (m *InMemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+
+	requests := make([]requestLabels, 0, len(m.requests))
+	for k := range m.requests {
+		requests = append(requests, k)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		a, b := requests[i], requests[j]
+		if a.operation != b.operation {
+			return a.operation < b.operation
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		if a.statusCode != b.statusCode {
+			return a.statusCode < b.statusCode
+		}
+		return a.errorClass < b.errorClass
+	})
+	fmt.Fprintln(bw, "# HELP stream_requests_total Total number of Stream API calls.")
+	fmt.Fprintln(bw, "# TYPE stream_requests_total counter")
+	for _, k := range requests {
+		fmt.Fprintf(bw, "stream_requests_total{operation=%s,method=%s,status_code=\"%d\",error_class=%s} %d\n",
+			quoteLabel(k.operation), quoteLabel(k.method), k.statusCode, quoteLabel(string(k.errorClass)), m.requests[k])
+	}
+
+	operations := make([]operationLabels, 0, len(m.operations))
+	for k := range m.operations {
+		operations = append(operations, k)
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		a, b := operations[i], operations[j]
+		if a.operation != b.operation {
+			return a.operation < b.operation
+		}
+		return a.method < b.method
+	})
+	fmt.Fprintln(bw, "# HELP stream_request_duration_seconds Latency of Stream API calls, including retries.")
+	fmt.Fprintln(bw, "# TYPE stream_request_duration_seconds histogram")
+	for _, k := range operations {
+		stats := m.operations[k]
+		labels := fmt.Sprintf("operation=%s,method=%s", quoteLabel(k.operation), quoteLabel(k.method))
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += stats.counts[i]
+			fmt.Fprintf(bw, "stream_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(bw, "stream_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stats.count)
+		fmt.Fprintf(bw, "stream_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(stats.sum))
+		fmt.Fprintf(bw, "stream_request_duration_seconds_count{%s} %d\n", labels, stats.count)
+	}
+	fmt.Fprintln(bw, "# HELP stream_request_bytes_total Total size in bytes of the Stream API requests bodies.")
+	fmt.Fprintln(bw, "# TYPE stream_request_bytes_total counter")
+	for _, k := range operations {
+		fmt.Fprintf(bw, "stream_request_bytes_total{operation=%s,method=%s} %d\n",
+			quoteLabel(k.operation), quoteLabel(k.method), m.operations[k].bytesSent)
+	}
+	fmt.Fprintln(bw, "# HELP stream_response_bytes_total Total size in bytes of the Stream API responses bodies.")
+	fmt.Fprintln(bw, "# TYPE stream_response_bytes_total counter")
+	for _, k := range operations {
+		fmt.Fprintf(bw, "stream_response_bytes_total{operation=%s,method=%s} %d\n",
+			quoteLabel(k.operation), quoteLabel(k.method), m.operations[k].bytesReceived)
+	}
+	fmt.Fprintln(bw, "# HELP stream_request_wire_bytes_total Total size in bytes of the Stream API requests bodies as sent, after compression.")
+	fmt.Fprintln(bw, "# TYPE stream_request_wire_bytes_total counter")
+	for _, k := range operations {
+		fmt.Fprintf(bw, "stream_request_wire_bytes_total{operation=%s,method=%s} %d\n",
+			quoteLabel(k.operation), quoteLabel(k.method), m.operations[k].wireSent)
+	}
+	fmt.Fprintln(bw, "# HELP stream_response_wire_bytes_total Total size in bytes of the Stream API responses bodies as received, before decompression.")
+	fmt.Fprintln(bw, "# TYPE stream_response_wire_bytes_total counter")
+	for _, k := range operations {
+		fmt.Fprintf(bw, "stream_response_wire_bytes_total{operation=%s,method=%s} %d\n",
+			quoteLabel(k.operation), quoteLabel(k.method), m.operations[k].wireReceived)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format, so
+// that InMemoryMetrics can be used as a scraping endpoint.
+func (m *InMemoryMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := m.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(value string) string {
+	return `"` + labelReplacer.Replace(value) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
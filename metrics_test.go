@@ -0,0 +1,141 @@
+package stream_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type metricsRecorder struct {
+	metrics []stream.CallMetrics
+}
+
+func (r *metricsRecorder) Observe(m stream.CallMetrics) {
+	r.metrics = append(r.metrics, m)
+}
+
+func TestMetricsCollector(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK, body: `{"results":[]}`},
+		{code: http.StatusTooManyRequests, body: `{"detail":"slow down"}`},
+		{code: http.StatusInternalServerError, body: `{"detail":"boom"}`},
+		{code: http.StatusNotFound, body: `{"detail":"not found"}`},
+		{err: errors.New("connection refused")},
+		{code: http.StatusOK, body: `not json`},
+	}}
+	recorder := &metricsRecorder{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithMetricsCollector(recorder),
+	)
+	require.NoError(t, err)
+	flat, _ := newFlatFeedWithUserID(client, "42")
+
+	_, err = flat.GetActivities()
+	require.NoError(t, err)
+	_, err = flat.AddActivity(stream.Activity{Actor: "bob", Verb: "like", Object: "cake"})
+	require.Error(t, err)
+	_, err = client.Users().Get("bob")
+	require.Error(t, err)
+	_, err = client.Users().Get("alice")
+	require.Error(t, err)
+	err = client.Reactions().Delete("abc")
+	require.Error(t, err)
+	_, err = client.Reactions().Get("abc")
+	require.Error(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Reactions().GetContext(ctx, "abc")
+	require.Error(t, err)
+
+	require.Len(t, recorder.metrics, 7)
+	m := recorder.metrics[0]
+	assert.Equal(t, "feed.get_activities", m.Operation)
+	assert.Equal(t, stream.ResourceFeed, m.Resource)
+	assert.Equal(t, http.MethodGet, m.Method)
+	assert.Equal(t, http.StatusOK, m.StatusCode)
+	assert.Equal(t, 0, m.BytesSent)
+	assert.Equal(t, len(`{"results":[]}`), m.BytesReceived)
+	assert.Equal(t, len(`{"results":[]}`), m.WireBytesReceived)
+	assert.Equal(t, 1, m.Attempts)
+	assert.True(t, m.Latency > 0)
+	assert.Equal(t, stream.ErrorClassNone, m.ErrorClass)
+
+	assert.Equal(t, "feed.add_activity", recorder.metrics[1].Operation)
+	assert.Equal(t, len(requester.bodies[1]), recorder.metrics[1].BytesSent)
+	assert.Equal(t, len(requester.bodies[1]), recorder.metrics[1].WireBytesSent)
+
+	classes := make([]stream.ErrorClass, len(recorder.metrics))
+	for i, m := range recorder.metrics {
+		classes[i] = m.ErrorClass
+	}
+	expected := []stream.ErrorClass{
+		stream.ErrorClassNone,
+		stream.ErrorClassRateLimited,
+		stream.ErrorClassServer,
+		stream.ErrorClassClient,
+		stream.ErrorClassTransport,
+		stream.ErrorClassOther,
+		stream.ErrorClassCanceled,
+	}
+	assert.Equal(t, expected, classes)
+}
+
+func TestInMemoryMetrics(t *testing.T) {
+	metrics := stream.NewInMemoryMetrics(0.5, 0.1)
+	metrics.Observe(stream.CallMetrics{Operation: "feed.get_activities", Method: "GET", StatusCode: 200, Latency: 50 * time.Millisecond, BytesReceived: 100, WireBytesReceived: 40})
+	metrics.Observe(stream.CallMetrics{Operation: "feed.get_activities", Method: "GET", StatusCode: 200, Latency: 200 * time.Millisecond, BytesReceived: 50, WireBytesReceived: 50})
+	metrics.Observe(stream.CallMetrics{Operation: "feed.get_activities", Method: "GET", StatusCode: 503, Latency: time.Second, ErrorClass: stream.ErrorClassServer})
+	metrics.Observe(stream.CallMetrics{Operation: `weird"op`, Method: "POST", StatusCode: 201, Latency: 100 * time.Millisecond, BytesSent: 10, BytesReceived: 20, WireBytesSent: 10, WireBytesReceived: 20})
+
+	var buf bytes.Buffer
+	require.NoError(t, metrics.WritePrometheus(&buf))
+	expected := `# HELP stream_requests_total Total number of Stream API calls.
+# TYPE stream_requests_total counter
+stream_requests_total{operation="feed.get_activities",method="GET",status_code="200",error_class=""} 2
+stream_requests_total{operation="feed.get_activities",method="GET",status_code="503",error_class="server_error"} 1
+stream_requests_total{operation="weird\"op",method="POST",status_code="201",error_class=""} 1
+# HELP stream_request_duration_seconds Latency of Stream API calls, including retries.
+# TYPE stream_request_duration_seconds histogram
+stream_request_duration_seconds_bucket{operation="feed.get_activities",method="GET",le="0.1"} 1
+stream_request_duration_seconds_bucket{operation="feed.get_activities",method="GET",le="0.5"} 2
+stream_request_duration_seconds_bucket{operation="feed.get_activities",method="GET",le="+Inf"} 3
+stream_request_duration_seconds_sum{operation="feed.get_activities",method="GET"} 1.25
+stream_request_duration_seconds_count{operation="feed.get_activities",method="GET"} 3
+stream_request_duration_seconds_bucket{operation="weird\"op",method="POST",le="0.1"} 1
+stream_request_duration_seconds_bucket{operation="weird\"op",method="POST",le="0.5"} 1
+stream_request_duration_seconds_bucket{operation="weird\"op",method="POST",le="+Inf"} 1
+stream_request_duration_seconds_sum{operation="weird\"op",method="POST"} 0.1
+stream_request_duration_seconds_count{operation="weird\"op",method="POST"} 1
+# HELP stream_request_bytes_total Total size in bytes of the Stream API requests bodies.
+# TYPE stream_request_bytes_total counter
+stream_request_bytes_total{operation="feed.get_activities",method="GET"} 0
+stream_request_bytes_total{operation="weird\"op",method="POST"} 10
+# HELP stream_response_bytes_total Total size in bytes of the Stream API responses bodies.
+# TYPE stream_response_bytes_total counter
+stream_response_bytes_total{operation="feed.get_activities",method="GET"} 150
+stream_response_bytes_total{operation="weird\"op",method="POST"} 20
+# HELP stream_request_wire_bytes_total Total size in bytes of the Stream API requests bodies as sent, after compression.
+# TYPE stream_request_wire_bytes_total counter
+stream_request_wire_bytes_total{operation="feed.get_activities",method="GET"} 0
+stream_request_wire_bytes_total{operation="weird\"op",method="POST"} 10
+# HELP stream_response_wire_bytes_total Total size in bytes of the Stream API responses bodies as received, before decompression.
+# TYPE stream_response_wire_bytes_total counter
+stream_response_wire_bytes_total{operation="feed.get_activities",method="GET"} 90
+stream_response_wire_bytes_total{operation="weird\"op",method="POST"} 20
+`
+	assert.Equal(t, expected, buf.String())
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, expected, rec.Body.String())
+}
@@ -0,0 +1,75 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+)
+
+// Call describes an API call going through the middleware chain of a Client.
+type Call struct {
+	// Operation is the logical operation performed by the call, e.g.
+	// "feed.add_activity" or "reactions.filter".
+	Operation string
+	// FeedID is the ID of the feed targeted by the call, if any.
+	FeedID string
+	// Method is the HTTP method of the call.
+	Method string
+	// URL is the URL of the API endpoint, including the query parameters. It's
+	// read-only: changing it doesn't change the URL the request is sent to.
+	URL string
+	// Resource is the API resource the endpoint belongs to. It's read-only.
+	Resource Resource
+	// Body is the request data, encoded as JSON before being sent. Middleware
+	// can replace it before calling the next Handler.
+	Body interface{}
+	// Header holds additional HTTP headers sent with the request. Middleware can
+	// add headers before calling the next Handler.
+	Header http.Header
+	// Response is the value the response body is decoded into, nil if the call
+	// doesn't return any data. It's filled once the next Handler returns.
+	Response interface{}
+	// StatusCode is the HTTP status code of the last response received, zero
+	// if none was received.
+	StatusCode int
+	// Attempts is the number of HTTP requests performed for the call, including
+	// retries.
+	Attempts int
+	// RequestSize is the size in bytes of the encoded request body.
+	RequestSize int
+	// RequestWireSize is the size in bytes of the request body as sent, which
+	// is smaller than RequestSize when compressed.
+	RequestWireSize int
+	// ResponseSize is the size in bytes of the response body, zero if the call
+	// failed.
+	ResponseSize int
+	// ResponseWireSize is the size in bytes of the response body as received,
+	// which is smaller than ResponseSize when compressed, zero if the call
+	// failed.
+	ResponseWireSize int
+}
+
+// Handler performs an API call.
+type Handler func(ctx context.Context, call *Call) error
+
+// Middleware wraps a Handler, running code before and after the API call is
+// performed. It can also skip the call altogether, returning an error or
+// filling the Response without calling the next Handler.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to the chain wrapping every API call
+// performed by a given Client. The first middleware is the outermost one: it
+// runs first before the call and last after it. Middleware run once per call,
+// regardless of the number of attempts performed by the retry policy.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// chain wraps the given Handler with the middleware of the Client.
+func (c *Client) chain(h Handler) Handler {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,108 @@
+package stream_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var events []string
+	var calls []stream.Call
+	recorder := func(name string) stream.Middleware {
+		return func(next stream.Handler) stream.Handler {
+			return func(ctx context.Context, call *stream.Call) error {
+				events = append(events, "before "+name)
+				err := next(ctx, call)
+				events = append(events, "after "+name)
+				calls = append(calls, *call)
+				return err
+			}
+		}
+	}
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusServiceUnavailable, body: `{"detail":"unavailable"}`},
+		{code: http.StatusOK, body: `{"id":"abc"}`},
+		{code: http.StatusNotFound, body: `{"detail":"not found"}`},
+	}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRetryPolicy(stream.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		stream.WithMiddleware(recorder("first"), recorder("second")),
+	)
+	require.NoError(t, err)
+
+	r := stream.AddReactionRequestObject{ID: "abc", Kind: "like", ActivityID: "a", UserID: "u"}
+	_, err = client.Reactions().Add(r)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"before first", "before second", "after second", "after first"}, events)
+	require.Len(t, calls, 2)
+	call := calls[1]
+	assert.Equal(t, http.MethodPost, call.Method)
+	assert.Equal(t, "https://api.stream-io-api.com/api/v1.0/reaction/?api_key=key", call.URL)
+	assert.Equal(t, stream.ResourceReactions, call.Resource)
+	assert.Equal(t, r, call.Body)
+	assert.Equal(t, "abc", call.Response.(*stream.Reaction).ID)
+	assert.Equal(t, http.StatusOK, call.StatusCode)
+	assert.Equal(t, 2, call.Attempts)
+
+	calls = nil
+	err = client.Reactions().Delete("abc")
+	require.Error(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, http.MethodDelete, calls[1].Method)
+	assert.Nil(t, calls[1].Response)
+	assert.Equal(t, http.StatusNotFound, calls[1].StatusCode)
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{{code: http.StatusOK}}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithMiddleware(func(next stream.Handler) stream.Handler {
+			return func(ctx context.Context, call *stream.Call) error {
+				call.Header.Set("X-Request-Id", "123")
+				call.Header.Set("Authorization", "overridden")
+				return next(ctx, call)
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Users().Get("bob")
+	require.NoError(t, err)
+	require.Len(t, requester.reqs, 1)
+	assert.Equal(t, "123", requester.reqs[0].Header.Get("X-Request-Id"))
+	assert.NotEqual(t, "overridden", requester.reqs[0].Header.Get("Authorization"))
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	injected := errors.New("injected failure")
+	requester := &scriptedRequester{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithMiddleware(func(next stream.Handler) stream.Handler {
+			return func(ctx context.Context, call *stream.Call) error {
+				if call.Resource == stream.ResourceUsers {
+					return injected
+				}
+				call.Response.(*stream.Reaction).ID = "cached"
+				return nil
+			}
+		}),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Users().Get("bob")
+	assert.Equal(t, injected, err)
+	reaction, err := client.Reactions().Get("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "cached", reaction.ID)
+	assert.Empty(t, requester.reqs)
+}
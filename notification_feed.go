@@ -1,6 +1,6 @@
 package stream
 
-import "encoding/json"
+import "context"
 
 // NotificationFeed is a Stream notification feed.
 type NotificationFeed struct {
@@ -10,12 +10,13 @@ type NotificationFeed struct {
 // GetActivities returns the activities for the given NotificationFeed, filtering
 // results with the provided GetActivitiesOption parameters.
 func (f *NotificationFeed) GetActivities(opts ...GetActivitiesOption) (*NotificationFeedResponse, error) {
-	body, err := f.client.getActivities(f, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return f.GetActivitiesContext(context.Background(), opts...)
+}
+
+// GetActivitiesContext is like GetActivities, using the provided context for the request.
+func (f *NotificationFeed) GetActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*NotificationFeedResponse, error) {
 	var resp NotificationFeedResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := f.client.getActivities(ctx, f, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -24,22 +25,28 @@ func (f *NotificationFeed) GetActivities(opts ...GetActivitiesOption) (*Notifica
 // GetNextPageActivities returns the activities for the given NotificationFeed at the "next" page
 // of a previous *NotificationFeedResponse response, if any.
 func (f *NotificationFeed) GetNextPageActivities(resp *NotificationFeedResponse) (*NotificationFeedResponse, error) {
-	opts, err := resp.parseNext()
-	if err != nil {
+	return f.GetNextPageActivitiesContext(context.Background(), resp)
+}
+
+// GetNextPageActivitiesContext is like GetNextPageActivities, using the provided context for the request.
+func (f *NotificationFeed) GetNextPageActivitiesContext(ctx context.Context, resp *NotificationFeedResponse) (*NotificationFeedResponse, error) {
+	var next NotificationFeedResponse
+	if err := f.client.getNextActivities(ctx, f, resp.Next, &next); err != nil {
 		return nil, err
 	}
-	return f.GetActivities(opts...)
+	return &next, nil
 }
 
 // GetEnrichedActivities returns the enriched activities for the given NotificationFeed, filtering
 // results with the provided GetActivitiesOption parameters.
 func (f *NotificationFeed) GetEnrichedActivities(opts ...GetActivitiesOption) (*EnrichedNotificationFeedResponse, error) {
-	body, err := f.client.getEnrichedActivities(f, opts...)
-	if err != nil {
-		return nil, err
-	}
+	return f.GetEnrichedActivitiesContext(context.Background(), opts...)
+}
+
+// GetEnrichedActivitiesContext is like GetEnrichedActivities, using the provided context for the request.
+func (f *NotificationFeed) GetEnrichedActivitiesContext(ctx context.Context, opts ...GetActivitiesOption) (*EnrichedNotificationFeedResponse, error) {
 	var resp EnrichedNotificationFeedResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := f.client.getEnrichedActivities(ctx, f, &resp, opts...); err != nil {
 		return nil, err
 	}
 	return &resp, nil
@@ -48,9 +55,14 @@ func (f *NotificationFeed) GetEnrichedActivities(opts ...GetActivitiesOption) (*
 // GetNextPageEnrichedActivities returns the enriched activities for the given NotificationFeed at the "next" page
 // of a previous *EnrichedNotificationFeedResponse response, if any.
 func (f *NotificationFeed) GetNextPageEnrichedActivities(resp *EnrichedNotificationFeedResponse) (*EnrichedNotificationFeedResponse, error) {
-	opts, err := resp.parseNext()
-	if err != nil {
+	return f.GetNextPageEnrichedActivitiesContext(context.Background(), resp)
+}
+
+// GetNextPageEnrichedActivitiesContext is like GetNextPageEnrichedActivities, using the provided context for the request.
+func (f *NotificationFeed) GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *EnrichedNotificationFeedResponse) (*EnrichedNotificationFeedResponse, error) {
+	var next EnrichedNotificationFeedResponse
+	if err := f.client.getNextEnrichedActivities(ctx, f, resp.Next, &next); err != nil {
 		return nil, err
 	}
-	return f.GetEnrichedActivities(opts...)
+	return &next, nil
 }
@@ -0,0 +1,205 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// NumberDecoding is how the numbers of custom fields, which have no declared
+// type, are decoded.
+type NumberDecoding int
+
+// The number decodings.
+const (
+	// NumbersAsFloat64 decodes numbers as float64, as encoding/json does.
+	// Integers larger than 2^53 lose precision. It's the default.
+	NumbersAsFloat64 NumberDecoding = iota
+	// NumbersAsJSONNumber decodes numbers as json.Number, keeping their exact
+	// representation.
+	NumbersAsJSONNumber
+	// NumbersAsInt64 decodes integers as int64 when they fit, and other
+	// numbers as float64.
+	NumbersAsInt64
+)
+
+// WithNumberDecoding sets how a given Client decodes the numbers of custom
+// fields: the extra fields of activities and enriched objects, the data of
+// collection objects, users and reactions, and the results and extra fields of
+// personalization responses. Decoding numbers other than as float64 requires
+// buffering each response and decoding it twice. Extra fields set with
+// EncodeExtra keep their numbers exact, so that values decoded losslessly can
+// be sent back unchanged.
+func WithNumberDecoding(decoding NumberDecoding) ClientOption {
+	return func(c *Client) {
+		c.numbers = decoding
+	}
+}
+
+// decodeNumbers unmarshals the given response body into out, decoding the
+// numbers of its untyped values with the given decoding.
+func decodeNumbers(data []byte, out interface{}, decoding NumberDecoding) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+	restoreNumbers(reflect.ValueOf(out), tree, decoding)
+	return nil
+}
+
+var (
+	emptyInterfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	extraType          = reflect.TypeOf(map[string]interface{}{})
+)
+
+// restoreNumbers replaces the untyped values held by v, as decoded by
+// encoding/json, with the ones of the corresponding node of the JSON document,
+// decoded with UseNumber, converting their numbers with the given decoding.
+// The extra fields of activities and enriched objects, which are the members
+// of their object not matching any field, are restored as well.
+func restoreNumbers(v reflect.Value, node interface{}, decoding NumberDecoding) {
+	if node == nil {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			restoreNumbers(v.Elem(), node, decoding)
+		}
+	case reflect.Interface:
+		if v.Type() == emptyInterfaceType && v.CanSet() {
+			v.Set(reflect.ValueOf(convertNumbers(node, decoding)))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		if v.Type().Elem() == emptyInterfaceType {
+			if obj, ok := node.(map[string]interface{}); ok && v.CanSet() {
+				v.Set(reflect.ValueOf(convertNumbers(obj, decoding)).Convert(v.Type()))
+			}
+			return
+		}
+		obj, ok := node.(map[string]interface{})
+		if !ok || !v.CanInterface() || v.Type().Key().Kind() != reflect.String || !mayHoldUntyped(v.Type().Elem()) {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			restoreNumbers(elem, obj[key.String()], decoding)
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Slice:
+		list, ok := node.([]interface{})
+		if !ok || v.Len() != len(list) {
+			return
+		}
+		if v.Type().Elem() == emptyInterfaceType {
+			if v.CanSet() {
+				v.Set(reflect.ValueOf(convertNumbers(list, decoding)).Convert(v.Type()))
+			}
+			return
+		}
+		for i := range list {
+			restoreNumbers(v.Index(i), list[i], decoding)
+		}
+	case reflect.Struct:
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if v.CanAddr() && v.Addr().CanInterface() {
+			if r, ok := v.Addr().Interface().(extraRestorer); ok {
+				r.restoreExtra(obj, decoding)
+			}
+		}
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath != "" && !field.Anonymous {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			switch {
+			case field.Anonymous && name == "":
+				restoreNumbers(v.Field(i), obj, decoding)
+			case name == "-":
+				if field.Name == "Extra" && field.Type == extraType {
+					restoreExtra(v.Field(i), obj, decoding)
+				}
+			default:
+				if name == "" {
+					name = field.Name
+				}
+				restoreNumbers(v.Field(i), obj[name], decoding)
+			}
+		}
+	}
+}
+
+// extraRestorer is implemented by the types keeping their extra fields in an
+// unexported field, which restoreNumbers can't reach.
+type extraRestorer interface {
+	restoreExtra(obj map[string]interface{}, decoding NumberDecoding)
+}
+
+// mayHoldUntyped tells whether values of the given type may hold untyped
+// values.
+func mayHoldUntyped(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// restoreExtra restores the extra fields held by v from the object they were
+// decoded from.
+func restoreExtra(v reflect.Value, obj map[string]interface{}, decoding NumberDecoding) {
+	if !v.CanInterface() {
+		return
+	}
+	extra, ok := v.Interface().(map[string]interface{})
+	if !ok {
+		return
+	}
+	for k := range extra {
+		if node, ok := obj[k]; ok {
+			extra[k] = convertNumbers(node, decoding)
+		}
+	}
+}
+
+// convertNumbers converts the json.Number values held by the given untyped
+// value with the given decoding.
+func convertNumbers(node interface{}, decoding NumberDecoding) interface{} {
+	switch node := node.(type) {
+	case json.Number:
+		switch decoding {
+		case NumbersAsJSONNumber:
+			return node
+		case NumbersAsInt64:
+			if i, err := node.Int64(); err == nil {
+				return i
+			}
+		}
+		f, _ := node.Float64()
+		return f
+	case map[string]interface{}:
+		for k, v := range node {
+			node[k] = convertNumbers(v, decoding)
+		}
+	case []interface{}:
+		for i, v := range node {
+			node[i] = convertNumbers(v, decoding)
+		}
+	}
+	return node
+}
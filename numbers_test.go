@@ -0,0 +1,104 @@
+package stream_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routeRequester replies with the body of the longest route whose path is
+// part of the request URL.
+type routeRequester map[string]string
+
+func (r routeRequester) Do(req *http.Request) (*http.Response, error) {
+	body, matched := "{}", ""
+	for path, b := range r {
+		if strings.Contains(req.URL.Path, path) && len(path) > len(matched) {
+			body, matched = b, path
+		}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+func TestNumberDecoding(t *testing.T) {
+	const big = 9007199254740993 // 2^53 + 1
+	requester := routeRequester{
+		"/enrich/feed/": `{"results":[{"id":"1","actor":{"id":"alice","followers":9007199254740993},"verb":"post","object":"post:1",` +
+			`"views":9007199254740993,"ratio":1.5,"latest_reactions":{"like":[{"kind":"like","data":{"count":9007199254740993}}]}}]}`,
+		"/feed/":            `{"results":[{"id":"1","actor":"alice","verb":"post","object":"post:1","views":9007199254740993,"stats":{"shares":[9007199254740993,2.5]}}]}`,
+		"/collections/":     `{"id":"1","data":{"views":9007199254740993}}`,
+		"/user/":            `{"id":"bob","data":{"followers":9007199254740993}}`,
+		"/reaction/":        `{"id":"r1","kind":"like","data":{"count":9007199254740993},"children_counts":{"like":9007199254740993}}`,
+		"/personalization/": `{"results":[{"score":9007199254740993}],"duration":"10ms","total":9007199254740993}`,
+	}
+	testCases := []struct {
+		decoding stream.NumberDecoding
+		exact    interface{}
+		ratio    interface{}
+	}{
+		{decoding: stream.NumbersAsFloat64, exact: float64(big), ratio: 1.5},
+		{decoding: stream.NumbersAsJSONNumber, exact: json.Number("9007199254740993"), ratio: json.Number("1.5")},
+		{decoding: stream.NumbersAsInt64, exact: int64(big), ratio: 1.5},
+	}
+	for _, tc := range testCases {
+		client, err := stream.NewClient("key", "secret",
+			stream.WithHTTPRequester(requester),
+			stream.WithNumberDecoding(tc.decoding),
+		)
+		require.NoError(t, err)
+		feed, err := client.FlatFeed("timeline", "bob")
+		require.NoError(t, err)
+
+		activities, err := feed.GetActivities()
+		require.NoError(t, err)
+		require.Len(t, activities.Results, 1)
+		assert.Equal(t, "alice", activities.Results[0].Actor)
+		assert.Equal(t, tc.exact, activities.Results[0].Extra["views"])
+		shares := activities.Results[0].Extra["stats"].(map[string]interface{})["shares"].([]interface{})
+		assert.Equal(t, tc.exact, shares[0])
+		if tc.decoding != stream.NumbersAsFloat64 {
+			data, err := json.Marshal(activities.Results[0])
+			require.NoError(t, err)
+			assert.Contains(t, string(data), `"views":9007199254740993`)
+		}
+
+		enriched, err := feed.GetEnrichedActivities()
+		require.NoError(t, err)
+		require.Len(t, enriched.Results, 1)
+		activity := enriched.Results[0]
+		assert.Equal(t, tc.exact, activity.Extra["views"])
+		assert.Equal(t, tc.ratio, activity.Extra["ratio"])
+		assert.Equal(t, "alice", activity.Actor.ID)
+		assert.Equal(t, tc.exact, activity.Actor.Extra["followers"])
+		assert.Equal(t, tc.exact, activity.LatestReactions["like"][0].Data["count"])
+
+		object, err := client.Collections().Get("pictures", "1")
+		require.NoError(t, err)
+		assert.Equal(t, tc.exact, object.Data["views"])
+
+		user, err := client.Users().Get("bob")
+		require.NoError(t, err)
+		assert.Equal(t, tc.exact, user.Data["followers"])
+
+		reaction, err := client.Reactions().Get("r1")
+		require.NoError(t, err)
+		assert.Equal(t, tc.exact, reaction.Data["count"])
+		assert.Equal(t, tc.exact, reaction.ChildrenCounters["like"])
+
+		personalized, err := client.Personalization().Get("follow_recommendations", nil)
+		require.NoError(t, err)
+		require.Len(t, personalized.Results, 1)
+		assert.Equal(t, tc.exact, personalized.Results[0]["score"])
+		assert.Equal(t, tc.exact, personalized.Extra()["total"])
+	}
+}
@@ -1,7 +1,7 @@
 package stream
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
@@ -12,31 +12,39 @@ type PersonalizationClient struct {
 
 // Get obtains a PersonalizationResponse for the given resource and params.
 func (c *PersonalizationClient) Get(resource string, params map[string]interface{}) (*PersonalizationResponse, error) {
+	return c.GetContext(context.Background(), resource, params)
+}
+
+// GetContext is like Get, using the provided context for the request.
+func (c *PersonalizationClient) GetContext(ctx context.Context, resource string, params map[string]interface{}) (*PersonalizationResponse, error) {
 	if resource == "" {
 		return nil, fmt.Errorf("missing resource")
 	}
-	endpoint := c.client.makeEndpoint("%s/", resource)
+	endpoint := c.client.makeEndpoint(ResourcePersonalization, "%s/", resource)
+	endpoint.operation = "personalization.get"
 	for k, v := range params {
 		endpoint.addQueryParam(makeRequestOption(k, v))
 	}
-	resp, err := c.client.get(endpoint, nil, c.client.authenticator.personalizationAuth)
-	if err != nil {
-		return nil, err
-	}
 	var personalizationResp PersonalizationResponse
-	err = json.Unmarshal(resp, &personalizationResp)
+	err := c.client.get(ctx, endpoint, nil, &personalizationResp, c.client.authenticator.personalizationAuth)
 	if err != nil {
-		return nil, fmt.Errorf("cannot unmarshal resp: %s", err)
+		return nil, err
 	}
 	return &personalizationResp, nil
 }
 
 // Post sends data to the given resource, adding the given params to the request.
 func (c *PersonalizationClient) Post(resource string, params map[string]interface{}, data map[string]interface{}) error {
+	return c.PostContext(context.Background(), resource, params, data)
+}
+
+// PostContext is like Post, using the provided context for the request.
+func (c *PersonalizationClient) PostContext(ctx context.Context, resource string, params map[string]interface{}, data map[string]interface{}) error {
 	if resource == "" {
 		return fmt.Errorf("missing resource")
 	}
-	endpoint := c.client.makeEndpoint("%s/", resource)
+	endpoint := c.client.makeEndpoint(ResourcePersonalization, "%s/", resource)
+	endpoint.operation = "personalization.post"
 	for k, v := range params {
 		endpoint.addQueryParam(makeRequestOption(k, v))
 	}
@@ -45,19 +53,23 @@ func (c *PersonalizationClient) Post(resource string, params map[string]interfac
 			"data": data,
 		}
 	}
-	_, err := c.client.post(endpoint, data, c.client.authenticator.personalizationAuth)
-	return err
+	return c.client.post(ctx, endpoint, data, nil, c.client.authenticator.personalizationAuth)
 }
 
 // Delete removes data from the given resource, adding the given params to the request.
 func (c *PersonalizationClient) Delete(resource string, params map[string]interface{}) error {
+	return c.DeleteContext(context.Background(), resource, params)
+}
+
+// DeleteContext is like Delete, using the provided context for the request.
+func (c *PersonalizationClient) DeleteContext(ctx context.Context, resource string, params map[string]interface{}) error {
 	if resource == "" {
 		return fmt.Errorf("missing resource")
 	}
-	endpoint := c.client.makeEndpoint("%s/", resource)
+	endpoint := c.client.makeEndpoint(ResourcePersonalization, "%s/", resource)
+	endpoint.operation = "personalization.delete"
 	for k, v := range params {
 		endpoint.addQueryParam(makeRequestOption(k, v))
 	}
-	_, err := c.client.delete(endpoint, nil, c.client.authenticator.personalizationAuth)
-	return err
+	return c.client.delete(ctx, endpoint, nil, nil, c.client.authenticator.personalizationAuth)
 }
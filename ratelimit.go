@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	headerRateLimitLimit     = "X-RateLimit-Limit"
+	headerRateLimitRemaining = "X-RateLimit-Remaining"
+	headerRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimit is a snapshot of the rate limit status of an API endpoint, as
+// reported by the X-RateLimit-* headers of a response.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window.
+	Limit int
+	// Remaining is the number of requests left in the current window.
+	Remaining int
+	// Reset is the time at which the current window ends.
+	Reset time.Time
+}
+
+// parseRateLimit reads the rate limit headers of a response, returning nil when
+// they're missing or malformed.
+func parseRateLimit(header http.Header) *RateLimit {
+	if header == nil {
+		return nil
+	}
+	limit, err := strconv.Atoi(header.Get(headerRateLimitLimit))
+	if err != nil {
+		return nil
+	}
+	remaining, err := strconv.Atoi(header.Get(headerRateLimitRemaining))
+	if err != nil {
+		return nil
+	}
+	reset, err := strconv.ParseInt(header.Get(headerRateLimitReset), 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	}
+}
+
+// rateLimited is embedded in API responses to expose the rate limit status
+// reported along with them.
+type rateLimited struct {
+	rateLimit *RateLimit
+}
+
+// RateLimit returns the rate limit status reported by the API along with the
+// response, or nil if the API didn't send one.
+func (r rateLimited) RateLimit() *RateLimit {
+	return r.rateLimit
+}
+
+func (r *rateLimited) setRateLimit(rl *RateLimit) {
+	r.rateLimit = rl
+}
+
+// rateLimitKey identifies an endpoint (i.e. an HTTP method and an API resource)
+// for rate limiting purposes, e.g. "GET feed".
+func rateLimitKey(method string, res Resource) string {
+	return fmt.Sprintf("%s %s", method, res)
+}
+
+// rateLimitStore keeps the last rate limit status seen for each endpoint. It's
+// shared by a Client and all the specialized clients created from it.
+type rateLimitStore struct {
+	mu     sync.RWMutex
+	limits map[string]RateLimit
+}
+
+func newRateLimitStore() *rateLimitStore {
+	return &rateLimitStore{limits: make(map[string]RateLimit)}
+}
+
+func (s *rateLimitStore) set(key string, rl RateLimit) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits[key] = rl
+}
+
+func (s *rateLimitStore) all() map[string]RateLimit {
+	limits := make(map[string]RateLimit)
+	if s == nil {
+		return limits
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, v := range s.limits {
+		limits[k] = v
+	}
+	return limits
+}
+
+// RateLimits returns the last rate limit status reported by the API for each
+// endpoint called so far by the Client or any of its specialized clients,
+// keyed by HTTP method and resource (e.g. "GET feed", "POST reactions").
+func (c *Client) RateLimits() map[string]RateLimit {
+	return c.rateLimits.all()
+}
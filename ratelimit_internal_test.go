@@ -0,0 +1,47 @@
+package stream
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseRateLimit(t *testing.T) {
+	testCases := []struct {
+		header   http.Header
+		expected *RateLimit
+	}{
+		{header: nil},
+		{header: http.Header{}},
+		{
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"abc"},
+				"X-Ratelimit-Remaining": []string{"10"},
+				"X-Ratelimit-Reset":     []string{"1546300800"},
+			},
+		},
+		{
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"100"},
+				"X-Ratelimit-Remaining": []string{"10"},
+			},
+		},
+		{
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"100"},
+				"X-Ratelimit-Remaining": []string{"10"},
+				"X-Ratelimit-Reset":     []string{"1546300800"},
+			},
+			expected: &RateLimit{Limit: 100, Remaining: 10, Reset: time.Unix(1546300800, 0)},
+		},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, parseRateLimit(tc.header))
+	}
+}
+
+func TestRateLimits_zeroClient(t *testing.T) {
+	assert.Empty(t, (&Client{}).RateLimits())
+}
@@ -0,0 +1,87 @@
+package stream_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitHeader(limit, remaining int, reset time.Time) http.Header {
+	h := http.Header{}
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return h
+}
+
+func TestRateLimitOnResponses(t *testing.T) {
+	reset := time.Unix(1546300800, 0)
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK, body: `{"results":[]}`, header: rateLimitHeader(100, 99, reset)},
+		{code: http.StatusCreated, body: `{"id":"abc"}`, header: rateLimitHeader(50, 10, reset)},
+		{code: http.StatusOK, body: `{"results":[]}`},
+	}}
+	client := newRetryingClient(t, requester, 1)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, &stream.RateLimit{Limit: 100, Remaining: 99, Reset: reset}, resp.RateLimit())
+
+	added, err := flat.AddActivity(stream.Activity{Actor: "bob", Verb: "like", Object: "cake"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", added.ID)
+	assert.Equal(t, &stream.RateLimit{Limit: 50, Remaining: 10, Reset: reset}, added.RateLimit())
+
+	resp, err = flat.GetActivities()
+	require.NoError(t, err)
+	assert.Nil(t, resp.RateLimit())
+}
+
+func TestRateLimitOnErrors(t *testing.T) {
+	reset := time.Unix(1546300800, 0)
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusTooManyRequests, body: `{"detail":"rate limited"}`, header: rateLimitHeader(100, 0, reset)},
+	}}
+	client := newRetryingClient(t, requester, 1)
+
+	_, err := client.Reactions().Get("abc")
+	require.Error(t, err)
+	apiErr, ok := stream.ToAPIError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	assert.Equal(t, &stream.RateLimit{Limit: 100, Remaining: 0, Reset: reset}, apiErr.RateLimit)
+}
+
+func TestClientRateLimits(t *testing.T) {
+	reset := time.Unix(1546300800, 0)
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK, body: `{"results":[]}`, header: rateLimitHeader(100, 99, reset)},
+		{code: http.StatusOK, body: `{"results":[]}`, header: rateLimitHeader(100, 98, reset)},
+		{code: http.StatusOK, header: rateLimitHeader(20, 5, reset)},
+		{code: http.StatusNotFound, body: `{"detail":"not found"}`, header: rateLimitHeader(30, 7, reset)},
+	}}
+	client := newRetryingClient(t, requester, 1)
+	assert.Empty(t, client.RateLimits())
+
+	flat, _ := newFlatFeedWithUserID(client, "123")
+	_, err := flat.GetActivities()
+	require.NoError(t, err)
+	_, err = flat.GetActivities()
+	require.NoError(t, err)
+	require.NoError(t, client.Reactions().Delete("abc"))
+	_, err = client.Users().Get("bob")
+	require.Error(t, err)
+
+	expected := map[string]stream.RateLimit{
+		"GET feed":         {Limit: 100, Remaining: 98, Reset: reset},
+		"DELETE reactions": {Limit: 20, Remaining: 5, Reset: reset},
+		"GET users":        {Limit: 30, Remaining: 7, Reset: reset},
+	}
+	assert.Equal(t, expected, client.RateLimits())
+}
@@ -1,9 +1,8 @@
 package stream
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
-	"fmt"
 )
 
 // ReactionsClient is a specialized client used to interact with the Reactions endpoints.
@@ -13,27 +12,35 @@ type ReactionsClient struct {
 
 //Add adds a reaction.
 func (c *ReactionsClient) Add(r AddReactionRequestObject) (*Reaction, error) {
+	return c.AddContext(context.Background(), r)
+}
+
+// AddContext is like Add, using the provided context for the request.
+func (c *ReactionsClient) AddContext(ctx context.Context, r AddReactionRequestObject) (*Reaction, error) {
 	if r.ParentID != "" {
 		return nil, errors.New("`Parent` not empty. For adding child reactions use `AddChild`")
 	}
-	return c.addReaction(r)
+	return c.addReaction(ctx, r)
 }
 
 //AddChild adds a child reaction to the provided parent.
 func (c *ReactionsClient) AddChild(parentID string, r AddReactionRequestObject) (*Reaction, error) {
-	r.ParentID = parentID
-	return c.addReaction(r)
+	return c.AddChildContext(context.Background(), parentID, r)
 }
 
-func (c *ReactionsClient) addReaction(r AddReactionRequestObject) (*Reaction, error) {
-	endpoint := c.client.makeEndpoint("reaction/")
-	resp, err := c.client.post(endpoint, r, c.client.authenticator.reactionsAuth)
-	if err != nil {
-		return nil, err
-	}
+// AddChildContext is like AddChild, using the provided context for the request.
+func (c *ReactionsClient) AddChildContext(ctx context.Context, parentID string, r AddReactionRequestObject) (*Reaction, error) {
+	r.ParentID = parentID
+	return c.addReaction(ctx, r)
+}
 
+func (c *ReactionsClient) addReaction(ctx context.Context, r AddReactionRequestObject) (*Reaction, error) {
+	endpoint := c.client.makeEndpoint(ResourceReactions, "reaction/")
+	endpoint.operation = "reactions.add"
+	// reactions with a client-provided ID cannot be added twice
+	endpoint.idempotent = r.ID != ""
 	result := &Reaction{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.post(ctx, endpoint, r, result, c.client.authenticator.reactionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -42,19 +49,20 @@ func (c *ReactionsClient) addReaction(r AddReactionRequestObject) (*Reaction, er
 
 // Update updates the reaction's data and/or target feeds.
 func (c *ReactionsClient) Update(id string, data map[string]interface{}, targetFeeds []string) (*Reaction, error) {
-	endpoint := c.client.makeEndpoint("reaction/%s/", id)
+	return c.UpdateContext(context.Background(), id, data, targetFeeds)
+}
+
+// UpdateContext is like Update, using the provided context for the request.
+func (c *ReactionsClient) UpdateContext(ctx context.Context, id string, data map[string]interface{}, targetFeeds []string) (*Reaction, error) {
+	endpoint := c.client.makeEndpoint(ResourceReactions, "reaction/%s/", id)
+	endpoint.operation = "reactions.update"
 
 	reqData := map[string]interface{}{
 		"data":         data,
 		"target_feeds": targetFeeds,
 	}
-	resp, err := c.client.put(endpoint, reqData, c.client.authenticator.reactionsAuth)
-	if err != nil {
-		return nil, err
-	}
-
 	result := &Reaction{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.put(ctx, endpoint, reqData, result, c.client.authenticator.reactionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -63,15 +71,16 @@ func (c *ReactionsClient) Update(id string, data map[string]interface{}, targetF
 
 //Get retrieves a reaction having the given id.
 func (c *ReactionsClient) Get(id string) (*Reaction, error) {
-	endpoint := c.client.makeEndpoint("reaction/%s/", id)
+	return c.GetContext(context.Background(), id)
+}
 
-	resp, err := c.client.get(endpoint, nil, c.client.authenticator.reactionsAuth)
-	if err != nil {
-		return nil, err
-	}
+// GetContext is like Get, using the provided context for the request.
+func (c *ReactionsClient) GetContext(ctx context.Context, id string) (*Reaction, error) {
+	endpoint := c.client.makeEndpoint(ResourceReactions, "reaction/%s/", id)
+	endpoint.operation = "reactions.get"
 
 	result := &Reaction{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.get(ctx, endpoint, nil, result, c.client.authenticator.reactionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -80,29 +89,31 @@ func (c *ReactionsClient) Get(id string) (*Reaction, error) {
 
 //Delete deletes a reaction having the given id.
 func (c *ReactionsClient) Delete(id string) error {
-	endpoint := c.client.makeEndpoint("reaction/%s/", id)
+	return c.DeleteContext(context.Background(), id)
+}
 
-	_, err := c.client.delete(endpoint, nil, c.client.authenticator.reactionsAuth)
-	return err
+// DeleteContext is like Delete, using the provided context for the request.
+func (c *ReactionsClient) DeleteContext(ctx context.Context, id string) error {
+	endpoint := c.client.makeEndpoint(ResourceReactions, "reaction/%s/", id)
+	endpoint.operation = "reactions.delete"
+
+	return c.client.delete(ctx, endpoint, nil, nil, c.client.authenticator.reactionsAuth)
 }
 
 //Filter lists reactions based on the provided criteria and with the specified pagination.
 func (c *ReactionsClient) Filter(attr FilterReactionsAttribute, opts ...FilterReactionsOption) (*FilterReactionResponse, error) {
-	var endpointURI string
-
-	endpointURI = fmt.Sprintf("reaction/%s/", attr())
+	return c.FilterContext(context.Background(), attr, opts...)
+}
 
-	endpoint := c.client.makeEndpoint(endpointURI)
+// FilterContext is like Filter, using the provided context for the request.
+func (c *ReactionsClient) FilterContext(ctx context.Context, attr FilterReactionsAttribute, opts ...FilterReactionsOption) (*FilterReactionResponse, error) {
+	endpoint := c.filterEndpoint(attr)
 	for _, opt := range opts {
 		endpoint.addQueryParam(opt)
 	}
 
-	resp, err := c.client.get(endpoint, nil, c.client.authenticator.reactionsAuth)
-	if err != nil {
-		return nil, err
-	}
 	result := &FilterReactionResponse{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.get(ctx, endpoint, nil, result, c.client.authenticator.reactionsAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -110,11 +121,30 @@ func (c *ReactionsClient) Filter(attr FilterReactionsAttribute, opts ...FilterRe
 	return result, nil
 }
 
+func (c *ReactionsClient) filterEndpoint(attr FilterReactionsAttribute) endpoint {
+	endpoint := c.client.makeEndpoint(ResourceReactions, "reaction/%s/", attr())
+	endpoint.operation = "reactions.filter"
+	return endpoint
+}
+
 // GetNextPageFilteredReactions returns the reactions at the "next" page of a previous *FilterReactionResponse response, if any.
 func (c *ReactionsClient) GetNextPageFilteredReactions(resp *FilterReactionResponse) (*FilterReactionResponse, error) {
-	opts, err := resp.parseNext()
+	return c.GetNextPageFilteredReactionsContext(context.Background(), resp)
+}
+
+// GetNextPageFilteredReactionsContext is like GetNextPageFilteredReactions, using the provided context for the request.
+func (c *ReactionsClient) GetNextPageFilteredReactionsContext(ctx context.Context, resp *FilterReactionResponse) (*FilterReactionResponse, error) {
+	if resp.Next == "" {
+		return nil, ErrMissingNextPage
+	}
+	if resp.meta.attr == nil {
+		return nil, ErrInvalidNextPage
+	}
+	result := &FilterReactionResponse{}
+	err := c.client.getNextPage(ctx, c.filterEndpoint(resp.meta.attr), resp.Next, result, c.client.authenticator.reactionsAuth)
 	if err != nil {
 		return nil, err
 	}
-	return c.Filter(resp.meta.attr, opts...)
+	result.meta.attr = resp.meta.attr
+	return result, nil
 }
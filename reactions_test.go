@@ -157,7 +157,7 @@ func TestFilterReactions(t *testing.T) {
 func TestGetNextPageReactions(t *testing.T) {
 	client, requester := newClient(t)
 
-	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/upvote/?api_key=key&id_gt=uid1&limit=100&with_activity_data=true"}`
+	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100&with_activity_data=true"}`
 	resp, err := client.Reactions().Filter(stream.ByUserID("uid").ByKind("like"), stream.WithLimit(10), stream.WithActivityData(), stream.WithIDGT("id1"))
 	require.NoError(t, err)
 
@@ -165,7 +165,7 @@ func TestGetNextPageReactions(t *testing.T) {
 	testRequest(t, requester.req, http.MethodGet, "https://api.stream-io-api.com/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100&with_activity_data=true", "")
 	require.NoError(t, err)
 
-	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/upvote/?api_key=key&id_gt=uid1&limit=100&with_own_children=true"}`
+	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100&with_own_children=true"}`
 	resp, err = client.Reactions().Filter(stream.ByUserID("uid").ByKind("like"), stream.WithLimit(10), stream.WithActivityData(), stream.WithIDGT("id1"))
 	require.NoError(t, err)
 
@@ -173,14 +173,20 @@ func TestGetNextPageReactions(t *testing.T) {
 	testRequest(t, requester.req, http.MethodGet, "https://api.stream-io-api.com/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100&with_own_children=true", "")
 	require.NoError(t, err)
 
-	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/upvote/?api_key=key&id_gt=uid1&limit=100&with_activity_data=false"}`
+	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100&with_activity_data=false"}`
 	resp, err = client.Reactions().Filter(stream.ByUserID("uid").ByKind("like"), stream.WithLimit(10), stream.WithActivityData(), stream.WithIDGT("id1"))
 	require.NoError(t, err)
 
 	_, err = client.Reactions().GetNextPageFilteredReactions(resp)
-	testRequest(t, requester.req, http.MethodGet, "https://api.stream-io-api.com/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100", "")
+	testRequest(t, requester.req, http.MethodGet, "https://api.stream-io-api.com/api/v1.0/reaction/user_id/uid/like/?api_key=key&id_gt=uid1&limit=100&with_activity_data=false", "")
 	require.NoError(t, err)
 
+	requester.resp = `{"next":"/api/v1.0/reaction/user_id/uid/upvote/?id_gt=uid1"}`
+	resp, err = client.Reactions().Filter(stream.ByUserID("uid").ByKind("like"))
+	require.NoError(t, err)
+	_, err = client.Reactions().GetNextPageFilteredReactions(resp)
+	require.Error(t, err)
+
 	requester.resp = `{"next":"123"}`
 	resp, err = client.Reactions().Filter(stream.ByActivityID("aid"))
 	require.NoError(t, err)
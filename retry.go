@@ -0,0 +1,139 @@
+package stream
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+)
+
+// RetryPolicy configures how a Client retries failed API calls. Transport
+// errors, 429 (Too Many Requests) and 5xx responses are retried with a jittered
+// exponential backoff, waiting for the time suggested by the Retry-After header
+// when the API provides one, up to MaxDelay.
+//
+// Requests are only retried when it's safe to do so: reads, updates and
+// deletions always are, while writes such as adding activities are retried only
+// when the payload makes them idempotent (e.g. activities having both a foreign
+// ID and a time).
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for a single call, including
+	// the first one. Values lower than 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled at every following
+	// attempt. Defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts, including the one suggested
+	// by the Retry-After header. Defaults to 5s.
+	MaxDelay time.Duration
+}
+
+// WithRetryPolicy sets the retry policy for a given Client. By default, API
+// calls are attempted only once.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		if policy.BaseDelay <= 0 {
+			policy.BaseDelay = defaultRetryBaseDelay
+		}
+		if policy.MaxDelay <= 0 {
+			policy.MaxDelay = defaultRetryMaxDelay
+		}
+		c.retryPolicy = policy
+	}
+}
+
+// idempotentMethods are the HTTP methods which can always be safely retried.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// canRetry tells whether another attempt is allowed after the given (1-based)
+// attempt has failed.
+func (p RetryPolicy) canRetry(attempt int) bool {
+	return attempt < p.MaxAttempts
+}
+
+// backoff returns the delay to wait before the attempt following the given
+// (1-based) one, honoring the Retry-After header of the failed response, if
+// any, as long as it doesn't exceed the maximum delay.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if d > p.MaxDelay {
+				d = p.MaxDelay
+			}
+			return d
+		}
+	}
+	d := p.MaxDelay
+	if shift := uint(attempt - 1); shift < 32 {
+		if exp := p.BaseDelay << shift; exp > 0 && exp < p.MaxDelay {
+			d = exp
+		}
+	}
+	// equal jitter: wait at least half of the computed delay
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses a Retry-After header value, expressed either in
+// seconds or as an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(t)
+	if d < 0 {
+		d = 0
+	}
+	return d, true
+}
+
+// isTemporary tells whether a failed attempt, identified by its (possibly nil)
+// response, might succeed if performed again.
+func isTemporary(resp *http.Response) bool {
+	switch {
+	case resp == nil:
+		// transport failure
+		return true
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode/100 == 5:
+		return true
+	case resp.StatusCode/100 == 2:
+		// the response body could not be read
+		return true
+	}
+	return false
+}
+
+// sleepContext waits for the given duration, returning early with the context
+// error if the context is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
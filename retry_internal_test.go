@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{value: ""},
+		{value: "abc"},
+		{value: "-1"},
+		{value: "0", expected: 0, ok: true},
+		{value: "12", expected: 12 * time.Second, ok: true},
+		{value: "Mon, 02 Jan 2006 15:04:05 GMT", expected: 0, ok: true},
+	}
+	for _, tc := range testCases {
+		d, ok := parseRetryAfter(tc.value)
+		assert.Equal(t, tc.ok, ok, tc.value)
+		assert.Equal(t, tc.expected, d, tc.value)
+	}
+
+	d, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, float64(time.Hour), float64(d), float64(2*time.Second))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	testCases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 5, max: time.Second},
+		{attempt: 100, max: time.Second},
+	}
+	for _, tc := range testCases {
+		for i := 0; i < 20; i++ {
+			d := p.backoff(tc.attempt, nil)
+			assert.True(t, d >= tc.max/2 && d <= tc.max, "attempt %d: %s", tc.attempt, d)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	assert.Equal(t, time.Second, p.backoff(1, resp))
+	p.MaxDelay = 5 * time.Second
+	assert.Equal(t, 3*time.Second, p.backoff(1, resp))
+}
+
+func Test_isTemporary(t *testing.T) {
+	testCases := []struct {
+		resp     *http.Response
+		expected bool
+	}{
+		{resp: nil, expected: true},
+		{resp: &http.Response{StatusCode: http.StatusOK}, expected: true},
+		{resp: &http.Response{StatusCode: http.StatusTooManyRequests}, expected: true},
+		{resp: &http.Response{StatusCode: http.StatusInternalServerError}, expected: true},
+		{resp: &http.Response{StatusCode: http.StatusGatewayTimeout}, expected: true},
+		{resp: &http.Response{StatusCode: http.StatusBadRequest}, expected: false},
+		{resp: &http.Response{StatusCode: http.StatusNotFound}, expected: false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, isTemporary(tc.resp))
+	}
+}
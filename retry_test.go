@@ -0,0 +1,221 @@
+package stream_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scriptedResponse struct {
+	code   int
+	body   string
+	header http.Header
+	err    error
+	// readErr, if set, fails the reads of the body once it's been read.
+	readErr error
+}
+
+// scriptedRequester replays the given responses in order, recording the
+// received requests and their bodies.
+type scriptedRequester struct {
+	responses []scriptedResponse
+	reqs      []*http.Request
+	bodies    []string
+}
+
+func (r *scriptedRequester) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+	}
+	r.reqs = append(r.reqs, req)
+	r.bodies = append(r.bodies, string(body))
+	if len(r.responses) == 0 {
+		return nil, fmt.Errorf("no more responses")
+	}
+	next := r.responses[0]
+	r.responses = r.responses[1:]
+	if next.err != nil {
+		return nil, next.err
+	}
+	if next.body == "" {
+		next.body = "{}"
+	}
+	return &http.Response{
+		StatusCode: next.code,
+		Header:     next.header,
+		Body:       ioutil.NopCloser(io.MultiReader(bytes.NewBufferString(next.body), failingReader{next.readErr})),
+	}, nil
+}
+
+// failingReader fails every read with the given error, or reports EOF.
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
+
+func newRetryingClient(t *testing.T, requester stream.Requester, maxAttempts int) *stream.Client {
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRetryPolicy(stream.RetryPolicy{MaxAttempts: maxAttempts, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func TestRetryTemporaryFailures(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{err: fmt.Errorf("connection reset")},
+		{code: http.StatusServiceUnavailable, body: `{"detail":"unavailable"}`},
+		{code: http.StatusTooManyRequests, body: `{"detail":"slow down"}`, header: http.Header{"Retry-After": []string{"0"}}},
+		{code: http.StatusOK, body: `{"results":[{"id":"abc"}]}`},
+	}}
+	client := newRetryingClient(t, requester, 4)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "abc", resp.Results[0].ID)
+	require.Len(t, requester.reqs, 4)
+	for _, req := range requester.reqs {
+		assert.NotEmpty(t, req.Header.Get("Authorization"))
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusInternalServerError, body: `{"detail":"boom 1"}`},
+		{code: http.StatusInternalServerError, body: `{"detail":"boom 2"}`},
+		{code: http.StatusOK},
+	}}
+	client := newRetryingClient(t, requester, 2)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	_, err := flat.GetActivities()
+	require.Error(t, err)
+	assert.Equal(t, "boom 2", err.Error())
+	assert.Len(t, requester.reqs, 2)
+}
+
+func TestRetryClientErrorsAreNotRetried(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusBadRequest, body: `{"detail":"bad request"}`},
+		{code: http.StatusOK},
+	}}
+	client := newRetryingClient(t, requester, 3)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	_, err := flat.GetActivities()
+	require.Error(t, err)
+	assert.Len(t, requester.reqs, 1)
+}
+
+func TestRetryNonIdempotentWrites(t *testing.T) {
+	failures := func() []scriptedResponse {
+		return []scriptedResponse{
+			{code: http.StatusBadGateway, body: `{"detail":"bad gateway"}`},
+			{code: http.StatusOK},
+		}
+	}
+	activity := stream.Activity{Actor: "bob", Verb: "like", Object: "cake"}
+
+	requester := &scriptedRequester{responses: failures()}
+	client := newRetryingClient(t, requester, 3)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+	_, err := flat.AddActivity(activity)
+	require.Error(t, err)
+	assert.Len(t, requester.reqs, 1)
+
+	activity.ForeignID = "like:1"
+	activity.Time = stream.Time{Time: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)}
+	requester = &scriptedRequester{responses: failures()}
+	client = newRetryingClient(t, requester, 3)
+	flat, _ = newFlatFeedWithUserID(client, "123")
+	_, err = flat.AddActivity(activity)
+	require.NoError(t, err)
+	require.Len(t, requester.reqs, 2)
+	assert.Equal(t, requester.bodies[0], requester.bodies[1])
+
+	requester = &scriptedRequester{responses: failures()}
+	client = newRetryingClient(t, requester, 3)
+	_, err = client.Reactions().Add(stream.AddReactionRequestObject{Kind: "like", ActivityID: "a", UserID: "u"})
+	require.Error(t, err)
+	assert.Len(t, requester.reqs, 1)
+}
+
+func TestRetryHonorsContext(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusServiceUnavailable, body: `{"detail":"unavailable"}`, header: http.Header{"Retry-After": []string{"60"}}},
+		{code: http.StatusOK},
+	}}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRetryPolicy(stream.RetryPolicy{MaxAttempts: 3, MaxDelay: time.Minute}),
+	)
+	require.NoError(t, err)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = flat.GetActivitiesContext(ctx)
+	require.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, requester.reqs, 1)
+}
+
+func TestRetryCapsRetryAfter(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusServiceUnavailable, body: `{"detail":"unavailable"}`, header: http.Header{"Retry-After": []string{"3600"}}},
+		{code: http.StatusOK, body: `{"results":[{"id":"abc"}]}`},
+	}}
+	client := newRetryingClient(t, requester, 3)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := flat.GetActivitiesContext(ctx)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Len(t, requester.reqs, 2)
+}
+
+func TestRetryResponseDecoding(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK, body: `{"results":[{"id":"partial"},`, readErr: fmt.Errorf("connection reset")},
+		{code: http.StatusOK, body: `{"results":[{"id":"abc"}]}`},
+	}}
+	client := newRetryingClient(t, requester, 3)
+	flat, _ := newFlatFeedWithUserID(client, "123")
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "abc", resp.Results[0].ID)
+	assert.Len(t, requester.reqs, 2)
+
+	requester = &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusOK, body: `{"results":[{"id":42}]}`},
+		{code: http.StatusOK},
+	}}
+	client = newRetryingClient(t, requester, 3)
+	flat, _ = newFlatFeedWithUserID(client, "123")
+	_, err = flat.GetActivities()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot unmarshal response")
+	assert.Len(t, requester.reqs, 1)
+}
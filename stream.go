@@ -1,9 +1,5 @@
 package stream
 
-import (
-	"github.com/fatih/structs"
-)
-
 const (
 	// TimeLayout is the default time parse layout for Stream API JSON time fields
 	TimeLayout = "2006-01-02T15:04:05.999999"
@@ -16,7 +12,3 @@ var timeLayouts = []string{
 	ReactionTimeLayout,
 	"2006-01-02 15:04:05.999999-07:00",
 }
-
-func init() {
-	structs.DefaultTagName = "json"
-}
@@ -0,0 +1,352 @@
+package streamtest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	stream "github.com/GetStream/stream-go2"
+)
+
+// Redacted is the value replacing credentials in recorded interactions.
+const Redacted = "REDACTED"
+
+// jwtPattern matches JSON Web Tokens.
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// Interaction is a request/response pair recorded in a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded HTTP request, with its credentials redacted.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   body   `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       body        `json:"body,omitempty"`
+}
+
+// body is a request or response body. JSON bodies are encoded as they are, so
+// that cassettes are easy to read and edit, and any other body is encoded as
+// a JSON string.
+type body []byte
+
+func (b body) MarshalJSON() ([]byte, error) {
+	if json.Valid(b) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return json.Marshal(string(b))
+}
+
+func (b *body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil && !json.Valid([]byte(s)) {
+		*b = body(s)
+		return nil
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+// Cassette is a stream.Requester which either records the requests sent by a
+// client, along with their responses, to a file, or replays the interactions
+// previously recorded there. It's safe for concurrent use.
+//
+// Cassettes are stored as newline-delimited JSON, one Interaction per line.
+// The API key and any JSON Web Token are redacted before being written.
+type Cassette struct {
+	mu        sync.Mutex
+	requester stream.Requester
+	file      *os.File
+	recorded  []*Interaction
+	used      []bool
+}
+
+var _ stream.Requester = (*Cassette)(nil)
+
+// Record returns a Cassette sending requests with the given requester, or
+// with http.DefaultClient if nil, and recording them to the file at the given
+// path, which is truncated. The cassette must be closed when done.
+func Record(path string, requester stream.Requester) (*Cassette, error) {
+	if requester == nil {
+		requester = http.DefaultClient
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create cassette: %s", err)
+	}
+	return &Cassette{requester: requester, file: f}, nil
+}
+
+// Replay returns a Cassette serving requests with the interactions recorded
+// in the file at the given path.
+//
+// A request is served with the first unused interaction matching its method,
+// path, query and body, so that identical requests are replayed in the order
+// they were recorded. Query parameters are compared regardless of their order
+// and JSON bodies regardless of their formatting and key order. The API key
+// and the signatures of the requests are ignored.
+func Replay(path string) (*Cassette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open cassette: %s", err)
+	}
+	defer f.Close()
+
+	c := &Cassette{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("cannot decode interaction at line %d: %s", line, err)
+		}
+		c.recorded = append(c.recorded, &i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read cassette: %s", err)
+	}
+	c.used = make([]bool, len(c.recorded))
+	return c, nil
+}
+
+// Interactions returns the interactions recorded or loaded by the cassette.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]Interaction, len(c.recorded))
+	for i, rec := range c.recorded {
+		out[i] = *rec
+	}
+	return out
+}
+
+// Close closes the file of a recording cassette. It's a no-op for replaying
+// ones.
+func (c *Cassette) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// Do records or replays the given request.
+func (c *Cassette) Do(r *http.Request) (*http.Response, error) {
+	if err := r.Context().Err(); err != nil {
+		return nil, err
+	}
+	req, err := recordRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	if c.requester == nil {
+		return c.replay(r, req)
+	}
+	return c.record(r, req)
+}
+
+func (c *Cassette) record(r *http.Request, req RecordedRequest) (*http.Response, error) {
+	resp, err := c.requester.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	// compressed responses are recorded decompressed, to keep cassettes
+	// readable
+	header := resp.Header
+	if decoded, ok := decompress(data, header); ok {
+		data = decoded
+		header = header.Clone()
+		header.Del("Content-Encoding")
+	}
+	i := &Interaction{
+		Request: req,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       redactBody(data),
+		},
+	}
+	line, err := json.Marshal(i)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode interaction: %s", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.file == nil {
+		return nil, fmt.Errorf("cassette is closed")
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("cannot write interaction: %s", err)
+	}
+	c.recorded = append(c.recorded, i)
+	return resp, nil
+}
+
+func (c *Cassette) replay(r *http.Request, req RecordedRequest) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n, i := range c.recorded {
+		if c.used[n] || !i.Request.matches(req) {
+			continue
+		}
+		c.used[n] = true
+		header := make(http.Header, len(i.Response.Header))
+		for k, v := range i.Response.Header {
+			header[k] = append([]string(nil), v...)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+			StatusCode:    i.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader(i.Response.Body)),
+			ContentLength: int64(len(i.Response.Body)),
+			Request:       r,
+		}, nil
+	}
+	return nil, fmt.Errorf("no recorded interaction matches %s %s", req.Method, req.url())
+}
+
+// recordRequest returns the redacted recording of the given request, leaving
+// its body readable.
+func recordRequest(r *http.Request) (RecordedRequest, error) {
+	req := RecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  redactQuery(r.URL.Query()).Encode(),
+	}
+	if r.Body != nil {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return req, fmt.Errorf("cannot read request body: %s", err)
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		if decoded, ok := decompress(data, r.Header); ok {
+			data = decoded
+		}
+		req.Body = redactBody(data)
+	}
+	return req, nil
+}
+
+// decompress returns the given gzip-encoded body decompressed, and whether it
+// was.
+func decompress(data []byte, header http.Header) ([]byte, bool) {
+	if header.Get("Content-Encoding") != "gzip" {
+		return nil, false
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	decoded, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// matches tells whether the given request is the same as the recorded one.
+func (r RecordedRequest) matches(other RecordedRequest) bool {
+	return r.Method == other.Method &&
+		r.Path == other.Path &&
+		normalizeQuery(r.Query) == normalizeQuery(other.Query) &&
+		bytes.Equal(normalizeBody(r.Body), normalizeBody(other.Body))
+}
+
+func (r RecordedRequest) url() string {
+	if r.Query == "" {
+		return r.Path
+	}
+	return r.Path + "?" + r.Query
+}
+
+// redactQuery replaces the API key and any JWT held by the given query
+// parameters.
+func redactQuery(query url.Values) url.Values {
+	for k, values := range query {
+		for i, v := range values {
+			if strings.EqualFold(k, "api_key") || strings.EqualFold(k, "authorization") {
+				values[i] = Redacted
+			} else {
+				values[i] = jwtPattern.ReplaceAllString(v, Redacted)
+			}
+		}
+	}
+	return query
+}
+
+// redactBody replaces any JWT held by the given body.
+func redactBody(data []byte) body {
+	if len(data) == 0 {
+		return nil
+	}
+	return jwtPattern.ReplaceAll(data, []byte(Redacted))
+}
+
+// normalizeQuery returns the given encoded query with its parameters sorted,
+// ignoring the API key.
+func normalizeQuery(raw string) string {
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		return raw
+	}
+	query.Del("api_key")
+	return query.Encode()
+}
+
+// normalizeBody returns the given body re-encoded with sorted keys and no
+// whitespace if it holds JSON, or as it is otherwise.
+func normalizeBody(b body) []byte {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return b
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return b
+	}
+	return data
+}
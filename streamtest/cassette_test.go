@@ -0,0 +1,121 @@
+package streamtest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "feeds.ndjson")
+
+	srv := streamtest.NewServer()
+	recorder, err := streamtest.Record(path, nil)
+	require.NoError(t, err)
+	client, err := srv.NewClient(stream.WithHTTPRequester(recorder))
+	require.NoError(t, err)
+
+	flat, err := client.FlatFeed("user", "alice")
+	require.NoError(t, err)
+	empty, err := flat.GetEnrichedActivities()
+	require.NoError(t, err)
+	added, err := flat.AddActivity(stream.Activity{Actor: "SU:alice", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+	_, err = client.Reactions().Add(stream.AddReactionRequestObject{Kind: "like", ActivityID: added.ID, UserID: "bob"})
+	require.NoError(t, err)
+	recorded, err := flat.GetEnrichedActivities(stream.WithEnrichReactionCounts())
+	require.NoError(t, err)
+	reactions, err := client.Reactions().Filter(stream.ByActivityID(added.ID))
+	require.NoError(t, err)
+	require.NoError(t, recorder.Close())
+	srv.Close()
+	assert.Len(t, recorder.Interactions(), 5)
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "api_key=key")
+	assert.NotContains(t, string(data), "eyJ")
+	assert.Contains(t, string(data), "api_key=REDACTED")
+
+	player, err := streamtest.Replay(path)
+	require.NoError(t, err)
+	assert.Len(t, player.Interactions(), 5)
+	client, err = stream.NewClient("another-key", "another-secret",
+		stream.WithBaseURL(srv.URL()),
+		stream.WithHTTPRequester(player),
+	)
+	require.NoError(t, err)
+
+	flat, err = client.FlatFeed("user", "alice")
+	require.NoError(t, err)
+	resp, err := flat.GetEnrichedActivities()
+	require.NoError(t, err)
+	assert.Equal(t, empty.Results, resp.Results)
+	// identical requests are served in the order they were recorded
+	_, err = flat.GetEnrichedActivities()
+	assert.Error(t, err)
+
+	replayed, err := client.Reactions().Filter(stream.ByActivityID(added.ID))
+	require.NoError(t, err)
+	assert.Equal(t, reactions.Results, replayed.Results)
+	resp, err = flat.GetEnrichedActivities(stream.WithEnrichReactionCounts())
+	require.NoError(t, err)
+	assert.Equal(t, recorded.Results, resp.Results)
+	assert.Equal(t, 1, resp.Results[0].ReactionCounts["like"])
+
+	_, err = client.Reactions().Filter(stream.ByActivityID("missing"))
+	assert.Error(t, err)
+}
+
+func TestCassetteCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "feeds.ndjson")
+
+	srv := streamtest.NewServer()
+	defer srv.Close()
+	recorder, err := streamtest.Record(path, nil)
+	require.NoError(t, err)
+	client, err := srv.NewClient(
+		stream.WithHTTPRequester(recorder),
+		stream.WithCompression(stream.CompressionConfig{Threshold: 1}),
+	)
+	require.NoError(t, err)
+	flat, err := client.FlatFeed("user", "alice")
+	require.NoError(t, err)
+	_, err = flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+	recorded, err := flat.GetActivities()
+	require.NoError(t, err)
+	require.NoError(t, recorder.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"picture:1"`)
+	assert.NotContains(t, string(data), `"Content-Encoding"`)
+
+	player, err := streamtest.Replay(path)
+	require.NoError(t, err)
+	client, err = stream.NewClient("key", "secret",
+		stream.WithBaseURL(srv.URL()),
+		stream.WithHTTPRequester(player),
+	)
+	require.NoError(t, err)
+	flat, err = client.FlatFeed("user", "alice")
+	require.NoError(t, err)
+	_, err = flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, recorded.Results, resp.Results)
+}
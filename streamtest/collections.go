@@ -0,0 +1,215 @@
+package streamtest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// object is a collection object or a user.
+type object struct {
+	id         string
+	collection string
+	data       fields
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+func (o *object) toJSON() fields {
+	data := o.data
+	if data == nil {
+		data = fields{}
+	}
+	out := fields{
+		"id":         o.id,
+		"data":       data,
+		"created_at": formatTimestamp(o.createdAt),
+		"updated_at": formatTimestamp(o.updatedAt),
+	}
+	if o.collection != "" {
+		out["collection"] = o.collection
+		out["foreign_id"] = fmt.Sprintf("%s:%s", o.collection, o.id)
+	}
+	return out
+}
+
+func (s *Server) collection(name string) map[string]*object {
+	c, ok := s.collections[name]
+	if !ok {
+		c = make(map[string]*object)
+		s.collections[name] = c
+	}
+	return c
+}
+
+func (s *Server) upsertObjects(r *request) (fields, error) {
+	var body struct {
+		Data map[string][]fields `json:"data"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	for _, objects := range body.Data {
+		for _, obj := range objects {
+			if id, _ := obj["id"].(string); id == "" {
+				return nil, invalidInput("id is a required field")
+			}
+		}
+	}
+	now := s.currentTime()
+	results := make(map[string][]fields)
+	for name, objects := range body.Data {
+		c := s.collection(name)
+		for _, obj := range objects {
+			id := obj["id"].(string)
+			data := make(fields, len(obj))
+			for k, v := range obj {
+				if k != "id" {
+					data[k] = v
+				}
+			}
+			o, ok := c[id]
+			if !ok {
+				o = &object{id: id, collection: name, createdAt: now}
+				c[id] = o
+			}
+			o.data = data
+			o.updatedAt = now
+			results[name] = append(results[name], o.toJSON())
+		}
+	}
+	return fields{"data": results}, nil
+}
+
+func (s *Server) selectObjects(r *request) (fields, error) {
+	var results []fields
+	for _, foreignID := range splitList(r.query.Get("foreign_ids")) {
+		parts := strings.SplitN(foreignID, ":", 2)
+		if len(parts) != 2 {
+			return nil, invalidInput("invalid foreign ID %q", foreignID)
+		}
+		if o, ok := s.collections[parts[0]][parts[1]]; ok {
+			results = append(results, o.toJSON())
+		}
+	}
+	return fields{"response": fields{"data": results}}, nil
+}
+
+func (s *Server) deleteObjects(r *request) (fields, error) {
+	name := r.query.Get("collection_name")
+	if name == "" {
+		return nil, invalidInput("collection_name is a required parameter")
+	}
+	for _, id := range splitList(r.query.Get("ids")) {
+		delete(s.collections[name], id)
+	}
+	return fields{}, nil
+}
+
+func (s *Server) addObject(r *request) (fields, error) {
+	var body struct {
+		ID   string `json:"id"`
+		Data fields `json:"data"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	now := s.currentTime()
+	c := s.collection(r.params[0])
+	if body.ID == "" {
+		body.ID = s.newID(now)
+	}
+	if _, ok := c[body.ID]; ok {
+		return nil, conflict("object %q already exists in collection %q", body.ID, r.params[0])
+	}
+	o := &object{id: body.ID, collection: r.params[0], data: body.Data, createdAt: now, updatedAt: now}
+	c[o.id] = o
+	return o.toJSON(), nil
+}
+
+func (s *Server) getObject(r *request) (fields, error) {
+	o, ok := s.collections[r.params[0]][r.params[1]]
+	if !ok {
+		return nil, notFound("object %q not found in collection %q", r.params[1], r.params[0])
+	}
+	return o.toJSON(), nil
+}
+
+func (s *Server) updateObject(r *request) (fields, error) {
+	o, ok := s.collections[r.params[0]][r.params[1]]
+	if !ok {
+		return nil, notFound("object %q not found in collection %q", r.params[1], r.params[0])
+	}
+	var body struct {
+		Data fields `json:"data"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	o.data = body.Data
+	o.updatedAt = s.currentTime()
+	return o.toJSON(), nil
+}
+
+func (s *Server) deleteObject(r *request) (fields, error) {
+	if _, ok := s.collections[r.params[0]][r.params[1]]; !ok {
+		return nil, notFound("object %q not found in collection %q", r.params[1], r.params[0])
+	}
+	delete(s.collections[r.params[0]], r.params[1])
+	return fields{}, nil
+}
+
+func (s *Server) addUser(r *request) (fields, error) {
+	var body struct {
+		ID   string `json:"id"`
+		Data fields `json:"data"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.ID == "" {
+		return nil, invalidInput("id is a required field")
+	}
+	if u, ok := s.users[body.ID]; ok {
+		if r.boolParam("get_or_create") {
+			return u.toJSON(), nil
+		}
+		return nil, conflict("user %q already exists", body.ID)
+	}
+	now := s.currentTime()
+	u := &object{id: body.ID, data: body.Data, createdAt: now, updatedAt: now}
+	s.users[u.id] = u
+	return u.toJSON(), nil
+}
+
+func (s *Server) getUser(r *request) (fields, error) {
+	u, ok := s.users[r.params[0]]
+	if !ok {
+		return nil, notFound("user %q not found", r.params[0])
+	}
+	return u.toJSON(), nil
+}
+
+func (s *Server) updateUser(r *request) (fields, error) {
+	u, ok := s.users[r.params[0]]
+	if !ok {
+		return nil, notFound("user %q not found", r.params[0])
+	}
+	var body struct {
+		Data fields `json:"data"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	u.data = body.Data
+	u.updatedAt = s.currentTime()
+	return u.toJSON(), nil
+}
+
+func (s *Server) deleteUser(r *request) (fields, error) {
+	if _, ok := s.users[r.params[0]]; !ok {
+		return nil, notFound("user %q not found", r.params[0])
+	}
+	delete(s.users, r.params[0])
+	return fields{}, nil
+}
@@ -0,0 +1,103 @@
+package streamtest
+
+import "strings"
+
+// notEnriched are the activity fields which never hold references.
+var notEnriched = map[string]bool{
+	"id":         true,
+	"foreign_id": true,
+	"time":       true,
+	"verb":       true,
+	"to":         true,
+	"origin":     true,
+}
+
+// resolve returns the object referenced by a "SU:<user_id>",
+// "SO:<collection>:<id>" or "SR:<reaction_id>" reference, or nil if the
+// reference is invalid or points to a missing object.
+func (s *Server) resolve(ref string) fields {
+	switch {
+	case strings.HasPrefix(ref, "SU:"):
+		if u, ok := s.users[ref[3:]]; ok {
+			return u.toJSON()
+		}
+	case strings.HasPrefix(ref, "SO:"):
+		parts := strings.SplitN(ref[3:], ":", 2)
+		if len(parts) != 2 {
+			return nil
+		}
+		if o, ok := s.collections[parts[0]][parts[1]]; ok {
+			return o.toJSON()
+		}
+	case strings.HasPrefix(ref, "SR:"):
+		if rc, ok := s.reactions[ref[3:]]; ok {
+			return s.reactionJSON(rc, "")
+		}
+	}
+	return nil
+}
+
+// resolveReferences replaces the references held by the activity fields with
+// the referenced objects. References to missing objects are left unchanged.
+func (s *Server) resolveReferences(a fields) fields {
+	for k, v := range a {
+		if ref, ok := v.(string); ok && !notEnriched[k] {
+			if obj := s.resolve(ref); obj != nil {
+				a[k] = obj
+			}
+		}
+	}
+	return a
+}
+
+// enrichActivity resolves the references held by the activity and adds its
+// reactions and their counts, according to the request parameters.
+func (s *Server) enrichActivity(a fields, r *request) {
+	id, _ := a["id"].(string)
+	s.resolveReferences(a)
+
+	kinds := splitList(r.query.Get("reactionKindsFilter"))
+	reactions := s.listReactions(func(rc *reaction) bool {
+		return rc.activityID == id && rc.parentID == "" && (len(kinds) == 0 || contains(kinds, rc.kind))
+	})
+	limit, err := r.intParam("recentReactionsLimit", defaultReactionLimit, maxReactionLimit)
+	if err != nil {
+		limit = defaultReactionLimit
+	}
+	userID := r.query.Get("user_id")
+	var ownUserID string
+	if r.boolParam("withOwnChildren") {
+		ownUserID = userID
+	}
+
+	counts := make(map[string]int)
+	latest := make(map[string][]fields)
+	own := make(map[string][]fields)
+	for _, rc := range reactions {
+		counts[rc.kind]++
+		if len(latest[rc.kind]) < limit {
+			latest[rc.kind] = append(latest[rc.kind], s.enrichedReactionJSON(rc, ownUserID))
+		}
+		if userID != "" && rc.userID == userID {
+			own[rc.kind] = append(own[rc.kind], s.enrichedReactionJSON(rc, ownUserID))
+		}
+	}
+	if r.boolParam("withReactionCounts") {
+		a["reaction_counts"] = counts
+	}
+	if r.boolParam("withRecentReactions") {
+		a["latest_reactions"] = latest
+	}
+	if r.boolParam("withOwnReactions") {
+		a["own_reactions"] = own
+	}
+}
+
+// enrichedReactionJSON returns a reaction along with its user, if it exists.
+func (s *Server) enrichedReactionJSON(rc *reaction, ownUserID string) fields {
+	out := s.reactionJSON(rc, ownUserID)
+	if u, ok := s.users[rc.userID]; ok {
+		out["user"] = u.toJSON()
+	}
+	return out
+}
@@ -0,0 +1,924 @@
+package streamtest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	slugRegex   = regexp.MustCompile(`^\w+$`)
+	userIDRegex = regexp.MustCompile(`^[\w-]+$`)
+)
+
+// activity is a stored activity, shared by all the feeds it was added or
+// propagated to.
+type activity struct {
+	id   string
+	time time.Time
+	// fields are the activity fields but the ID and time.
+	fields fields
+}
+
+func (a *activity) foreignID() string {
+	s, _ := a.fields["foreign_id"].(string)
+	return s
+}
+
+// toJSON returns the activity as it appears in a feed, where it might have
+// been propagated from the given origin feed.
+func (a *activity) toJSON(origin string) fields {
+	out := make(fields, len(a.fields)+3)
+	for k, v := range a.fields {
+		out[k] = v
+	}
+	out["id"] = a.id
+	out["time"] = formatTime(a.time)
+	if origin != "" {
+		out["origin"] = origin
+	}
+	return out
+}
+
+type feed struct {
+	id  string
+	typ FeedType
+	// entries are the activities in the feed, newest first.
+	entries []entry
+	// groups are the aggregation groups of the feed, by group key.
+	groups map[string]*group
+}
+
+type entry struct {
+	id string
+	// origin is the ID of the feed the activity was propagated from, if any.
+	origin string
+	// copied tells whether the activity was received from a followed feed.
+	copied bool
+}
+
+type group struct {
+	id   string
+	seen bool
+	read bool
+}
+
+func (f *feed) index(id string) int {
+	for i, e := range f.entries {
+		if e.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+type follow struct {
+	source    string
+	target    string
+	createdAt time.Time
+}
+
+func (f *follow) toJSON() fields {
+	return fields{
+		"feed_id":    f.source,
+		"target_id":  f.target,
+		"created_at": formatTimestamp(f.createdAt),
+		"updated_at": formatTimestamp(f.createdAt),
+	}
+}
+
+// feed returns the feed having the given ID, in the slug:user_id form.
+func (s *Server) feed(id string) (*feed, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 {
+		return nil, invalidInput("invalid feed ID %q", id)
+	}
+	return s.feedFromPath(parts[0], parts[1])
+}
+
+func (s *Server) feedFromPath(slug, userID string) (*feed, error) {
+	if !slugRegex.MatchString(slug) {
+		return nil, invalidInput("invalid feed slug %q", slug)
+	}
+	if !userIDRegex.MatchString(userID) {
+		return nil, invalidInput("invalid feed user ID %q", userID)
+	}
+	id := fmt.Sprintf("%s:%s", slug, userID)
+	f, ok := s.feeds[id]
+	if !ok {
+		f = &feed{id: id, typ: s.groups[slug], groups: make(map[string]*group)}
+		s.feeds[id] = f
+	}
+	return f, nil
+}
+
+// parseActivity validates the given activity payload, returning an activity
+// which is not stored yet.
+func (s *Server) parseActivity(data fields) (*activity, error) {
+	for _, k := range []string{"actor", "verb", "object"} {
+		if v, _ := data[k].(string); v == "" {
+			return nil, invalidInput("%s is a required field", k)
+		}
+	}
+	for _, target := range stringList(data["to"]) {
+		if _, err := s.feed(target); err != nil {
+			return nil, err
+		}
+	}
+	a := &activity{time: s.currentTime(), fields: make(fields, len(data))}
+	if v, ok := data["time"]; ok {
+		t, err := parseTime(v)
+		if err != nil {
+			return nil, invalidInput("invalid activity time: %s", err)
+		}
+		a.time = t
+	}
+	for k, v := range data {
+		if k != "id" && k != "time" {
+			a.fields[k] = v
+		}
+	}
+	return a, nil
+}
+
+// storeActivity stores a parsed activity, returning it. Activities having the
+// same foreign ID and time as a stored one replace it, keeping its ID.
+func (s *Server) storeActivity(a *activity) *activity {
+	if existing := s.findByForeignID(a.foreignID(), a.time); existing != nil {
+		existing.fields = a.fields
+		return existing
+	}
+	a.id = s.newID(a.time)
+	s.activities[a.id] = a
+	return a
+}
+
+func (s *Server) findByForeignID(foreignID string, t time.Time) *activity {
+	if foreignID == "" {
+		return nil
+	}
+	for _, a := range s.activities {
+		if a.foreignID() == foreignID && a.time.Equal(t) {
+			return a
+		}
+	}
+	return nil
+}
+
+// compareActivities compares the position of two activities in feeds,
+// returning a negative number if a is older than b.
+func (s *Server) compareActivities(a, b string) int {
+	x, y := s.activities[a], s.activities[b]
+	if x != nil && y != nil && !x.time.Equal(y.time) {
+		if x.time.Before(y.time) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// distribute adds an activity to a feed, to its "to" targets and to the
+// followers of both.
+func (s *Server) distribute(f *feed, a *activity) {
+	s.insert(f, entry{id: a.id})
+	s.fanOut(f, a.id)
+	for _, target := range stringList(a.fields["to"]) {
+		s.deliver(f, target, a.id)
+	}
+}
+
+// deliver adds an activity from a feed to one of its "to" targets, and to the
+// followers of the target.
+func (s *Server) deliver(f *feed, target, id string) {
+	tf, err := s.feed(target)
+	if err != nil || tf == f {
+		return
+	}
+	s.insert(tf, entry{id: id, origin: f.id})
+	s.fanOut(tf, id)
+}
+
+func (s *Server) fanOut(f *feed, id string) {
+	for _, follower := range s.followers(f.id) {
+		s.insert(follower, entry{id: id, origin: f.id, copied: true})
+	}
+}
+
+func (s *Server) insert(f *feed, e entry) {
+	if f.index(e.id) >= 0 {
+		return
+	}
+	f.entries = append(f.entries, e)
+	sort.SliceStable(f.entries, func(i, j int) bool {
+		return s.compareActivities(f.entries[i].id, f.entries[j].id) > 0
+	})
+	if f.typ != FlatFeed {
+		g := s.group(f, groupKey(s.activities[e.id]))
+		g.seen, g.read = false, false
+	}
+}
+
+// remove removes an activity from a feed and from every feed it was
+// propagated to from there.
+func (s *Server) remove(f *feed, id string) {
+	i := f.index(id)
+	if i < 0 {
+		return
+	}
+	f.entries = append(f.entries[:i], f.entries[i+1:]...)
+	for _, other := range s.feeds {
+		if j := other.index(id); j >= 0 && other.entries[j].origin == f.id {
+			s.remove(other, id)
+		}
+	}
+	for _, other := range s.feeds {
+		if other.index(id) >= 0 {
+			return
+		}
+	}
+	delete(s.activities, id)
+}
+
+func (s *Server) followers(id string) []*feed {
+	var feeds []*feed
+	for _, f := range s.follows {
+		if f.target == id {
+			feeds = append(feeds, s.feeds[f.source])
+		}
+	}
+	return feeds
+}
+
+func (s *Server) isFollowing(source, target string) bool {
+	for _, f := range s.follows {
+		if f.source == source && f.target == target {
+			return true
+		}
+	}
+	return false
+}
+
+// addFollow makes source follow target, copying up to copyLimit of the target
+// activities.
+func (s *Server) addFollow(source, target *feed, copyLimit int) error {
+	if source == target {
+		return invalidInput("feed %s cannot follow itself", source.id)
+	}
+	if !s.isFollowing(source.id, target.id) {
+		s.follows = append(s.follows, &follow{source: source.id, target: target.id, createdAt: s.currentTime()})
+	}
+	var copied int
+	for _, e := range append([]entry(nil), target.entries...) {
+		if copied >= copyLimit {
+			break
+		}
+		if e.copied {
+			continue
+		}
+		s.insert(source, entry{id: e.id, origin: target.id, copied: true})
+		copied++
+	}
+	return nil
+}
+
+func (s *Server) removeFollow(source, target *feed, keepHistory bool) {
+	for i, f := range s.follows {
+		if f.source == source.id && f.target == target.id {
+			s.follows = append(s.follows[:i], s.follows[i+1:]...)
+			break
+		}
+	}
+	if keepHistory {
+		return
+	}
+	for _, e := range append([]entry(nil), source.entries...) {
+		if e.copied && e.origin == target.id {
+			s.remove(source, e.id)
+		}
+	}
+}
+
+func (s *Server) addActivities(r *request) (fields, error) {
+	f, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	var body fields
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	items, batch := body["activities"].([]interface{})
+	if !batch {
+		a, err := s.parseActivity(body)
+		if err != nil {
+			return nil, err
+		}
+		a = s.storeActivity(a)
+		s.distribute(f, a)
+		return a.toJSON(""), nil
+	}
+
+	parsed := make([]*activity, len(items))
+	for i, item := range items {
+		data, _ := item.(map[string]interface{})
+		a, err := s.parseActivity(data)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = a
+	}
+	results := make([]fields, len(parsed))
+	for i, a := range parsed {
+		a = s.storeActivity(a)
+		s.distribute(f, a)
+		results[i] = a.toJSON("")
+	}
+	return fields{"activities": results}, nil
+}
+
+func (s *Server) addToMany(r *request) (fields, error) {
+	var body struct {
+		Activity fields   `json:"activity"`
+		Feeds    []string `json:"feeds"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	feeds := make([]*feed, len(body.Feeds))
+	for i, id := range body.Feeds {
+		f, err := s.feed(id)
+		if err != nil {
+			return nil, err
+		}
+		feeds[i] = f
+	}
+	a, err := s.parseActivity(body.Activity)
+	if err != nil {
+		return nil, err
+	}
+	a = s.storeActivity(a)
+	for _, f := range feeds {
+		s.distribute(f, a)
+	}
+	return fields{}, nil
+}
+
+func (s *Server) removeActivity(r *request) (fields, error) {
+	f, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	id := r.params[2]
+	if r.boolParam("foreign_id") {
+		for _, e := range f.entries {
+			if s.activities[e.id].foreignID() == id {
+				s.remove(f, e.id)
+				break
+			}
+		}
+	} else {
+		s.remove(f, id)
+	}
+	return fields{"removed": id}, nil
+}
+
+func (s *Server) getActivities(r *request) (fields, error) {
+	return s.readFeed(r, false)
+}
+
+func (s *Server) getEnrichedActivities(r *request) (fields, error) {
+	return s.readFeed(r, true)
+}
+
+func (s *Server) readFeed(r *request, enrich bool) (fields, error) {
+	f, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	limit, err := r.intParam("limit", defaultLimit, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := r.intParam("offset", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	render := func(e entry) fields {
+		a := s.activities[e.id].toJSON(e.origin)
+		if enrich {
+			s.enrichActivity(a, r)
+		}
+		return a
+	}
+	if f.typ == FlatFeed {
+		return s.readFlatFeed(r, f, limit, offset, render), nil
+	}
+	return s.readAggregatedFeed(r, f, limit, offset, render), nil
+}
+
+func (s *Server) readFlatFeed(r *request, f *feed, limit, offset int, render func(entry) fields) fields {
+	entries := s.filterEntries(f.entries, r)
+	start, end, more := window(len(entries), offset, limit)
+	results := make([]fields, 0, end-start)
+	for _, e := range entries[start:end] {
+		results = append(results, render(e))
+	}
+	var next string
+	if more && end > start {
+		next = r.next("limit", fmt.Sprint(limit), "id_lt", entries[end-1].id)
+	}
+	return fields{"results": results, "next": next}
+}
+
+// filterEntries applies the id_lt, id_lte, id_gt and id_gte parameters to the
+// given entries.
+func (s *Server) filterEntries(entries []entry, r *request) []entry {
+	filtered := entries
+	for param, keep := range rangeParams {
+		ref := r.query.Get(param)
+		if ref == "" {
+			continue
+		}
+		var kept []entry
+		for _, e := range filtered {
+			if keep(s.compareActivities(e.id, ref)) {
+				kept = append(kept, e)
+			}
+		}
+		filtered = kept
+	}
+	return filtered
+}
+
+type groupView struct {
+	key     string
+	state   *group
+	entries []entry
+}
+
+func groupKey(a *activity) string {
+	return fmt.Sprintf("%v_%s", a.fields["verb"], a.time.Format("2006-01-02"))
+}
+
+func (s *Server) group(f *feed, key string) *group {
+	g, ok := f.groups[key]
+	if !ok {
+		g = &group{id: s.newID(s.currentTime())}
+		f.groups[key] = g
+	}
+	return g
+}
+
+// aggregate groups the activities of a feed, most recently updated group
+// first.
+func (s *Server) aggregate(f *feed) []*groupView {
+	var views []*groupView
+	byKey := make(map[string]*groupView)
+	for _, e := range f.entries {
+		key := groupKey(s.activities[e.id])
+		v, ok := byKey[key]
+		if !ok {
+			v = &groupView{key: key, state: s.group(f, key)}
+			byKey[key] = v
+			views = append(views, v)
+		}
+		v.entries = append(v.entries, e)
+	}
+	return views
+}
+
+func (s *Server) readAggregatedFeed(r *request, f *feed, limit, offset int, render func(entry) fields) fields {
+	all := s.aggregate(f)
+	groups := all
+	if ref := r.query.Get("id_lt"); ref != "" {
+		for i, g := range groups {
+			if g.state.id == ref {
+				groups = groups[i+1:]
+				break
+			}
+		}
+	}
+	start, end, more := window(len(groups), offset, limit)
+	results := make([]fields, 0, end-start)
+	for _, g := range groups[start:end] {
+		activities := make([]fields, len(g.entries))
+		actors := make(map[interface{}]bool)
+		for i, e := range g.entries {
+			activities[i] = render(e)
+			actors[s.activities[e.id].fields["actor"]] = true
+		}
+		first, last := s.activities[g.entries[len(g.entries)-1].id], s.activities[g.entries[0].id]
+		result := fields{
+			"id":             g.state.id,
+			"group":          g.key,
+			"verb":           last.fields["verb"],
+			"activities":     activities,
+			"activity_count": len(g.entries),
+			"actor_count":    len(actors),
+			"created_at":     formatTime(first.time),
+			"updated_at":     formatTime(last.time),
+		}
+		if f.typ == NotificationFeed {
+			result["is_seen"] = g.state.seen
+			result["is_read"] = g.state.read
+		}
+		results = append(results, result)
+	}
+	resp := fields{"results": results, "next": ""}
+	if more && end > start {
+		resp["next"] = r.next("limit", fmt.Sprint(limit), "id_lt", groups[end-1].state.id)
+	}
+	if f.typ != NotificationFeed {
+		return resp
+	}
+
+	// the counters and the returned groups reflect the state before marking
+	var unseen, unread int
+	for _, g := range all {
+		if !g.state.seen {
+			unseen++
+		}
+		if !g.state.read {
+			unread++
+		}
+	}
+	resp["unseen"] = unseen
+	resp["unread"] = unread
+	mark := func(param string, set func(*group)) {
+		everything := r.boolParam(param)
+		ids := splitList(r.query.Get(param))
+		for _, g := range all {
+			if everything || contains(ids, g.state.id) {
+				set(g.state)
+			}
+		}
+	}
+	mark("mark_seen", func(g *group) { g.seen = true })
+	mark("mark_read", func(g *group) { g.read = true })
+	return resp
+}
+
+func (s *Server) follow(r *request) (fields, error) {
+	source, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		Target            string `json:"target"`
+		ActivityCopyLimit *int   `json:"activity_copy_limit"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	target, err := s.feed(body.Target)
+	if err != nil {
+		return nil, err
+	}
+	copyLimit, err := activityCopyLimit(body.ActivityCopyLimit, defaultCopyLimit)
+	if err != nil {
+		return nil, err
+	}
+	return fields{}, s.addFollow(source, target, copyLimit)
+}
+
+func activityCopyLimit(limit *int, def int) (int, error) {
+	if limit == nil {
+		return def, nil
+	}
+	if *limit < 0 || *limit > maxCopyLimit {
+		return 0, invalidInput("activity_copy_limit must be between 0 and %d", maxCopyLimit)
+	}
+	return *limit, nil
+}
+
+func (s *Server) followMany(r *request) (fields, error) {
+	def, err := r.intParam("activity_copy_limit", defaultCopyLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+	var body []struct {
+		Source            string `json:"source"`
+		Target            string `json:"target"`
+		ActivityCopyLimit *int   `json:"activity_copy_limit"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	for _, rel := range body {
+		source, err := s.feed(rel.Source)
+		if err != nil {
+			return nil, err
+		}
+		target, err := s.feed(rel.Target)
+		if err != nil {
+			return nil, err
+		}
+		copyLimit, err := activityCopyLimit(rel.ActivityCopyLimit, def)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.addFollow(source, target, copyLimit); err != nil {
+			return nil, err
+		}
+	}
+	return fields{}, nil
+}
+
+func (s *Server) unfollow(r *request) (fields, error) {
+	source, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.feed(r.params[2])
+	if err != nil {
+		return nil, err
+	}
+	s.removeFollow(source, target, r.boolParam("keep_history"))
+	return fields{}, nil
+}
+
+func (s *Server) unfollowMany(r *request) (fields, error) {
+	var body []struct {
+		Source      string `json:"source"`
+		Target      string `json:"target"`
+		KeepHistory bool   `json:"keep_history"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	for _, rel := range body {
+		source, err := s.feed(rel.Source)
+		if err != nil {
+			return nil, err
+		}
+		target, err := s.feed(rel.Target)
+		if err != nil {
+			return nil, err
+		}
+		s.removeFollow(source, target, rel.KeepHistory)
+	}
+	return fields{}, nil
+}
+
+func (s *Server) getFollowing(r *request) (fields, error) {
+	f, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	filter := splitList(r.query.Get("filter"))
+	return s.listFollows(r, func(fl *follow) bool {
+		return fl.source == f.id && (len(filter) == 0 || contains(filter, fl.target))
+	})
+}
+
+func (s *Server) getFollowers(r *request) (fields, error) {
+	f, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	return s.listFollows(r, func(fl *follow) bool {
+		return fl.target == f.id
+	})
+}
+
+// listFollows returns the follows matching the given function, newest first.
+func (s *Server) listFollows(r *request, match func(*follow) bool) (fields, error) {
+	limit, err := r.intParam("limit", defaultLimit, maxFollowLimit)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := r.intParam("offset", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var follows []*follow
+	for i := len(s.follows) - 1; i >= 0; i-- {
+		if match(s.follows[i]) {
+			follows = append(follows, s.follows[i])
+		}
+	}
+	start, end, _ := window(len(follows), offset, limit)
+	results := make([]fields, 0, end-start)
+	for _, f := range follows[start:end] {
+		results = append(results, f.toJSON())
+	}
+	return fields{"results": results}, nil
+}
+
+func (s *Server) updateToTargets(r *request) (fields, error) {
+	f, err := s.feedFromPath(r.params[0], r.params[1])
+	if err != nil {
+		return nil, err
+	}
+	var body struct {
+		ForeignID string   `json:"foreign_id"`
+		Time      string   `json:"time"`
+		New       []string `json:"new_targets"`
+		Adds      []string `json:"added_targets"`
+		Removes   []string `json:"removed_targets"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.New != nil && (body.Adds != nil || body.Removes != nil) {
+		return nil, invalidInput("new_targets cannot be combined with added_targets or removed_targets")
+	}
+	t, err := parseTime(body.Time)
+	if err != nil {
+		return nil, invalidInput("invalid activity time: %s", err)
+	}
+	a := s.findByForeignID(body.ForeignID, t)
+	if a == nil || f.index(a.id) < 0 {
+		return nil, notFound("activity with foreign_id %q and time %q not found in feed %s", body.ForeignID, body.Time, f.id)
+	}
+
+	old := stringList(a.fields["to"])
+	targets := body.New
+	if targets == nil {
+		for _, target := range append(old, body.Adds...) {
+			if !contains(body.Removes, target) && !contains(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+	}
+	for _, target := range targets {
+		if _, err := s.feed(target); err != nil {
+			return nil, err
+		}
+	}
+
+	var added, removed []string
+	for _, target := range old {
+		if !contains(targets, target) {
+			removed = append(removed, target)
+			if tf := s.feeds[target]; tf != nil {
+				if i := tf.index(a.id); i >= 0 && tf.entries[i].origin == f.id {
+					s.remove(tf, a.id)
+				}
+			}
+		}
+	}
+	to := make([]interface{}, len(targets))
+	for i, target := range targets {
+		to[i] = target
+		if !contains(old, target) {
+			added = append(added, target)
+			s.deliver(f, target, a.id)
+		}
+	}
+	a.fields["to"] = to
+	return fields{"activity": a.toJSON(""), "added": added, "removed": removed}, nil
+}
+
+func (s *Server) getActivitiesByID(r *request) (fields, error) {
+	var found []*activity
+	if ids := r.query.Get("ids"); ids != "" {
+		for _, id := range splitList(ids) {
+			if a, ok := s.activities[id]; ok {
+				found = append(found, a)
+			}
+		}
+	} else {
+		foreignIDs := splitList(r.query.Get("foreign_ids"))
+		timestamps := splitList(r.query.Get("timestamps"))
+		if len(foreignIDs) != len(timestamps) {
+			return nil, invalidInput("foreign_ids and timestamps must have the same length")
+		}
+		for i := range foreignIDs {
+			t, err := parseTime(timestamps[i])
+			if err != nil {
+				return nil, invalidInput("invalid timestamp: %s", err)
+			}
+			if a := s.findByForeignID(foreignIDs[i], t); a != nil {
+				found = append(found, a)
+			}
+		}
+	}
+	results := make([]fields, len(found))
+	for i, a := range found {
+		results[i] = a.toJSON("")
+	}
+	return fields{"results": results}, nil
+}
+
+func (s *Server) updateActivities(r *request) (fields, error) {
+	var body struct {
+		Activities []fields `json:"activities"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	updates := make(map[*activity]fields)
+	for _, data := range body.Activities {
+		a, err := s.parseActivity(data)
+		if err != nil {
+			return nil, err
+		}
+		if a.foreignID() == "" || data["time"] == nil {
+			return nil, invalidInput("foreign_id and time are required for updating activities")
+		}
+		existing := s.findByForeignID(a.foreignID(), a.time)
+		if existing == nil {
+			return nil, notFound("activity with foreign_id %q not found", a.foreignID())
+		}
+		updates[existing] = a.fields
+	}
+	for a, f := range updates {
+		a.fields = f
+	}
+	return fields{}, nil
+}
+
+func (s *Server) partialUpdateActivities(r *request) (fields, error) {
+	type changeset struct {
+		ID        string   `json:"id"`
+		ForeignID string   `json:"foreign_id"`
+		Time      string   `json:"time"`
+		Set       fields   `json:"set"`
+		Unset     []string `json:"unset"`
+	}
+	var body struct {
+		changeset
+		Changes []changeset `json:"changes"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	changes := body.Changes
+	if changes == nil {
+		changes = []changeset{body.changeset}
+	}
+
+	targets := make([]*activity, len(changes))
+	for i, c := range changes {
+		var a *activity
+		if c.ID != "" {
+			a = s.activities[c.ID]
+		} else if t, err := parseTime(c.Time); err == nil {
+			a = s.findByForeignID(c.ForeignID, t)
+		}
+		if a == nil {
+			return nil, notFound("activity not found")
+		}
+		for _, k := range append(c.Unset, keys(c.Set)...) {
+			switch strings.SplitN(k, ".", 2)[0] {
+			case "id", "foreign_id", "time":
+				return nil, invalidInput("field %q cannot be updated", k)
+			}
+		}
+		targets[i] = a
+	}
+	results := make([]fields, len(changes))
+	for i, c := range changes {
+		a := targets[i]
+		for k, v := range c.Set {
+			setPath(a.fields, k, v)
+		}
+		for _, k := range c.Unset {
+			unsetPath(a.fields, k)
+		}
+		results[i] = a.toJSON("")
+	}
+	if body.Changes == nil {
+		return results[0], nil
+	}
+	return fields{"activities": results}, nil
+}
+
+func keys(m fields) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	return list
+}
+
+// setPath sets a possibly nested field, given its dotted path.
+func setPath(m map[string]interface{}, path string, v interface{}) {
+	parts := strings.Split(path, ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = v
+}
+
+// unsetPath removes a possibly nested field, given its dotted path.
+func unsetPath(m map[string]interface{}, path string) {
+	parts := strings.Split(path, ".")
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = next
+	}
+	delete(m, parts[len(parts)-1])
+}
@@ -0,0 +1,1776 @@
+package streamtest
+
+import (
+	"context"
+	"sync"
+
+	stream "github.com/GetStream/stream-go2"
+)
+
+// MockCall is a call recorded by a mock.
+type MockCall struct {
+	// Method is the name of the called method.
+	Method string
+	// Args are the arguments of the call, except for the context. Variadic
+	// arguments are recorded as a single slice.
+	Args []interface{}
+}
+
+// mockCalls records the calls received by a mock.
+type mockCalls struct {
+	mu    sync.Mutex
+	calls []MockCall
+}
+
+func (m *mockCalls) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, MockCall{Method: method, Args: args})
+}
+
+// Calls returns the calls received by the mock, in order.
+func (m *mockCalls) Calls() []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockCall(nil), m.calls...)
+}
+
+// CallsTo returns the calls to the given method received by the mock, in
+// order.
+func (m *mockCalls) CallsTo(method string) []MockCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var calls []MockCall
+	for _, c := range m.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+var (
+	_ stream.ClientInterface                = (*MockClient)(nil)
+	_ stream.Feed                           = (*MockFeed)(nil)
+	_ stream.FlatFeedInterface              = (*MockFlatFeed)(nil)
+	_ stream.AggregatedFeedInterface        = (*MockAggregatedFeed)(nil)
+	_ stream.NotificationFeedInterface      = (*MockNotificationFeed)(nil)
+	_ stream.AnalyticsClientInterface       = (*MockAnalyticsClient)(nil)
+	_ stream.CollectionsClientInterface     = (*MockCollectionsClient)(nil)
+	_ stream.UsersClientInterface           = (*MockUsersClient)(nil)
+	_ stream.ReactionsClientInterface       = (*MockReactionsClient)(nil)
+	_ stream.PersonalizationClientInterface = (*MockPersonalizationClient)(nil)
+)
+
+// MockClient is a mock stream.ClientInterface recording the calls it
+// receives, to be created with NewMockClient.
+//
+// Each method calls the function held by the corresponding field, if set, and
+// otherwise returns an empty response and a nil error. Feeds and sub-clients
+// are mocks themselves: the sub-clients are held by the client fields, and
+// FlatFeed, AggregatedFeed and NotificationFeed return the same mock feed
+// every time they're called with the same slug and user ID.
+type MockClient struct {
+	mockCalls
+
+	// AnalyticsClient is the mock returned by Analytics.
+	AnalyticsClient *MockAnalyticsClient
+	// CollectionsClient is the mock returned by Collections.
+	CollectionsClient *MockCollectionsClient
+	// UsersClient is the mock returned by Users.
+	UsersClient *MockUsersClient
+	// ReactionsClient is the mock returned by Reactions.
+	ReactionsClient *MockReactionsClient
+	// PersonalizationClient is the mock returned by Personalization.
+	PersonalizationClient *MockPersonalizationClient
+
+	FlatFeedFunc                           func(string, string) (stream.FlatFeedInterface, error)
+	AggregatedFeedFunc                     func(string, string) (stream.AggregatedFeedInterface, error)
+	NotificationFeedFunc                   func(string, string) (stream.NotificationFeedInterface, error)
+	AddToManyFunc                          func(stream.Activity, ...stream.Feed) error
+	AddToManyContextFunc                   func(context.Context, stream.Activity, ...stream.Feed) error
+	FollowManyFunc                         func([]stream.FollowRelationship, ...stream.FollowManyOption) error
+	FollowManyContextFunc                  func(context.Context, []stream.FollowRelationship, ...stream.FollowManyOption) error
+	UnfollowManyFunc                       func([]stream.UnfollowRelationship) error
+	UnfollowManyContextFunc                func(context.Context, []stream.UnfollowRelationship) error
+	BulkAddActivitiesFunc                  func(stream.Feed, []stream.Activity, stream.BulkConfig) (*stream.BulkActivitiesResult, error)
+	BulkAddActivitiesContextFunc           func(context.Context, stream.Feed, []stream.Activity, stream.BulkConfig) (*stream.BulkActivitiesResult, error)
+	BulkUpdateActivitiesFunc               func([]stream.Activity, stream.BulkConfig) (*stream.BulkResult, error)
+	BulkUpdateActivitiesContextFunc        func(context.Context, []stream.Activity, stream.BulkConfig) (*stream.BulkResult, error)
+	BulkPartialUpdateActivitiesFunc        func([]stream.UpdateActivityRequest, stream.BulkConfig) (*stream.BulkActivitiesResult, error)
+	BulkPartialUpdateActivitiesContextFunc func(context.Context, []stream.UpdateActivityRequest, stream.BulkConfig) (*stream.BulkActivitiesResult, error)
+	BulkAddToManyFunc                      func(stream.Activity, []stream.Feed, stream.BulkConfig) (*stream.BulkResult, error)
+	BulkAddToManyContextFunc               func(context.Context, stream.Activity, []stream.Feed, stream.BulkConfig) (*stream.BulkResult, error)
+	BulkFollowManyFunc                     func([]stream.FollowRelationship, stream.BulkConfig, ...stream.FollowManyOption) (*stream.BulkResult, error)
+	BulkFollowManyContextFunc              func(context.Context, []stream.FollowRelationship, stream.BulkConfig, ...stream.FollowManyOption) (*stream.BulkResult, error)
+	BulkUnfollowManyFunc                   func([]stream.UnfollowRelationship, stream.BulkConfig) (*stream.BulkResult, error)
+	BulkUnfollowManyContextFunc            func(context.Context, []stream.UnfollowRelationship, stream.BulkConfig) (*stream.BulkResult, error)
+	GetActivitiesByIDFunc                  func(...string) (*stream.GetActivitiesResponse, error)
+	GetActivitiesByIDContextFunc           func(context.Context, ...string) (*stream.GetActivitiesResponse, error)
+	GetActivitiesByForeignIDFunc           func(...stream.ForeignIDTimePair) (*stream.GetActivitiesResponse, error)
+	GetActivitiesByForeignIDContextFunc    func(context.Context, ...stream.ForeignIDTimePair) (*stream.GetActivitiesResponse, error)
+	UpdateActivitiesFunc                   func(...stream.Activity) error
+	UpdateActivitiesContextFunc            func(context.Context, ...stream.Activity) error
+	PartialUpdateActivitiesFunc            func(...stream.UpdateActivityRequest) (*stream.UpdateActivitiesResponse, error)
+	PartialUpdateActivitiesContextFunc     func(context.Context, ...stream.UpdateActivityRequest) (*stream.UpdateActivitiesResponse, error)
+	UpdateActivityByIDFunc                 func(string, map[string]interface{}, []string) (*stream.UpdateActivityResponse, error)
+	UpdateActivityByIDContextFunc          func(context.Context, string, map[string]interface{}, []string) (*stream.UpdateActivityResponse, error)
+	UpdateActivityByForeignIDFunc          func(string, stream.Time, map[string]interface{}, []string) (*stream.UpdateActivityResponse, error)
+	UpdateActivityByForeignIDContextFunc   func(context.Context, string, stream.Time, map[string]interface{}, []string) (*stream.UpdateActivityResponse, error)
+	GetUserSessionTokenFunc                func(string) (string, error)
+	GetUserSessionTokenWithClaimsFunc      func(string, map[string]interface{}) (string, error)
+	NewCursorFunc                          func(string) (string, error)
+
+	feedsMu           sync.Mutex
+	flatFeeds         map[string]*MockFlatFeed
+	aggregatedFeeds   map[string]*MockAggregatedFeed
+	notificationFeeds map[string]*MockNotificationFeed
+}
+
+// NewMockClient returns a new MockClient.
+func NewMockClient() *MockClient {
+	return &MockClient{
+		AnalyticsClient:       &MockAnalyticsClient{},
+		CollectionsClient:     &MockCollectionsClient{},
+		UsersClient:           &MockUsersClient{},
+		ReactionsClient:       &MockReactionsClient{},
+		PersonalizationClient: &MockPersonalizationClient{},
+		flatFeeds:             make(map[string]*MockFlatFeed),
+		aggregatedFeeds:       make(map[string]*MockAggregatedFeed),
+		notificationFeeds:     make(map[string]*MockNotificationFeed),
+	}
+}
+
+// FlatFeedMock returns the mock flat feed having the given slug and user ID,
+// creating it if needed.
+func (m *MockClient) FlatFeedMock(slug, userID string) *MockFlatFeed {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+	id := slug + ":" + userID
+	f, ok := m.flatFeeds[id]
+	if !ok {
+		f = NewMockFlatFeed(slug, userID)
+		m.flatFeeds[id] = f
+	}
+	return f
+}
+
+// AggregatedFeedMock returns the mock aggregated feed having the given slug
+// and user ID, creating it if needed.
+func (m *MockClient) AggregatedFeedMock(slug, userID string) *MockAggregatedFeed {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+	id := slug + ":" + userID
+	f, ok := m.aggregatedFeeds[id]
+	if !ok {
+		f = NewMockAggregatedFeed(slug, userID)
+		m.aggregatedFeeds[id] = f
+	}
+	return f
+}
+
+// NotificationFeedMock returns the mock notification feed having the given
+// slug and user ID, creating it if needed.
+func (m *MockClient) NotificationFeedMock(slug, userID string) *MockNotificationFeed {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+	id := slug + ":" + userID
+	f, ok := m.notificationFeeds[id]
+	if !ok {
+		f = NewMockNotificationFeed(slug, userID)
+		m.notificationFeeds[id] = f
+	}
+	return f
+}
+
+// FlatFeed records the call and calls FlatFeedFunc, if set, or returns the
+// feed returned by FlatFeedMock otherwise.
+func (m *MockClient) FlatFeed(slug, userID string) (stream.FlatFeedInterface, error) {
+	m.record("FlatFeed", slug, userID)
+	if m.FlatFeedFunc != nil {
+		return m.FlatFeedFunc(slug, userID)
+	}
+	return m.FlatFeedMock(slug, userID), nil
+}
+
+// AggregatedFeed records the call and calls AggregatedFeedFunc, if set, or
+// returns the feed returned by AggregatedFeedMock otherwise.
+func (m *MockClient) AggregatedFeed(slug, userID string) (stream.AggregatedFeedInterface, error) {
+	m.record("AggregatedFeed", slug, userID)
+	if m.AggregatedFeedFunc != nil {
+		return m.AggregatedFeedFunc(slug, userID)
+	}
+	return m.AggregatedFeedMock(slug, userID), nil
+}
+
+// NotificationFeed records the call and calls NotificationFeedFunc, if set, or
+// returns the feed returned by NotificationFeedMock otherwise.
+func (m *MockClient) NotificationFeed(slug, userID string) (stream.NotificationFeedInterface, error) {
+	m.record("NotificationFeed", slug, userID)
+	if m.NotificationFeedFunc != nil {
+		return m.NotificationFeedFunc(slug, userID)
+	}
+	return m.NotificationFeedMock(slug, userID), nil
+}
+
+// Analytics records the call and returns AnalyticsClient.
+func (m *MockClient) Analytics() stream.AnalyticsClientInterface {
+	m.record("Analytics")
+	return m.AnalyticsClient
+}
+
+// Collections records the call and returns CollectionsClient.
+func (m *MockClient) Collections() stream.CollectionsClientInterface {
+	m.record("Collections")
+	return m.CollectionsClient
+}
+
+// Users records the call and returns UsersClient.
+func (m *MockClient) Users() stream.UsersClientInterface {
+	m.record("Users")
+	return m.UsersClient
+}
+
+// Reactions records the call and returns ReactionsClient.
+func (m *MockClient) Reactions() stream.ReactionsClientInterface {
+	m.record("Reactions")
+	return m.ReactionsClient
+}
+
+// Personalization records the call and returns PersonalizationClient.
+func (m *MockClient) Personalization() stream.PersonalizationClientInterface {
+	m.record("Personalization")
+	return m.PersonalizationClient
+}
+
+// AddToMany records the call and calls AddToManyFunc, if set.
+func (m *MockClient) AddToMany(activity stream.Activity, feeds ...stream.Feed) error {
+	m.record("AddToMany", activity, feeds)
+	if m.AddToManyFunc != nil {
+		return m.AddToManyFunc(activity, feeds...)
+	}
+	return nil
+}
+
+// AddToManyContext records the call and calls AddToManyContextFunc, if set.
+func (m *MockClient) AddToManyContext(ctx context.Context, activity stream.Activity, feeds ...stream.Feed) error {
+	m.record("AddToManyContext", activity, feeds)
+	if m.AddToManyContextFunc != nil {
+		return m.AddToManyContextFunc(ctx, activity, feeds...)
+	}
+	return nil
+}
+
+// FollowMany records the call and calls FollowManyFunc, if set.
+func (m *MockClient) FollowMany(relationships []stream.FollowRelationship, opts ...stream.FollowManyOption) error {
+	m.record("FollowMany", relationships, opts)
+	if m.FollowManyFunc != nil {
+		return m.FollowManyFunc(relationships, opts...)
+	}
+	return nil
+}
+
+// FollowManyContext records the call and calls FollowManyContextFunc, if set.
+func (m *MockClient) FollowManyContext(ctx context.Context, relationships []stream.FollowRelationship, opts ...stream.FollowManyOption) error {
+	m.record("FollowManyContext", relationships, opts)
+	if m.FollowManyContextFunc != nil {
+		return m.FollowManyContextFunc(ctx, relationships, opts...)
+	}
+	return nil
+}
+
+// UnfollowMany records the call and calls UnfollowManyFunc, if set.
+func (m *MockClient) UnfollowMany(relationships []stream.UnfollowRelationship) error {
+	m.record("UnfollowMany", relationships)
+	if m.UnfollowManyFunc != nil {
+		return m.UnfollowManyFunc(relationships)
+	}
+	return nil
+}
+
+// UnfollowManyContext records the call and calls UnfollowManyContextFunc, if set.
+func (m *MockClient) UnfollowManyContext(ctx context.Context, relationships []stream.UnfollowRelationship) error {
+	m.record("UnfollowManyContext", relationships)
+	if m.UnfollowManyContextFunc != nil {
+		return m.UnfollowManyContextFunc(ctx, relationships)
+	}
+	return nil
+}
+
+// BulkAddActivities records the call and calls BulkAddActivitiesFunc, if set.
+func (m *MockClient) BulkAddActivities(feed stream.Feed, activities []stream.Activity, config stream.BulkConfig) (*stream.BulkActivitiesResult, error) {
+	m.record("BulkAddActivities", feed, activities, config)
+	if m.BulkAddActivitiesFunc != nil {
+		return m.BulkAddActivitiesFunc(feed, activities, config)
+	}
+	return new(stream.BulkActivitiesResult), nil
+}
+
+// BulkAddActivitiesContext records the call and calls BulkAddActivitiesContextFunc, if set.
+func (m *MockClient) BulkAddActivitiesContext(ctx context.Context, feed stream.Feed, activities []stream.Activity, config stream.BulkConfig) (*stream.BulkActivitiesResult, error) {
+	m.record("BulkAddActivitiesContext", feed, activities, config)
+	if m.BulkAddActivitiesContextFunc != nil {
+		return m.BulkAddActivitiesContextFunc(ctx, feed, activities, config)
+	}
+	return new(stream.BulkActivitiesResult), nil
+}
+
+// BulkUpdateActivities records the call and calls BulkUpdateActivitiesFunc, if set.
+func (m *MockClient) BulkUpdateActivities(activities []stream.Activity, config stream.BulkConfig) (*stream.BulkResult, error) {
+	m.record("BulkUpdateActivities", activities, config)
+	if m.BulkUpdateActivitiesFunc != nil {
+		return m.BulkUpdateActivitiesFunc(activities, config)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkUpdateActivitiesContext records the call and calls BulkUpdateActivitiesContextFunc, if set.
+func (m *MockClient) BulkUpdateActivitiesContext(ctx context.Context, activities []stream.Activity, config stream.BulkConfig) (*stream.BulkResult, error) {
+	m.record("BulkUpdateActivitiesContext", activities, config)
+	if m.BulkUpdateActivitiesContextFunc != nil {
+		return m.BulkUpdateActivitiesContextFunc(ctx, activities, config)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkPartialUpdateActivities records the call and calls BulkPartialUpdateActivitiesFunc, if set.
+func (m *MockClient) BulkPartialUpdateActivities(changesets []stream.UpdateActivityRequest, config stream.BulkConfig) (*stream.BulkActivitiesResult, error) {
+	m.record("BulkPartialUpdateActivities", changesets, config)
+	if m.BulkPartialUpdateActivitiesFunc != nil {
+		return m.BulkPartialUpdateActivitiesFunc(changesets, config)
+	}
+	return new(stream.BulkActivitiesResult), nil
+}
+
+// BulkPartialUpdateActivitiesContext records the call and calls BulkPartialUpdateActivitiesContextFunc, if set.
+func (m *MockClient) BulkPartialUpdateActivitiesContext(ctx context.Context, changesets []stream.UpdateActivityRequest, config stream.BulkConfig) (*stream.BulkActivitiesResult, error) {
+	m.record("BulkPartialUpdateActivitiesContext", changesets, config)
+	if m.BulkPartialUpdateActivitiesContextFunc != nil {
+		return m.BulkPartialUpdateActivitiesContextFunc(ctx, changesets, config)
+	}
+	return new(stream.BulkActivitiesResult), nil
+}
+
+// BulkAddToMany records the call and calls BulkAddToManyFunc, if set.
+func (m *MockClient) BulkAddToMany(activity stream.Activity, feeds []stream.Feed, config stream.BulkConfig) (*stream.BulkResult, error) {
+	m.record("BulkAddToMany", activity, feeds, config)
+	if m.BulkAddToManyFunc != nil {
+		return m.BulkAddToManyFunc(activity, feeds, config)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkAddToManyContext records the call and calls BulkAddToManyContextFunc, if set.
+func (m *MockClient) BulkAddToManyContext(ctx context.Context, activity stream.Activity, feeds []stream.Feed, config stream.BulkConfig) (*stream.BulkResult, error) {
+	m.record("BulkAddToManyContext", activity, feeds, config)
+	if m.BulkAddToManyContextFunc != nil {
+		return m.BulkAddToManyContextFunc(ctx, activity, feeds, config)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkFollowMany records the call and calls BulkFollowManyFunc, if set.
+func (m *MockClient) BulkFollowMany(relationships []stream.FollowRelationship, config stream.BulkConfig, opts ...stream.FollowManyOption) (*stream.BulkResult, error) {
+	m.record("BulkFollowMany", relationships, config, opts)
+	if m.BulkFollowManyFunc != nil {
+		return m.BulkFollowManyFunc(relationships, config, opts...)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkFollowManyContext records the call and calls BulkFollowManyContextFunc, if set.
+func (m *MockClient) BulkFollowManyContext(ctx context.Context, relationships []stream.FollowRelationship, config stream.BulkConfig, opts ...stream.FollowManyOption) (*stream.BulkResult, error) {
+	m.record("BulkFollowManyContext", relationships, config, opts)
+	if m.BulkFollowManyContextFunc != nil {
+		return m.BulkFollowManyContextFunc(ctx, relationships, config, opts...)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkUnfollowMany records the call and calls BulkUnfollowManyFunc, if set.
+func (m *MockClient) BulkUnfollowMany(relationships []stream.UnfollowRelationship, config stream.BulkConfig) (*stream.BulkResult, error) {
+	m.record("BulkUnfollowMany", relationships, config)
+	if m.BulkUnfollowManyFunc != nil {
+		return m.BulkUnfollowManyFunc(relationships, config)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// BulkUnfollowManyContext records the call and calls BulkUnfollowManyContextFunc, if set.
+func (m *MockClient) BulkUnfollowManyContext(ctx context.Context, relationships []stream.UnfollowRelationship, config stream.BulkConfig) (*stream.BulkResult, error) {
+	m.record("BulkUnfollowManyContext", relationships, config)
+	if m.BulkUnfollowManyContextFunc != nil {
+		return m.BulkUnfollowManyContextFunc(ctx, relationships, config)
+	}
+	return new(stream.BulkResult), nil
+}
+
+// GetActivitiesByID records the call and calls GetActivitiesByIDFunc, if set.
+func (m *MockClient) GetActivitiesByID(ids ...string) (*stream.GetActivitiesResponse, error) {
+	m.record("GetActivitiesByID", ids)
+	if m.GetActivitiesByIDFunc != nil {
+		return m.GetActivitiesByIDFunc(ids...)
+	}
+	return new(stream.GetActivitiesResponse), nil
+}
+
+// GetActivitiesByIDContext records the call and calls GetActivitiesByIDContextFunc, if set.
+func (m *MockClient) GetActivitiesByIDContext(ctx context.Context, ids ...string) (*stream.GetActivitiesResponse, error) {
+	m.record("GetActivitiesByIDContext", ids)
+	if m.GetActivitiesByIDContextFunc != nil {
+		return m.GetActivitiesByIDContextFunc(ctx, ids...)
+	}
+	return new(stream.GetActivitiesResponse), nil
+}
+
+// GetActivitiesByForeignID records the call and calls GetActivitiesByForeignIDFunc, if set.
+func (m *MockClient) GetActivitiesByForeignID(values ...stream.ForeignIDTimePair) (*stream.GetActivitiesResponse, error) {
+	m.record("GetActivitiesByForeignID", values)
+	if m.GetActivitiesByForeignIDFunc != nil {
+		return m.GetActivitiesByForeignIDFunc(values...)
+	}
+	return new(stream.GetActivitiesResponse), nil
+}
+
+// GetActivitiesByForeignIDContext records the call and calls GetActivitiesByForeignIDContextFunc, if set.
+func (m *MockClient) GetActivitiesByForeignIDContext(ctx context.Context, values ...stream.ForeignIDTimePair) (*stream.GetActivitiesResponse, error) {
+	m.record("GetActivitiesByForeignIDContext", values)
+	if m.GetActivitiesByForeignIDContextFunc != nil {
+		return m.GetActivitiesByForeignIDContextFunc(ctx, values...)
+	}
+	return new(stream.GetActivitiesResponse), nil
+}
+
+// UpdateActivities records the call and calls UpdateActivitiesFunc, if set.
+func (m *MockClient) UpdateActivities(activities ...stream.Activity) error {
+	m.record("UpdateActivities", activities)
+	if m.UpdateActivitiesFunc != nil {
+		return m.UpdateActivitiesFunc(activities...)
+	}
+	return nil
+}
+
+// UpdateActivitiesContext records the call and calls UpdateActivitiesContextFunc, if set.
+func (m *MockClient) UpdateActivitiesContext(ctx context.Context, activities ...stream.Activity) error {
+	m.record("UpdateActivitiesContext", activities)
+	if m.UpdateActivitiesContextFunc != nil {
+		return m.UpdateActivitiesContextFunc(ctx, activities...)
+	}
+	return nil
+}
+
+// PartialUpdateActivities records the call and calls PartialUpdateActivitiesFunc, if set.
+func (m *MockClient) PartialUpdateActivities(changesets ...stream.UpdateActivityRequest) (*stream.UpdateActivitiesResponse, error) {
+	m.record("PartialUpdateActivities", changesets)
+	if m.PartialUpdateActivitiesFunc != nil {
+		return m.PartialUpdateActivitiesFunc(changesets...)
+	}
+	return new(stream.UpdateActivitiesResponse), nil
+}
+
+// PartialUpdateActivitiesContext records the call and calls PartialUpdateActivitiesContextFunc, if set.
+func (m *MockClient) PartialUpdateActivitiesContext(ctx context.Context, changesets ...stream.UpdateActivityRequest) (*stream.UpdateActivitiesResponse, error) {
+	m.record("PartialUpdateActivitiesContext", changesets)
+	if m.PartialUpdateActivitiesContextFunc != nil {
+		return m.PartialUpdateActivitiesContextFunc(ctx, changesets...)
+	}
+	return new(stream.UpdateActivitiesResponse), nil
+}
+
+// UpdateActivityByID records the call and calls UpdateActivityByIDFunc, if set.
+func (m *MockClient) UpdateActivityByID(id string, set map[string]interface{}, unset []string) (*stream.UpdateActivityResponse, error) {
+	m.record("UpdateActivityByID", id, set, unset)
+	if m.UpdateActivityByIDFunc != nil {
+		return m.UpdateActivityByIDFunc(id, set, unset)
+	}
+	return new(stream.UpdateActivityResponse), nil
+}
+
+// UpdateActivityByIDContext records the call and calls UpdateActivityByIDContextFunc, if set.
+func (m *MockClient) UpdateActivityByIDContext(ctx context.Context, id string, set map[string]interface{}, unset []string) (*stream.UpdateActivityResponse, error) {
+	m.record("UpdateActivityByIDContext", id, set, unset)
+	if m.UpdateActivityByIDContextFunc != nil {
+		return m.UpdateActivityByIDContextFunc(ctx, id, set, unset)
+	}
+	return new(stream.UpdateActivityResponse), nil
+}
+
+// UpdateActivityByForeignID records the call and calls UpdateActivityByForeignIDFunc, if set.
+func (m *MockClient) UpdateActivityByForeignID(foreignID string, timestamp stream.Time, set map[string]interface{}, unset []string) (*stream.UpdateActivityResponse, error) {
+	m.record("UpdateActivityByForeignID", foreignID, timestamp, set, unset)
+	if m.UpdateActivityByForeignIDFunc != nil {
+		return m.UpdateActivityByForeignIDFunc(foreignID, timestamp, set, unset)
+	}
+	return new(stream.UpdateActivityResponse), nil
+}
+
+// UpdateActivityByForeignIDContext records the call and calls UpdateActivityByForeignIDContextFunc, if set.
+func (m *MockClient) UpdateActivityByForeignIDContext(ctx context.Context, foreignID string, timestamp stream.Time, set map[string]interface{}, unset []string) (*stream.UpdateActivityResponse, error) {
+	m.record("UpdateActivityByForeignIDContext", foreignID, timestamp, set, unset)
+	if m.UpdateActivityByForeignIDContextFunc != nil {
+		return m.UpdateActivityByForeignIDContextFunc(ctx, foreignID, timestamp, set, unset)
+	}
+	return new(stream.UpdateActivityResponse), nil
+}
+
+// GetUserSessionToken records the call and calls GetUserSessionTokenFunc, if set.
+func (m *MockClient) GetUserSessionToken(userID string) (string, error) {
+	m.record("GetUserSessionToken", userID)
+	if m.GetUserSessionTokenFunc != nil {
+		return m.GetUserSessionTokenFunc(userID)
+	}
+	return "", nil
+}
+
+// GetUserSessionTokenWithClaims records the call and calls GetUserSessionTokenWithClaimsFunc, if set.
+func (m *MockClient) GetUserSessionTokenWithClaims(userID string, claims map[string]interface{}) (string, error) {
+	m.record("GetUserSessionTokenWithClaims", userID, claims)
+	if m.GetUserSessionTokenWithClaimsFunc != nil {
+		return m.GetUserSessionTokenWithClaimsFunc(userID, claims)
+	}
+	return "", nil
+}
+
+// NewCursor records the call and calls NewCursorFunc, if set.
+func (m *MockClient) NewCursor(next string) (string, error) {
+	m.record("NewCursor", next)
+	if m.NewCursorFunc != nil {
+		return m.NewCursorFunc(next)
+	}
+	return "", nil
+}
+
+// MockFeed is a mock stream.Feed recording the calls it receives, to be
+// created with NewMockFeed. Each method calls the function held by the
+// corresponding field, if set, and otherwise returns an empty response and a
+// nil error.
+type MockFeed struct {
+	mockCalls
+	slug   string
+	userID string
+
+	AddActivityFunc                      func(stream.Activity) (*stream.AddActivityResponse, error)
+	AddActivityContextFunc               func(context.Context, stream.Activity) (*stream.AddActivityResponse, error)
+	AddActivitiesFunc                    func(...stream.Activity) (*stream.AddActivitiesResponse, error)
+	AddActivitiesContextFunc             func(context.Context, ...stream.Activity) (*stream.AddActivitiesResponse, error)
+	RemoveActivityByIDFunc               func(string) error
+	RemoveActivityByIDContextFunc        func(context.Context, string) error
+	RemoveActivityByForeignIDFunc        func(string) error
+	RemoveActivityByForeignIDContextFunc func(context.Context, string) error
+	FollowFunc                           func(stream.FlatFeedInterface, ...stream.FollowFeedOption) error
+	FollowContextFunc                    func(context.Context, stream.FlatFeedInterface, ...stream.FollowFeedOption) error
+	GetFollowingFunc                     func(...stream.FollowingOption) (*stream.FollowingResponse, error)
+	GetFollowingContextFunc              func(context.Context, ...stream.FollowingOption) (*stream.FollowingResponse, error)
+	UnfollowFunc                         func(stream.Feed, ...stream.UnfollowOption) error
+	UnfollowContextFunc                  func(context.Context, stream.Feed, ...stream.UnfollowOption) error
+	UpdateToTargetsFunc                  func(stream.Activity, ...stream.UpdateToTargetsOption) error
+	UpdateToTargetsContextFunc           func(context.Context, stream.Activity, ...stream.UpdateToTargetsOption) error
+	RealtimeTokenFunc                    func(bool) string
+}
+
+// NewMockFeed returns a new MockFeed with the given slug and user ID.
+func NewMockFeed(slug, userID string) *MockFeed {
+	return &MockFeed{slug: slug, userID: userID}
+}
+
+// ID returns the feed ID, as slug:user_id.
+func (m *MockFeed) ID() string {
+	return m.slug + ":" + m.userID
+}
+
+// Slug returns the feed's slug.
+func (m *MockFeed) Slug() string {
+	return m.slug
+}
+
+// UserID returns the feed's user_id.
+func (m *MockFeed) UserID() string {
+	return m.userID
+}
+
+// AddActivity records the call and calls AddActivityFunc, if set.
+func (m *MockFeed) AddActivity(activity stream.Activity) (*stream.AddActivityResponse, error) {
+	m.record("AddActivity", activity)
+	if m.AddActivityFunc != nil {
+		return m.AddActivityFunc(activity)
+	}
+	return new(stream.AddActivityResponse), nil
+}
+
+// AddActivityContext records the call and calls AddActivityContextFunc, if set.
+func (m *MockFeed) AddActivityContext(ctx context.Context, activity stream.Activity) (*stream.AddActivityResponse, error) {
+	m.record("AddActivityContext", activity)
+	if m.AddActivityContextFunc != nil {
+		return m.AddActivityContextFunc(ctx, activity)
+	}
+	return new(stream.AddActivityResponse), nil
+}
+
+// AddActivities records the call and calls AddActivitiesFunc, if set.
+func (m *MockFeed) AddActivities(activities ...stream.Activity) (*stream.AddActivitiesResponse, error) {
+	m.record("AddActivities", activities)
+	if m.AddActivitiesFunc != nil {
+		return m.AddActivitiesFunc(activities...)
+	}
+	return new(stream.AddActivitiesResponse), nil
+}
+
+// AddActivitiesContext records the call and calls AddActivitiesContextFunc, if set.
+func (m *MockFeed) AddActivitiesContext(ctx context.Context, activities ...stream.Activity) (*stream.AddActivitiesResponse, error) {
+	m.record("AddActivitiesContext", activities)
+	if m.AddActivitiesContextFunc != nil {
+		return m.AddActivitiesContextFunc(ctx, activities...)
+	}
+	return new(stream.AddActivitiesResponse), nil
+}
+
+// RemoveActivityByID records the call and calls RemoveActivityByIDFunc, if set.
+func (m *MockFeed) RemoveActivityByID(id string) error {
+	m.record("RemoveActivityByID", id)
+	if m.RemoveActivityByIDFunc != nil {
+		return m.RemoveActivityByIDFunc(id)
+	}
+	return nil
+}
+
+// RemoveActivityByIDContext records the call and calls RemoveActivityByIDContextFunc, if set.
+func (m *MockFeed) RemoveActivityByIDContext(ctx context.Context, id string) error {
+	m.record("RemoveActivityByIDContext", id)
+	if m.RemoveActivityByIDContextFunc != nil {
+		return m.RemoveActivityByIDContextFunc(ctx, id)
+	}
+	return nil
+}
+
+// RemoveActivityByForeignID records the call and calls RemoveActivityByForeignIDFunc, if set.
+func (m *MockFeed) RemoveActivityByForeignID(foreignID string) error {
+	m.record("RemoveActivityByForeignID", foreignID)
+	if m.RemoveActivityByForeignIDFunc != nil {
+		return m.RemoveActivityByForeignIDFunc(foreignID)
+	}
+	return nil
+}
+
+// RemoveActivityByForeignIDContext records the call and calls RemoveActivityByForeignIDContextFunc, if set.
+func (m *MockFeed) RemoveActivityByForeignIDContext(ctx context.Context, foreignID string) error {
+	m.record("RemoveActivityByForeignIDContext", foreignID)
+	if m.RemoveActivityByForeignIDContextFunc != nil {
+		return m.RemoveActivityByForeignIDContextFunc(ctx, foreignID)
+	}
+	return nil
+}
+
+// Follow records the call and calls FollowFunc, if set.
+func (m *MockFeed) Follow(feed stream.FlatFeedInterface, opts ...stream.FollowFeedOption) error {
+	m.record("Follow", feed, opts)
+	if m.FollowFunc != nil {
+		return m.FollowFunc(feed, opts...)
+	}
+	return nil
+}
+
+// FollowContext records the call and calls FollowContextFunc, if set.
+func (m *MockFeed) FollowContext(ctx context.Context, feed stream.FlatFeedInterface, opts ...stream.FollowFeedOption) error {
+	m.record("FollowContext", feed, opts)
+	if m.FollowContextFunc != nil {
+		return m.FollowContextFunc(ctx, feed, opts...)
+	}
+	return nil
+}
+
+// GetFollowing records the call and calls GetFollowingFunc, if set.
+func (m *MockFeed) GetFollowing(opts ...stream.FollowingOption) (*stream.FollowingResponse, error) {
+	m.record("GetFollowing", opts)
+	if m.GetFollowingFunc != nil {
+		return m.GetFollowingFunc(opts...)
+	}
+	return new(stream.FollowingResponse), nil
+}
+
+// GetFollowingContext records the call and calls GetFollowingContextFunc, if set.
+func (m *MockFeed) GetFollowingContext(ctx context.Context, opts ...stream.FollowingOption) (*stream.FollowingResponse, error) {
+	m.record("GetFollowingContext", opts)
+	if m.GetFollowingContextFunc != nil {
+		return m.GetFollowingContextFunc(ctx, opts...)
+	}
+	return new(stream.FollowingResponse), nil
+}
+
+// Unfollow records the call and calls UnfollowFunc, if set.
+func (m *MockFeed) Unfollow(target stream.Feed, opts ...stream.UnfollowOption) error {
+	m.record("Unfollow", target, opts)
+	if m.UnfollowFunc != nil {
+		return m.UnfollowFunc(target, opts...)
+	}
+	return nil
+}
+
+// UnfollowContext records the call and calls UnfollowContextFunc, if set.
+func (m *MockFeed) UnfollowContext(ctx context.Context, target stream.Feed, opts ...stream.UnfollowOption) error {
+	m.record("UnfollowContext", target, opts)
+	if m.UnfollowContextFunc != nil {
+		return m.UnfollowContextFunc(ctx, target, opts...)
+	}
+	return nil
+}
+
+// UpdateToTargets records the call and calls UpdateToTargetsFunc, if set.
+func (m *MockFeed) UpdateToTargets(activity stream.Activity, opts ...stream.UpdateToTargetsOption) error {
+	m.record("UpdateToTargets", activity, opts)
+	if m.UpdateToTargetsFunc != nil {
+		return m.UpdateToTargetsFunc(activity, opts...)
+	}
+	return nil
+}
+
+// UpdateToTargetsContext records the call and calls UpdateToTargetsContextFunc, if set.
+func (m *MockFeed) UpdateToTargetsContext(ctx context.Context, activity stream.Activity, opts ...stream.UpdateToTargetsOption) error {
+	m.record("UpdateToTargetsContext", activity, opts)
+	if m.UpdateToTargetsContextFunc != nil {
+		return m.UpdateToTargetsContextFunc(ctx, activity, opts...)
+	}
+	return nil
+}
+
+// RealtimeToken records the call and calls RealtimeTokenFunc, if set.
+func (m *MockFeed) RealtimeToken(readonly bool) string {
+	m.record("RealtimeToken", readonly)
+	if m.RealtimeTokenFunc != nil {
+		return m.RealtimeTokenFunc(readonly)
+	}
+	return ""
+}
+
+// MockFlatFeed is a mock stream.FlatFeedInterface
+// recording the calls it receives, to be created with NewMockFlatFeed. It
+// behaves like MockFeed.
+type MockFlatFeed struct {
+	MockFeed
+
+	GetActivitiesFunc                           func(...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error)
+	GetActivitiesContextFunc                    func(context.Context, ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error)
+	GetNextPageActivitiesFunc                   func(*stream.FlatFeedResponse) (*stream.FlatFeedResponse, error)
+	GetNextPageActivitiesContextFunc            func(context.Context, *stream.FlatFeedResponse) (*stream.FlatFeedResponse, error)
+	GetActivitiesWithRankingFunc                func(string, ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error)
+	GetActivitiesWithRankingContextFunc         func(context.Context, string, ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error)
+	GetFollowersFunc                            func(...stream.FollowersOption) (*stream.FollowersResponse, error)
+	GetFollowersContextFunc                     func(context.Context, ...stream.FollowersOption) (*stream.FollowersResponse, error)
+	GetEnrichedActivitiesFunc                   func(...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error)
+	GetEnrichedActivitiesContextFunc            func(context.Context, ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error)
+	GetNextPageEnrichedActivitiesFunc           func(*stream.EnrichedFlatFeedResponse) (*stream.EnrichedFlatFeedResponse, error)
+	GetNextPageEnrichedActivitiesContextFunc    func(context.Context, *stream.EnrichedFlatFeedResponse) (*stream.EnrichedFlatFeedResponse, error)
+	GetEnrichedActivitiesWithRankingFunc        func(string, ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error)
+	GetEnrichedActivitiesWithRankingContextFunc func(context.Context, string, ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error)
+	GetActivitiesFromCursorFunc                 func(string) (*stream.FlatFeedResponse, error)
+	GetActivitiesFromCursorContextFunc          func(context.Context, string) (*stream.FlatFeedResponse, error)
+	GetEnrichedActivitiesFromCursorFunc         func(string) (*stream.EnrichedFlatFeedResponse, error)
+	GetEnrichedActivitiesFromCursorContextFunc  func(context.Context, string) (*stream.EnrichedFlatFeedResponse, error)
+}
+
+// NewMockFlatFeed returns a new MockFlatFeed with the given slug and user ID.
+func NewMockFlatFeed(slug, userID string) *MockFlatFeed {
+	return &MockFlatFeed{MockFeed: MockFeed{slug: slug, userID: userID}}
+}
+
+// GetActivities records the call and calls GetActivitiesFunc, if set.
+func (m *MockFlatFeed) GetActivities(opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+	m.record("GetActivities", opts)
+	if m.GetActivitiesFunc != nil {
+		return m.GetActivitiesFunc(opts...)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetActivitiesContext records the call and calls GetActivitiesContextFunc, if set.
+func (m *MockFlatFeed) GetActivitiesContext(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+	m.record("GetActivitiesContext", opts)
+	if m.GetActivitiesContextFunc != nil {
+		return m.GetActivitiesContextFunc(ctx, opts...)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetNextPageActivities records the call and calls GetNextPageActivitiesFunc, if set.
+func (m *MockFlatFeed) GetNextPageActivities(resp *stream.FlatFeedResponse) (*stream.FlatFeedResponse, error) {
+	m.record("GetNextPageActivities", resp)
+	if m.GetNextPageActivitiesFunc != nil {
+		return m.GetNextPageActivitiesFunc(resp)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetNextPageActivitiesContext records the call and calls GetNextPageActivitiesContextFunc, if set.
+func (m *MockFlatFeed) GetNextPageActivitiesContext(ctx context.Context, resp *stream.FlatFeedResponse) (*stream.FlatFeedResponse, error) {
+	m.record("GetNextPageActivitiesContext", resp)
+	if m.GetNextPageActivitiesContextFunc != nil {
+		return m.GetNextPageActivitiesContextFunc(ctx, resp)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetActivitiesWithRanking records the call and calls GetActivitiesWithRankingFunc, if set.
+func (m *MockFlatFeed) GetActivitiesWithRanking(ranking string, opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+	m.record("GetActivitiesWithRanking", ranking, opts)
+	if m.GetActivitiesWithRankingFunc != nil {
+		return m.GetActivitiesWithRankingFunc(ranking, opts...)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetActivitiesWithRankingContext records the call and calls GetActivitiesWithRankingContextFunc, if set.
+func (m *MockFlatFeed) GetActivitiesWithRankingContext(ctx context.Context, ranking string, opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+	m.record("GetActivitiesWithRankingContext", ranking, opts)
+	if m.GetActivitiesWithRankingContextFunc != nil {
+		return m.GetActivitiesWithRankingContextFunc(ctx, ranking, opts...)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetFollowers records the call and calls GetFollowersFunc, if set.
+func (m *MockFlatFeed) GetFollowers(opts ...stream.FollowersOption) (*stream.FollowersResponse, error) {
+	m.record("GetFollowers", opts)
+	if m.GetFollowersFunc != nil {
+		return m.GetFollowersFunc(opts...)
+	}
+	return new(stream.FollowersResponse), nil
+}
+
+// GetFollowersContext records the call and calls GetFollowersContextFunc, if set.
+func (m *MockFlatFeed) GetFollowersContext(ctx context.Context, opts ...stream.FollowersOption) (*stream.FollowersResponse, error) {
+	m.record("GetFollowersContext", opts)
+	if m.GetFollowersContextFunc != nil {
+		return m.GetFollowersContextFunc(ctx, opts...)
+	}
+	return new(stream.FollowersResponse), nil
+}
+
+// GetEnrichedActivities records the call and calls GetEnrichedActivitiesFunc, if set.
+func (m *MockFlatFeed) GetEnrichedActivities(opts ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetEnrichedActivities", opts)
+	if m.GetEnrichedActivitiesFunc != nil {
+		return m.GetEnrichedActivitiesFunc(opts...)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetEnrichedActivitiesContext records the call and calls GetEnrichedActivitiesContextFunc, if set.
+func (m *MockFlatFeed) GetEnrichedActivitiesContext(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetEnrichedActivitiesContext", opts)
+	if m.GetEnrichedActivitiesContextFunc != nil {
+		return m.GetEnrichedActivitiesContextFunc(ctx, opts...)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetNextPageEnrichedActivities records the call and calls GetNextPageEnrichedActivitiesFunc, if set.
+func (m *MockFlatFeed) GetNextPageEnrichedActivities(resp *stream.EnrichedFlatFeedResponse) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetNextPageEnrichedActivities", resp)
+	if m.GetNextPageEnrichedActivitiesFunc != nil {
+		return m.GetNextPageEnrichedActivitiesFunc(resp)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetNextPageEnrichedActivitiesContext records the call and calls GetNextPageEnrichedActivitiesContextFunc, if set.
+func (m *MockFlatFeed) GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *stream.EnrichedFlatFeedResponse) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetNextPageEnrichedActivitiesContext", resp)
+	if m.GetNextPageEnrichedActivitiesContextFunc != nil {
+		return m.GetNextPageEnrichedActivitiesContextFunc(ctx, resp)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetEnrichedActivitiesWithRanking records the call and calls GetEnrichedActivitiesWithRankingFunc, if set.
+func (m *MockFlatFeed) GetEnrichedActivitiesWithRanking(ranking string, opts ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetEnrichedActivitiesWithRanking", ranking, opts)
+	if m.GetEnrichedActivitiesWithRankingFunc != nil {
+		return m.GetEnrichedActivitiesWithRankingFunc(ranking, opts...)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetEnrichedActivitiesWithRankingContext records the call and calls GetEnrichedActivitiesWithRankingContextFunc, if set.
+func (m *MockFlatFeed) GetEnrichedActivitiesWithRankingContext(ctx context.Context, ranking string, opts ...stream.GetActivitiesOption) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetEnrichedActivitiesWithRankingContext", ranking, opts)
+	if m.GetEnrichedActivitiesWithRankingContextFunc != nil {
+		return m.GetEnrichedActivitiesWithRankingContextFunc(ctx, ranking, opts...)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetActivitiesFromCursor records the call and calls GetActivitiesFromCursorFunc, if set.
+func (m *MockFlatFeed) GetActivitiesFromCursor(cursor string) (*stream.FlatFeedResponse, error) {
+	m.record("GetActivitiesFromCursor", cursor)
+	if m.GetActivitiesFromCursorFunc != nil {
+		return m.GetActivitiesFromCursorFunc(cursor)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetActivitiesFromCursorContext records the call and calls GetActivitiesFromCursorContextFunc, if set.
+func (m *MockFlatFeed) GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*stream.FlatFeedResponse, error) {
+	m.record("GetActivitiesFromCursorContext", cursor)
+	if m.GetActivitiesFromCursorContextFunc != nil {
+		return m.GetActivitiesFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.FlatFeedResponse), nil
+}
+
+// GetEnrichedActivitiesFromCursor records the call and calls GetEnrichedActivitiesFromCursorFunc, if set.
+func (m *MockFlatFeed) GetEnrichedActivitiesFromCursor(cursor string) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetEnrichedActivitiesFromCursor", cursor)
+	if m.GetEnrichedActivitiesFromCursorFunc != nil {
+		return m.GetEnrichedActivitiesFromCursorFunc(cursor)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// GetEnrichedActivitiesFromCursorContext records the call and calls GetEnrichedActivitiesFromCursorContextFunc, if set.
+func (m *MockFlatFeed) GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*stream.EnrichedFlatFeedResponse, error) {
+	m.record("GetEnrichedActivitiesFromCursorContext", cursor)
+	if m.GetEnrichedActivitiesFromCursorContextFunc != nil {
+		return m.GetEnrichedActivitiesFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.EnrichedFlatFeedResponse), nil
+}
+
+// MockAggregatedFeed is a mock stream.AggregatedFeedInterface
+// recording the calls it receives, to be created with NewMockAggregatedFeed. It
+// behaves like MockFeed.
+type MockAggregatedFeed struct {
+	MockFeed
+
+	GetActivitiesFunc                          func(...stream.GetActivitiesOption) (*stream.AggregatedFeedResponse, error)
+	GetActivitiesContextFunc                   func(context.Context, ...stream.GetActivitiesOption) (*stream.AggregatedFeedResponse, error)
+	GetNextPageActivitiesFunc                  func(*stream.AggregatedFeedResponse) (*stream.AggregatedFeedResponse, error)
+	GetNextPageActivitiesContextFunc           func(context.Context, *stream.AggregatedFeedResponse) (*stream.AggregatedFeedResponse, error)
+	GetEnrichedActivitiesFunc                  func(...stream.GetActivitiesOption) (*stream.EnrichedAggregatedFeedResponse, error)
+	GetEnrichedActivitiesContextFunc           func(context.Context, ...stream.GetActivitiesOption) (*stream.EnrichedAggregatedFeedResponse, error)
+	GetNextPageEnrichedActivitiesFunc          func(*stream.EnrichedAggregatedFeedResponse) (*stream.EnrichedAggregatedFeedResponse, error)
+	GetNextPageEnrichedActivitiesContextFunc   func(context.Context, *stream.EnrichedAggregatedFeedResponse) (*stream.EnrichedAggregatedFeedResponse, error)
+	GetActivitiesFromCursorFunc                func(string) (*stream.AggregatedFeedResponse, error)
+	GetActivitiesFromCursorContextFunc         func(context.Context, string) (*stream.AggregatedFeedResponse, error)
+	GetEnrichedActivitiesFromCursorFunc        func(string) (*stream.EnrichedAggregatedFeedResponse, error)
+	GetEnrichedActivitiesFromCursorContextFunc func(context.Context, string) (*stream.EnrichedAggregatedFeedResponse, error)
+}
+
+// NewMockAggregatedFeed returns a new MockAggregatedFeed with the given slug and user ID.
+func NewMockAggregatedFeed(slug, userID string) *MockAggregatedFeed {
+	return &MockAggregatedFeed{MockFeed: MockFeed{slug: slug, userID: userID}}
+}
+
+// GetActivities records the call and calls GetActivitiesFunc, if set.
+func (m *MockAggregatedFeed) GetActivities(opts ...stream.GetActivitiesOption) (*stream.AggregatedFeedResponse, error) {
+	m.record("GetActivities", opts)
+	if m.GetActivitiesFunc != nil {
+		return m.GetActivitiesFunc(opts...)
+	}
+	return new(stream.AggregatedFeedResponse), nil
+}
+
+// GetActivitiesContext records the call and calls GetActivitiesContextFunc, if set.
+func (m *MockAggregatedFeed) GetActivitiesContext(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.AggregatedFeedResponse, error) {
+	m.record("GetActivitiesContext", opts)
+	if m.GetActivitiesContextFunc != nil {
+		return m.GetActivitiesContextFunc(ctx, opts...)
+	}
+	return new(stream.AggregatedFeedResponse), nil
+}
+
+// GetNextPageActivities records the call and calls GetNextPageActivitiesFunc, if set.
+func (m *MockAggregatedFeed) GetNextPageActivities(resp *stream.AggregatedFeedResponse) (*stream.AggregatedFeedResponse, error) {
+	m.record("GetNextPageActivities", resp)
+	if m.GetNextPageActivitiesFunc != nil {
+		return m.GetNextPageActivitiesFunc(resp)
+	}
+	return new(stream.AggregatedFeedResponse), nil
+}
+
+// GetNextPageActivitiesContext records the call and calls GetNextPageActivitiesContextFunc, if set.
+func (m *MockAggregatedFeed) GetNextPageActivitiesContext(ctx context.Context, resp *stream.AggregatedFeedResponse) (*stream.AggregatedFeedResponse, error) {
+	m.record("GetNextPageActivitiesContext", resp)
+	if m.GetNextPageActivitiesContextFunc != nil {
+		return m.GetNextPageActivitiesContextFunc(ctx, resp)
+	}
+	return new(stream.AggregatedFeedResponse), nil
+}
+
+// GetEnrichedActivities records the call and calls GetEnrichedActivitiesFunc, if set.
+func (m *MockAggregatedFeed) GetEnrichedActivities(opts ...stream.GetActivitiesOption) (*stream.EnrichedAggregatedFeedResponse, error) {
+	m.record("GetEnrichedActivities", opts)
+	if m.GetEnrichedActivitiesFunc != nil {
+		return m.GetEnrichedActivitiesFunc(opts...)
+	}
+	return new(stream.EnrichedAggregatedFeedResponse), nil
+}
+
+// GetEnrichedActivitiesContext records the call and calls GetEnrichedActivitiesContextFunc, if set.
+func (m *MockAggregatedFeed) GetEnrichedActivitiesContext(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.EnrichedAggregatedFeedResponse, error) {
+	m.record("GetEnrichedActivitiesContext", opts)
+	if m.GetEnrichedActivitiesContextFunc != nil {
+		return m.GetEnrichedActivitiesContextFunc(ctx, opts...)
+	}
+	return new(stream.EnrichedAggregatedFeedResponse), nil
+}
+
+// GetNextPageEnrichedActivities records the call and calls GetNextPageEnrichedActivitiesFunc, if set.
+func (m *MockAggregatedFeed) GetNextPageEnrichedActivities(resp *stream.EnrichedAggregatedFeedResponse) (*stream.EnrichedAggregatedFeedResponse, error) {
+	m.record("GetNextPageEnrichedActivities", resp)
+	if m.GetNextPageEnrichedActivitiesFunc != nil {
+		return m.GetNextPageEnrichedActivitiesFunc(resp)
+	}
+	return new(stream.EnrichedAggregatedFeedResponse), nil
+}
+
+// GetNextPageEnrichedActivitiesContext records the call and calls GetNextPageEnrichedActivitiesContextFunc, if set.
+func (m *MockAggregatedFeed) GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *stream.EnrichedAggregatedFeedResponse) (*stream.EnrichedAggregatedFeedResponse, error) {
+	m.record("GetNextPageEnrichedActivitiesContext", resp)
+	if m.GetNextPageEnrichedActivitiesContextFunc != nil {
+		return m.GetNextPageEnrichedActivitiesContextFunc(ctx, resp)
+	}
+	return new(stream.EnrichedAggregatedFeedResponse), nil
+}
+
+// GetActivitiesFromCursor records the call and calls GetActivitiesFromCursorFunc, if set.
+func (m *MockAggregatedFeed) GetActivitiesFromCursor(cursor string) (*stream.AggregatedFeedResponse, error) {
+	m.record("GetActivitiesFromCursor", cursor)
+	if m.GetActivitiesFromCursorFunc != nil {
+		return m.GetActivitiesFromCursorFunc(cursor)
+	}
+	return new(stream.AggregatedFeedResponse), nil
+}
+
+// GetActivitiesFromCursorContext records the call and calls GetActivitiesFromCursorContextFunc, if set.
+func (m *MockAggregatedFeed) GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*stream.AggregatedFeedResponse, error) {
+	m.record("GetActivitiesFromCursorContext", cursor)
+	if m.GetActivitiesFromCursorContextFunc != nil {
+		return m.GetActivitiesFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.AggregatedFeedResponse), nil
+}
+
+// GetEnrichedActivitiesFromCursor records the call and calls GetEnrichedActivitiesFromCursorFunc, if set.
+func (m *MockAggregatedFeed) GetEnrichedActivitiesFromCursor(cursor string) (*stream.EnrichedAggregatedFeedResponse, error) {
+	m.record("GetEnrichedActivitiesFromCursor", cursor)
+	if m.GetEnrichedActivitiesFromCursorFunc != nil {
+		return m.GetEnrichedActivitiesFromCursorFunc(cursor)
+	}
+	return new(stream.EnrichedAggregatedFeedResponse), nil
+}
+
+// GetEnrichedActivitiesFromCursorContext records the call and calls GetEnrichedActivitiesFromCursorContextFunc, if set.
+func (m *MockAggregatedFeed) GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*stream.EnrichedAggregatedFeedResponse, error) {
+	m.record("GetEnrichedActivitiesFromCursorContext", cursor)
+	if m.GetEnrichedActivitiesFromCursorContextFunc != nil {
+		return m.GetEnrichedActivitiesFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.EnrichedAggregatedFeedResponse), nil
+}
+
+// MockNotificationFeed is a mock stream.NotificationFeedInterface
+// recording the calls it receives, to be created with NewMockNotificationFeed. It
+// behaves like MockFeed.
+type MockNotificationFeed struct {
+	MockFeed
+
+	GetActivitiesFunc                          func(...stream.GetActivitiesOption) (*stream.NotificationFeedResponse, error)
+	GetActivitiesContextFunc                   func(context.Context, ...stream.GetActivitiesOption) (*stream.NotificationFeedResponse, error)
+	GetNextPageActivitiesFunc                  func(*stream.NotificationFeedResponse) (*stream.NotificationFeedResponse, error)
+	GetNextPageActivitiesContextFunc           func(context.Context, *stream.NotificationFeedResponse) (*stream.NotificationFeedResponse, error)
+	GetEnrichedActivitiesFunc                  func(...stream.GetActivitiesOption) (*stream.EnrichedNotificationFeedResponse, error)
+	GetEnrichedActivitiesContextFunc           func(context.Context, ...stream.GetActivitiesOption) (*stream.EnrichedNotificationFeedResponse, error)
+	GetNextPageEnrichedActivitiesFunc          func(*stream.EnrichedNotificationFeedResponse) (*stream.EnrichedNotificationFeedResponse, error)
+	GetNextPageEnrichedActivitiesContextFunc   func(context.Context, *stream.EnrichedNotificationFeedResponse) (*stream.EnrichedNotificationFeedResponse, error)
+	GetActivitiesFromCursorFunc                func(string) (*stream.NotificationFeedResponse, error)
+	GetActivitiesFromCursorContextFunc         func(context.Context, string) (*stream.NotificationFeedResponse, error)
+	GetEnrichedActivitiesFromCursorFunc        func(string) (*stream.EnrichedNotificationFeedResponse, error)
+	GetEnrichedActivitiesFromCursorContextFunc func(context.Context, string) (*stream.EnrichedNotificationFeedResponse, error)
+}
+
+// NewMockNotificationFeed returns a new MockNotificationFeed with the given slug and user ID.
+func NewMockNotificationFeed(slug, userID string) *MockNotificationFeed {
+	return &MockNotificationFeed{MockFeed: MockFeed{slug: slug, userID: userID}}
+}
+
+// GetActivities records the call and calls GetActivitiesFunc, if set.
+func (m *MockNotificationFeed) GetActivities(opts ...stream.GetActivitiesOption) (*stream.NotificationFeedResponse, error) {
+	m.record("GetActivities", opts)
+	if m.GetActivitiesFunc != nil {
+		return m.GetActivitiesFunc(opts...)
+	}
+	return new(stream.NotificationFeedResponse), nil
+}
+
+// GetActivitiesContext records the call and calls GetActivitiesContextFunc, if set.
+func (m *MockNotificationFeed) GetActivitiesContext(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.NotificationFeedResponse, error) {
+	m.record("GetActivitiesContext", opts)
+	if m.GetActivitiesContextFunc != nil {
+		return m.GetActivitiesContextFunc(ctx, opts...)
+	}
+	return new(stream.NotificationFeedResponse), nil
+}
+
+// GetNextPageActivities records the call and calls GetNextPageActivitiesFunc, if set.
+func (m *MockNotificationFeed) GetNextPageActivities(resp *stream.NotificationFeedResponse) (*stream.NotificationFeedResponse, error) {
+	m.record("GetNextPageActivities", resp)
+	if m.GetNextPageActivitiesFunc != nil {
+		return m.GetNextPageActivitiesFunc(resp)
+	}
+	return new(stream.NotificationFeedResponse), nil
+}
+
+// GetNextPageActivitiesContext records the call and calls GetNextPageActivitiesContextFunc, if set.
+func (m *MockNotificationFeed) GetNextPageActivitiesContext(ctx context.Context, resp *stream.NotificationFeedResponse) (*stream.NotificationFeedResponse, error) {
+	m.record("GetNextPageActivitiesContext", resp)
+	if m.GetNextPageActivitiesContextFunc != nil {
+		return m.GetNextPageActivitiesContextFunc(ctx, resp)
+	}
+	return new(stream.NotificationFeedResponse), nil
+}
+
+// GetEnrichedActivities records the call and calls GetEnrichedActivitiesFunc, if set.
+func (m *MockNotificationFeed) GetEnrichedActivities(opts ...stream.GetActivitiesOption) (*stream.EnrichedNotificationFeedResponse, error) {
+	m.record("GetEnrichedActivities", opts)
+	if m.GetEnrichedActivitiesFunc != nil {
+		return m.GetEnrichedActivitiesFunc(opts...)
+	}
+	return new(stream.EnrichedNotificationFeedResponse), nil
+}
+
+// GetEnrichedActivitiesContext records the call and calls GetEnrichedActivitiesContextFunc, if set.
+func (m *MockNotificationFeed) GetEnrichedActivitiesContext(ctx context.Context, opts ...stream.GetActivitiesOption) (*stream.EnrichedNotificationFeedResponse, error) {
+	m.record("GetEnrichedActivitiesContext", opts)
+	if m.GetEnrichedActivitiesContextFunc != nil {
+		return m.GetEnrichedActivitiesContextFunc(ctx, opts...)
+	}
+	return new(stream.EnrichedNotificationFeedResponse), nil
+}
+
+// GetNextPageEnrichedActivities records the call and calls GetNextPageEnrichedActivitiesFunc, if set.
+func (m *MockNotificationFeed) GetNextPageEnrichedActivities(resp *stream.EnrichedNotificationFeedResponse) (*stream.EnrichedNotificationFeedResponse, error) {
+	m.record("GetNextPageEnrichedActivities", resp)
+	if m.GetNextPageEnrichedActivitiesFunc != nil {
+		return m.GetNextPageEnrichedActivitiesFunc(resp)
+	}
+	return new(stream.EnrichedNotificationFeedResponse), nil
+}
+
+// GetNextPageEnrichedActivitiesContext records the call and calls GetNextPageEnrichedActivitiesContextFunc, if set.
+func (m *MockNotificationFeed) GetNextPageEnrichedActivitiesContext(ctx context.Context, resp *stream.EnrichedNotificationFeedResponse) (*stream.EnrichedNotificationFeedResponse, error) {
+	m.record("GetNextPageEnrichedActivitiesContext", resp)
+	if m.GetNextPageEnrichedActivitiesContextFunc != nil {
+		return m.GetNextPageEnrichedActivitiesContextFunc(ctx, resp)
+	}
+	return new(stream.EnrichedNotificationFeedResponse), nil
+}
+
+// GetActivitiesFromCursor records the call and calls GetActivitiesFromCursorFunc, if set.
+func (m *MockNotificationFeed) GetActivitiesFromCursor(cursor string) (*stream.NotificationFeedResponse, error) {
+	m.record("GetActivitiesFromCursor", cursor)
+	if m.GetActivitiesFromCursorFunc != nil {
+		return m.GetActivitiesFromCursorFunc(cursor)
+	}
+	return new(stream.NotificationFeedResponse), nil
+}
+
+// GetActivitiesFromCursorContext records the call and calls GetActivitiesFromCursorContextFunc, if set.
+func (m *MockNotificationFeed) GetActivitiesFromCursorContext(ctx context.Context, cursor string) (*stream.NotificationFeedResponse, error) {
+	m.record("GetActivitiesFromCursorContext", cursor)
+	if m.GetActivitiesFromCursorContextFunc != nil {
+		return m.GetActivitiesFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.NotificationFeedResponse), nil
+}
+
+// GetEnrichedActivitiesFromCursor records the call and calls GetEnrichedActivitiesFromCursorFunc, if set.
+func (m *MockNotificationFeed) GetEnrichedActivitiesFromCursor(cursor string) (*stream.EnrichedNotificationFeedResponse, error) {
+	m.record("GetEnrichedActivitiesFromCursor", cursor)
+	if m.GetEnrichedActivitiesFromCursorFunc != nil {
+		return m.GetEnrichedActivitiesFromCursorFunc(cursor)
+	}
+	return new(stream.EnrichedNotificationFeedResponse), nil
+}
+
+// GetEnrichedActivitiesFromCursorContext records the call and calls GetEnrichedActivitiesFromCursorContextFunc, if set.
+func (m *MockNotificationFeed) GetEnrichedActivitiesFromCursorContext(ctx context.Context, cursor string) (*stream.EnrichedNotificationFeedResponse, error) {
+	m.record("GetEnrichedActivitiesFromCursorContext", cursor)
+	if m.GetEnrichedActivitiesFromCursorContextFunc != nil {
+		return m.GetEnrichedActivitiesFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.EnrichedNotificationFeedResponse), nil
+}
+
+// MockAnalyticsClient is a mock stream.AnalyticsClientInterface
+// recording the calls it receives. Each method calls the function held by the
+// corresponding field, if set, and otherwise returns an empty response and a
+// nil error.
+type MockAnalyticsClient struct {
+	mockCalls
+
+	TrackEngagementFunc        func(...stream.EngagementEvent) error
+	TrackEngagementContextFunc func(context.Context, ...stream.EngagementEvent) error
+	TrackImpressionFunc        func(stream.ImpressionEventsData) error
+	TrackImpressionContextFunc func(context.Context, stream.ImpressionEventsData) error
+	RedirectAndTrackFunc       func(string, ...map[string]interface{}) (string, error)
+}
+
+// TrackEngagement records the call and calls TrackEngagementFunc, if set.
+func (m *MockAnalyticsClient) TrackEngagement(events ...stream.EngagementEvent) error {
+	m.record("TrackEngagement", events)
+	if m.TrackEngagementFunc != nil {
+		return m.TrackEngagementFunc(events...)
+	}
+	return nil
+}
+
+// TrackEngagementContext records the call and calls TrackEngagementContextFunc, if set.
+func (m *MockAnalyticsClient) TrackEngagementContext(ctx context.Context, events ...stream.EngagementEvent) error {
+	m.record("TrackEngagementContext", events)
+	if m.TrackEngagementContextFunc != nil {
+		return m.TrackEngagementContextFunc(ctx, events...)
+	}
+	return nil
+}
+
+// TrackImpression records the call and calls TrackImpressionFunc, if set.
+func (m *MockAnalyticsClient) TrackImpression(eventsData stream.ImpressionEventsData) error {
+	m.record("TrackImpression", eventsData)
+	if m.TrackImpressionFunc != nil {
+		return m.TrackImpressionFunc(eventsData)
+	}
+	return nil
+}
+
+// TrackImpressionContext records the call and calls TrackImpressionContextFunc, if set.
+func (m *MockAnalyticsClient) TrackImpressionContext(ctx context.Context, eventsData stream.ImpressionEventsData) error {
+	m.record("TrackImpressionContext", eventsData)
+	if m.TrackImpressionContextFunc != nil {
+		return m.TrackImpressionContextFunc(ctx, eventsData)
+	}
+	return nil
+}
+
+// RedirectAndTrack records the call and calls RedirectAndTrackFunc, if set.
+func (m *MockAnalyticsClient) RedirectAndTrack(url string, events ...map[string]interface{}) (string, error) {
+	m.record("RedirectAndTrack", url, events)
+	if m.RedirectAndTrackFunc != nil {
+		return m.RedirectAndTrackFunc(url, events...)
+	}
+	return "", nil
+}
+
+// MockCollectionsClient is a mock stream.CollectionsClientInterface
+// recording the calls it receives. Each method calls the function held by the
+// corresponding field, if set, and otherwise returns an empty response and a
+// nil error.
+type MockCollectionsClient struct {
+	mockCalls
+
+	UpsertFunc                   func(string, ...stream.CollectionObject) error
+	UpsertContextFunc            func(context.Context, string, ...stream.CollectionObject) error
+	SelectFunc                   func(string, ...string) ([]stream.GetCollectionResponseObject, error)
+	SelectContextFunc            func(context.Context, string, ...string) ([]stream.GetCollectionResponseObject, error)
+	SelectWithMissingFunc        func(string, ...string) (*stream.SelectCollectionResponse, error)
+	SelectWithMissingContextFunc func(context.Context, string, ...string) (*stream.SelectCollectionResponse, error)
+	DeleteManyFunc               func(string, ...string) error
+	DeleteManyContextFunc        func(context.Context, string, ...string) error
+	AddFunc                      func(string, stream.CollectionObject, ...stream.AddObjectOption) (*stream.CollectionObject, error)
+	AddContextFunc               func(context.Context, string, stream.CollectionObject, ...stream.AddObjectOption) (*stream.CollectionObject, error)
+	GetFunc                      func(string, string) (*stream.CollectionObject, error)
+	GetContextFunc               func(context.Context, string, string) (*stream.CollectionObject, error)
+	UpdateFunc                   func(string, string, map[string]interface{}) (*stream.CollectionObject, error)
+	UpdateContextFunc            func(context.Context, string, string, map[string]interface{}) (*stream.CollectionObject, error)
+	DeleteFunc                   func(string, string) error
+	DeleteContextFunc            func(context.Context, string, string) error
+	CreateReferenceFunc          func(string, string) string
+}
+
+// Upsert records the call and calls UpsertFunc, if set.
+func (m *MockCollectionsClient) Upsert(collection string, objects ...stream.CollectionObject) error {
+	m.record("Upsert", collection, objects)
+	if m.UpsertFunc != nil {
+		return m.UpsertFunc(collection, objects...)
+	}
+	return nil
+}
+
+// UpsertContext records the call and calls UpsertContextFunc, if set.
+func (m *MockCollectionsClient) UpsertContext(ctx context.Context, collection string, objects ...stream.CollectionObject) error {
+	m.record("UpsertContext", collection, objects)
+	if m.UpsertContextFunc != nil {
+		return m.UpsertContextFunc(ctx, collection, objects...)
+	}
+	return nil
+}
+
+// Select records the call and calls SelectFunc, if set.
+func (m *MockCollectionsClient) Select(collection string, ids ...string) ([]stream.GetCollectionResponseObject, error) {
+	m.record("Select", collection, ids)
+	if m.SelectFunc != nil {
+		return m.SelectFunc(collection, ids...)
+	}
+	return nil, nil
+}
+
+// SelectContext records the call and calls SelectContextFunc, if set.
+func (m *MockCollectionsClient) SelectContext(ctx context.Context, collection string, ids ...string) ([]stream.GetCollectionResponseObject, error) {
+	m.record("SelectContext", collection, ids)
+	if m.SelectContextFunc != nil {
+		return m.SelectContextFunc(ctx, collection, ids...)
+	}
+	return nil, nil
+}
+
+// SelectWithMissing records the call and calls SelectWithMissingFunc, if set.
+func (m *MockCollectionsClient) SelectWithMissing(collection string, ids ...string) (*stream.SelectCollectionResponse, error) {
+	m.record("SelectWithMissing", collection, ids)
+	if m.SelectWithMissingFunc != nil {
+		return m.SelectWithMissingFunc(collection, ids...)
+	}
+	return new(stream.SelectCollectionResponse), nil
+}
+
+// SelectWithMissingContext records the call and calls SelectWithMissingContextFunc, if set.
+func (m *MockCollectionsClient) SelectWithMissingContext(ctx context.Context, collection string, ids ...string) (*stream.SelectCollectionResponse, error) {
+	m.record("SelectWithMissingContext", collection, ids)
+	if m.SelectWithMissingContextFunc != nil {
+		return m.SelectWithMissingContextFunc(ctx, collection, ids...)
+	}
+	return new(stream.SelectCollectionResponse), nil
+}
+
+// DeleteMany records the call and calls DeleteManyFunc, if set.
+func (m *MockCollectionsClient) DeleteMany(collection string, ids ...string) error {
+	m.record("DeleteMany", collection, ids)
+	if m.DeleteManyFunc != nil {
+		return m.DeleteManyFunc(collection, ids...)
+	}
+	return nil
+}
+
+// DeleteManyContext records the call and calls DeleteManyContextFunc, if set.
+func (m *MockCollectionsClient) DeleteManyContext(ctx context.Context, collection string, ids ...string) error {
+	m.record("DeleteManyContext", collection, ids)
+	if m.DeleteManyContextFunc != nil {
+		return m.DeleteManyContextFunc(ctx, collection, ids...)
+	}
+	return nil
+}
+
+// Add records the call and calls AddFunc, if set.
+func (m *MockCollectionsClient) Add(collection string, object stream.CollectionObject, opts ...stream.AddObjectOption) (*stream.CollectionObject, error) {
+	m.record("Add", collection, object, opts)
+	if m.AddFunc != nil {
+		return m.AddFunc(collection, object, opts...)
+	}
+	return new(stream.CollectionObject), nil
+}
+
+// AddContext records the call and calls AddContextFunc, if set.
+func (m *MockCollectionsClient) AddContext(ctx context.Context, collection string, object stream.CollectionObject, opts ...stream.AddObjectOption) (*stream.CollectionObject, error) {
+	m.record("AddContext", collection, object, opts)
+	if m.AddContextFunc != nil {
+		return m.AddContextFunc(ctx, collection, object, opts...)
+	}
+	return new(stream.CollectionObject), nil
+}
+
+// Get records the call and calls GetFunc, if set.
+func (m *MockCollectionsClient) Get(collection string, id string) (*stream.CollectionObject, error) {
+	m.record("Get", collection, id)
+	if m.GetFunc != nil {
+		return m.GetFunc(collection, id)
+	}
+	return new(stream.CollectionObject), nil
+}
+
+// GetContext records the call and calls GetContextFunc, if set.
+func (m *MockCollectionsClient) GetContext(ctx context.Context, collection string, id string) (*stream.CollectionObject, error) {
+	m.record("GetContext", collection, id)
+	if m.GetContextFunc != nil {
+		return m.GetContextFunc(ctx, collection, id)
+	}
+	return new(stream.CollectionObject), nil
+}
+
+// Update records the call and calls UpdateFunc, if set.
+func (m *MockCollectionsClient) Update(collection string, id string, data map[string]interface{}) (*stream.CollectionObject, error) {
+	m.record("Update", collection, id, data)
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(collection, id, data)
+	}
+	return new(stream.CollectionObject), nil
+}
+
+// UpdateContext records the call and calls UpdateContextFunc, if set.
+func (m *MockCollectionsClient) UpdateContext(ctx context.Context, collection string, id string, data map[string]interface{}) (*stream.CollectionObject, error) {
+	m.record("UpdateContext", collection, id, data)
+	if m.UpdateContextFunc != nil {
+		return m.UpdateContextFunc(ctx, collection, id, data)
+	}
+	return new(stream.CollectionObject), nil
+}
+
+// Delete records the call and calls DeleteFunc, if set.
+func (m *MockCollectionsClient) Delete(collection string, id string) error {
+	m.record("Delete", collection, id)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(collection, id)
+	}
+	return nil
+}
+
+// DeleteContext records the call and calls DeleteContextFunc, if set.
+func (m *MockCollectionsClient) DeleteContext(ctx context.Context, collection string, id string) error {
+	m.record("DeleteContext", collection, id)
+	if m.DeleteContextFunc != nil {
+		return m.DeleteContextFunc(ctx, collection, id)
+	}
+	return nil
+}
+
+// CreateReference records the call and calls CreateReferenceFunc, if set.
+func (m *MockCollectionsClient) CreateReference(collection string, id string) string {
+	m.record("CreateReference", collection, id)
+	if m.CreateReferenceFunc != nil {
+		return m.CreateReferenceFunc(collection, id)
+	}
+	return ""
+}
+
+// MockUsersClient is a mock stream.UsersClientInterface
+// recording the calls it receives. Each method calls the function held by the
+// corresponding field, if set, and otherwise returns an empty response and a
+// nil error.
+type MockUsersClient struct {
+	mockCalls
+
+	AddFunc             func(stream.User, bool) (*stream.User, error)
+	AddContextFunc      func(context.Context, stream.User, bool) (*stream.User, error)
+	UpdateFunc          func(string, map[string]interface{}) (*stream.User, error)
+	UpdateContextFunc   func(context.Context, string, map[string]interface{}) (*stream.User, error)
+	GetFunc             func(string) (*stream.User, error)
+	GetContextFunc      func(context.Context, string) (*stream.User, error)
+	DeleteFunc          func(string) error
+	DeleteContextFunc   func(context.Context, string) error
+	CreateReferenceFunc func(string) string
+}
+
+// Add records the call and calls AddFunc, if set.
+func (m *MockUsersClient) Add(user stream.User, getOrCreate bool) (*stream.User, error) {
+	m.record("Add", user, getOrCreate)
+	if m.AddFunc != nil {
+		return m.AddFunc(user, getOrCreate)
+	}
+	return new(stream.User), nil
+}
+
+// AddContext records the call and calls AddContextFunc, if set.
+func (m *MockUsersClient) AddContext(ctx context.Context, user stream.User, getOrCreate bool) (*stream.User, error) {
+	m.record("AddContext", user, getOrCreate)
+	if m.AddContextFunc != nil {
+		return m.AddContextFunc(ctx, user, getOrCreate)
+	}
+	return new(stream.User), nil
+}
+
+// Update records the call and calls UpdateFunc, if set.
+func (m *MockUsersClient) Update(id string, data map[string]interface{}) (*stream.User, error) {
+	m.record("Update", id, data)
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(id, data)
+	}
+	return new(stream.User), nil
+}
+
+// UpdateContext records the call and calls UpdateContextFunc, if set.
+func (m *MockUsersClient) UpdateContext(ctx context.Context, id string, data map[string]interface{}) (*stream.User, error) {
+	m.record("UpdateContext", id, data)
+	if m.UpdateContextFunc != nil {
+		return m.UpdateContextFunc(ctx, id, data)
+	}
+	return new(stream.User), nil
+}
+
+// Get records the call and calls GetFunc, if set.
+func (m *MockUsersClient) Get(id string) (*stream.User, error) {
+	m.record("Get", id)
+	if m.GetFunc != nil {
+		return m.GetFunc(id)
+	}
+	return new(stream.User), nil
+}
+
+// GetContext records the call and calls GetContextFunc, if set.
+func (m *MockUsersClient) GetContext(ctx context.Context, id string) (*stream.User, error) {
+	m.record("GetContext", id)
+	if m.GetContextFunc != nil {
+		return m.GetContextFunc(ctx, id)
+	}
+	return new(stream.User), nil
+}
+
+// Delete records the call and calls DeleteFunc, if set.
+func (m *MockUsersClient) Delete(id string) error {
+	m.record("Delete", id)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(id)
+	}
+	return nil
+}
+
+// DeleteContext records the call and calls DeleteContextFunc, if set.
+func (m *MockUsersClient) DeleteContext(ctx context.Context, id string) error {
+	m.record("DeleteContext", id)
+	if m.DeleteContextFunc != nil {
+		return m.DeleteContextFunc(ctx, id)
+	}
+	return nil
+}
+
+// CreateReference records the call and calls CreateReferenceFunc, if set.
+func (m *MockUsersClient) CreateReference(id string) string {
+	m.record("CreateReference", id)
+	if m.CreateReferenceFunc != nil {
+		return m.CreateReferenceFunc(id)
+	}
+	return ""
+}
+
+// MockReactionsClient is a mock stream.ReactionsClientInterface
+// recording the calls it receives. Each method calls the function held by the
+// corresponding field, if set, and otherwise returns an empty response and a
+// nil error.
+type MockReactionsClient struct {
+	mockCalls
+
+	AddFunc                                 func(stream.AddReactionRequestObject) (*stream.Reaction, error)
+	AddContextFunc                          func(context.Context, stream.AddReactionRequestObject) (*stream.Reaction, error)
+	AddChildFunc                            func(string, stream.AddReactionRequestObject) (*stream.Reaction, error)
+	AddChildContextFunc                     func(context.Context, string, stream.AddReactionRequestObject) (*stream.Reaction, error)
+	UpdateFunc                              func(string, map[string]interface{}, []string) (*stream.Reaction, error)
+	UpdateContextFunc                       func(context.Context, string, map[string]interface{}, []string) (*stream.Reaction, error)
+	GetFunc                                 func(string) (*stream.Reaction, error)
+	GetContextFunc                          func(context.Context, string) (*stream.Reaction, error)
+	DeleteFunc                              func(string) error
+	DeleteContextFunc                       func(context.Context, string) error
+	FilterFunc                              func(stream.FilterReactionsAttribute, ...stream.FilterReactionsOption) (*stream.FilterReactionResponse, error)
+	FilterContextFunc                       func(context.Context, stream.FilterReactionsAttribute, ...stream.FilterReactionsOption) (*stream.FilterReactionResponse, error)
+	GetNextPageFilteredReactionsFunc        func(*stream.FilterReactionResponse) (*stream.FilterReactionResponse, error)
+	GetNextPageFilteredReactionsContextFunc func(context.Context, *stream.FilterReactionResponse) (*stream.FilterReactionResponse, error)
+	FilterFromCursorFunc                    func(string) (*stream.FilterReactionResponse, error)
+	FilterFromCursorContextFunc             func(context.Context, string) (*stream.FilterReactionResponse, error)
+}
+
+// Add records the call and calls AddFunc, if set.
+func (m *MockReactionsClient) Add(r stream.AddReactionRequestObject) (*stream.Reaction, error) {
+	m.record("Add", r)
+	if m.AddFunc != nil {
+		return m.AddFunc(r)
+	}
+	return new(stream.Reaction), nil
+}
+
+// AddContext records the call and calls AddContextFunc, if set.
+func (m *MockReactionsClient) AddContext(ctx context.Context, r stream.AddReactionRequestObject) (*stream.Reaction, error) {
+	m.record("AddContext", r)
+	if m.AddContextFunc != nil {
+		return m.AddContextFunc(ctx, r)
+	}
+	return new(stream.Reaction), nil
+}
+
+// AddChild records the call and calls AddChildFunc, if set.
+func (m *MockReactionsClient) AddChild(parentID string, r stream.AddReactionRequestObject) (*stream.Reaction, error) {
+	m.record("AddChild", parentID, r)
+	if m.AddChildFunc != nil {
+		return m.AddChildFunc(parentID, r)
+	}
+	return new(stream.Reaction), nil
+}
+
+// AddChildContext records the call and calls AddChildContextFunc, if set.
+func (m *MockReactionsClient) AddChildContext(ctx context.Context, parentID string, r stream.AddReactionRequestObject) (*stream.Reaction, error) {
+	m.record("AddChildContext", parentID, r)
+	if m.AddChildContextFunc != nil {
+		return m.AddChildContextFunc(ctx, parentID, r)
+	}
+	return new(stream.Reaction), nil
+}
+
+// Update records the call and calls UpdateFunc, if set.
+func (m *MockReactionsClient) Update(id string, data map[string]interface{}, targetFeeds []string) (*stream.Reaction, error) {
+	m.record("Update", id, data, targetFeeds)
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(id, data, targetFeeds)
+	}
+	return new(stream.Reaction), nil
+}
+
+// UpdateContext records the call and calls UpdateContextFunc, if set.
+func (m *MockReactionsClient) UpdateContext(ctx context.Context, id string, data map[string]interface{}, targetFeeds []string) (*stream.Reaction, error) {
+	m.record("UpdateContext", id, data, targetFeeds)
+	if m.UpdateContextFunc != nil {
+		return m.UpdateContextFunc(ctx, id, data, targetFeeds)
+	}
+	return new(stream.Reaction), nil
+}
+
+// Get records the call and calls GetFunc, if set.
+func (m *MockReactionsClient) Get(id string) (*stream.Reaction, error) {
+	m.record("Get", id)
+	if m.GetFunc != nil {
+		return m.GetFunc(id)
+	}
+	return new(stream.Reaction), nil
+}
+
+// GetContext records the call and calls GetContextFunc, if set.
+func (m *MockReactionsClient) GetContext(ctx context.Context, id string) (*stream.Reaction, error) {
+	m.record("GetContext", id)
+	if m.GetContextFunc != nil {
+		return m.GetContextFunc(ctx, id)
+	}
+	return new(stream.Reaction), nil
+}
+
+// Delete records the call and calls DeleteFunc, if set.
+func (m *MockReactionsClient) Delete(id string) error {
+	m.record("Delete", id)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(id)
+	}
+	return nil
+}
+
+// DeleteContext records the call and calls DeleteContextFunc, if set.
+func (m *MockReactionsClient) DeleteContext(ctx context.Context, id string) error {
+	m.record("DeleteContext", id)
+	if m.DeleteContextFunc != nil {
+		return m.DeleteContextFunc(ctx, id)
+	}
+	return nil
+}
+
+// Filter records the call and calls FilterFunc, if set.
+func (m *MockReactionsClient) Filter(attr stream.FilterReactionsAttribute, opts ...stream.FilterReactionsOption) (*stream.FilterReactionResponse, error) {
+	m.record("Filter", attr, opts)
+	if m.FilterFunc != nil {
+		return m.FilterFunc(attr, opts...)
+	}
+	return new(stream.FilterReactionResponse), nil
+}
+
+// FilterContext records the call and calls FilterContextFunc, if set.
+func (m *MockReactionsClient) FilterContext(ctx context.Context, attr stream.FilterReactionsAttribute, opts ...stream.FilterReactionsOption) (*stream.FilterReactionResponse, error) {
+	m.record("FilterContext", attr, opts)
+	if m.FilterContextFunc != nil {
+		return m.FilterContextFunc(ctx, attr, opts...)
+	}
+	return new(stream.FilterReactionResponse), nil
+}
+
+// GetNextPageFilteredReactions records the call and calls GetNextPageFilteredReactionsFunc, if set.
+func (m *MockReactionsClient) GetNextPageFilteredReactions(resp *stream.FilterReactionResponse) (*stream.FilterReactionResponse, error) {
+	m.record("GetNextPageFilteredReactions", resp)
+	if m.GetNextPageFilteredReactionsFunc != nil {
+		return m.GetNextPageFilteredReactionsFunc(resp)
+	}
+	return new(stream.FilterReactionResponse), nil
+}
+
+// GetNextPageFilteredReactionsContext records the call and calls GetNextPageFilteredReactionsContextFunc, if set.
+func (m *MockReactionsClient) GetNextPageFilteredReactionsContext(ctx context.Context, resp *stream.FilterReactionResponse) (*stream.FilterReactionResponse, error) {
+	m.record("GetNextPageFilteredReactionsContext", resp)
+	if m.GetNextPageFilteredReactionsContextFunc != nil {
+		return m.GetNextPageFilteredReactionsContextFunc(ctx, resp)
+	}
+	return new(stream.FilterReactionResponse), nil
+}
+
+// FilterFromCursor records the call and calls FilterFromCursorFunc, if set.
+func (m *MockReactionsClient) FilterFromCursor(cursor string) (*stream.FilterReactionResponse, error) {
+	m.record("FilterFromCursor", cursor)
+	if m.FilterFromCursorFunc != nil {
+		return m.FilterFromCursorFunc(cursor)
+	}
+	return new(stream.FilterReactionResponse), nil
+}
+
+// FilterFromCursorContext records the call and calls FilterFromCursorContextFunc, if set.
+func (m *MockReactionsClient) FilterFromCursorContext(ctx context.Context, cursor string) (*stream.FilterReactionResponse, error) {
+	m.record("FilterFromCursorContext", cursor)
+	if m.FilterFromCursorContextFunc != nil {
+		return m.FilterFromCursorContextFunc(ctx, cursor)
+	}
+	return new(stream.FilterReactionResponse), nil
+}
+
+// MockPersonalizationClient is a mock stream.PersonalizationClientInterface
+// recording the calls it receives. Each method calls the function held by the
+// corresponding field, if set, and otherwise returns an empty response and a
+// nil error.
+type MockPersonalizationClient struct {
+	mockCalls
+
+	GetFunc           func(string, map[string]interface{}) (*stream.PersonalizationResponse, error)
+	GetContextFunc    func(context.Context, string, map[string]interface{}) (*stream.PersonalizationResponse, error)
+	PostFunc          func(string, map[string]interface{}, map[string]interface{}) error
+	PostContextFunc   func(context.Context, string, map[string]interface{}, map[string]interface{}) error
+	DeleteFunc        func(string, map[string]interface{}) error
+	DeleteContextFunc func(context.Context, string, map[string]interface{}) error
+}
+
+// Get records the call and calls GetFunc, if set.
+func (m *MockPersonalizationClient) Get(resource string, params map[string]interface{}) (*stream.PersonalizationResponse, error) {
+	m.record("Get", resource, params)
+	if m.GetFunc != nil {
+		return m.GetFunc(resource, params)
+	}
+	return new(stream.PersonalizationResponse), nil
+}
+
+// GetContext records the call and calls GetContextFunc, if set.
+func (m *MockPersonalizationClient) GetContext(ctx context.Context, resource string, params map[string]interface{}) (*stream.PersonalizationResponse, error) {
+	m.record("GetContext", resource, params)
+	if m.GetContextFunc != nil {
+		return m.GetContextFunc(ctx, resource, params)
+	}
+	return new(stream.PersonalizationResponse), nil
+}
+
+// Post records the call and calls PostFunc, if set.
+func (m *MockPersonalizationClient) Post(resource string, params map[string]interface{}, data map[string]interface{}) error {
+	m.record("Post", resource, params, data)
+	if m.PostFunc != nil {
+		return m.PostFunc(resource, params, data)
+	}
+	return nil
+}
+
+// PostContext records the call and calls PostContextFunc, if set.
+func (m *MockPersonalizationClient) PostContext(ctx context.Context, resource string, params map[string]interface{}, data map[string]interface{}) error {
+	m.record("PostContext", resource, params, data)
+	if m.PostContextFunc != nil {
+		return m.PostContextFunc(ctx, resource, params, data)
+	}
+	return nil
+}
+
+// Delete records the call and calls DeleteFunc, if set.
+func (m *MockPersonalizationClient) Delete(resource string, params map[string]interface{}) error {
+	m.record("Delete", resource, params)
+	if m.DeleteFunc != nil {
+		return m.DeleteFunc(resource, params)
+	}
+	return nil
+}
+
+// DeleteContext records the call and calls DeleteContextFunc, if set.
+func (m *MockPersonalizationClient) DeleteContext(ctx context.Context, resource string, params map[string]interface{}) error {
+	m.record("DeleteContext", resource, params)
+	if m.DeleteContextFunc != nil {
+		return m.DeleteContextFunc(ctx, resource, params)
+	}
+	return nil
+}
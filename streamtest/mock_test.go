@@ -0,0 +1,99 @@
+package streamtest_test
+
+import (
+	"errors"
+	"testing"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// likeLatest is a service function depending on stream.ClientInterface, as
+// application code would.
+func likeLatest(client stream.ClientInterface, userID string) (*stream.Reaction, error) {
+	timeline, err := client.FlatFeed("timeline", userID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := timeline.GetActivities(stream.WithActivitiesLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, nil
+	}
+	return client.Reactions().Add(stream.AddReactionRequestObject{
+		Kind:       "like",
+		ActivityID: resp.Results[0].ID,
+		UserID:     userID,
+	})
+}
+
+func TestMockClient(t *testing.T) {
+	client := streamtest.NewMockClient()
+	timeline := client.FlatFeedMock("timeline", "alice")
+	timeline.GetActivitiesFunc = func(opts ...stream.GetActivitiesOption) (*stream.FlatFeedResponse, error) {
+		return &stream.FlatFeedResponse{Results: []stream.Activity{{ID: "123"}}}, nil
+	}
+	client.ReactionsClient.AddFunc = func(r stream.AddReactionRequestObject) (*stream.Reaction, error) {
+		r.ID = "456"
+		return &stream.Reaction{AddReactionRequestObject: r}, nil
+	}
+
+	reaction, err := likeLatest(client, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "456", reaction.ID)
+	assert.Equal(t, "123", reaction.ActivityID)
+
+	assert.Equal(t, []streamtest.MockCall{
+		{Method: "FlatFeed", Args: []interface{}{"timeline", "alice"}},
+		{Method: "Reactions"},
+	}, client.Calls())
+	require.Len(t, timeline.CallsTo("GetActivities"), 1)
+	assert.Len(t, timeline.CallsTo("GetActivities")[0].Args[0], 1)
+	calls := client.ReactionsClient.CallsTo("Add")
+	require.Len(t, calls, 1)
+	assert.Equal(t, "like", calls[0].Args[0].(stream.AddReactionRequestObject).Kind)
+
+	feed, err := client.FlatFeed("timeline", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, timeline, feed)
+	assert.Equal(t, "timeline:alice", feed.ID())
+	other, err := client.FlatFeed("timeline", "bob")
+	require.NoError(t, err)
+	assert.NotEqual(t, timeline, other)
+
+	client.FlatFeedFunc = func(slug, userID string) (stream.FlatFeedInterface, error) {
+		return nil, errors.New("boom")
+	}
+	_, err = likeLatest(client, "alice")
+	assert.EqualError(t, err, "boom")
+}
+
+func TestMockDefaults(t *testing.T) {
+	client := streamtest.NewMockClient()
+
+	resp, err := client.Reactions().Filter(stream.ByActivityID("123"))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+
+	user, err := client.Users().Get("alice")
+	require.NoError(t, err)
+	assert.NotNil(t, user)
+
+	feed, err := client.NotificationFeed("notification", "alice")
+	require.NoError(t, err)
+	_, err = feed.AddActivity(stream.Activity{Verb: "like"})
+	require.NoError(t, err)
+	agg, err := client.AggregatedFeed("aggregated", "alice")
+	require.NoError(t, err)
+	flat := streamtest.NewMockFlatFeed("user", "bob")
+	require.NoError(t, agg.Follow(flat))
+
+	assert.Len(t, client.NotificationFeedMock("notification", "alice").CallsTo("AddActivity"), 1)
+	follows := client.AggregatedFeedMock("aggregated", "alice").CallsTo("Follow")
+	require.Len(t, follows, 1)
+	assert.Equal(t, flat, follows[0].Args[0])
+}
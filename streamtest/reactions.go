@@ -0,0 +1,261 @@
+package streamtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type reaction struct {
+	id          string
+	kind        string
+	activityID  string
+	userID      string
+	parentID    string
+	data        fields
+	targetFeeds []string
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+// reactionJSON returns a reaction along with its latest children and their
+// counts, and the children added by the given user if any.
+func (s *Server) reactionJSON(rc *reaction, ownUserID string) fields {
+	data := rc.data
+	if data == nil {
+		data = fields{}
+	}
+	out := fields{
+		"id":          rc.id,
+		"kind":        rc.kind,
+		"activity_id": rc.activityID,
+		"user_id":     rc.userID,
+		"data":        data,
+		"parent":      rc.parentID,
+		"created_at":  formatTimestamp(rc.createdAt),
+		"updated_at":  formatTimestamp(rc.updatedAt),
+	}
+	if len(rc.targetFeeds) > 0 {
+		out["target_feeds"] = rc.targetFeeds
+	}
+	children := s.listReactions(func(c *reaction) bool { return c.parentID == rc.id })
+	latest := make(map[string][]fields)
+	counts := make(map[string]int)
+	own := make(map[string][]fields)
+	for _, c := range children {
+		if len(latest[c.kind]) < defaultReactionLimit {
+			latest[c.kind] = append(latest[c.kind], s.reactionJSON(c, ownUserID))
+		}
+		counts[c.kind]++
+		if ownUserID != "" && c.userID == ownUserID {
+			own[c.kind] = append(own[c.kind], s.reactionJSON(c, ownUserID))
+		}
+	}
+	out["latest_children"] = latest
+	out["children_counts"] = counts
+	if ownUserID != "" {
+		out["own_children"] = own
+	}
+	return out
+}
+
+// listReactions returns the reactions matching the given function, newest
+// first.
+func (s *Server) listReactions(match func(*reaction) bool) []*reaction {
+	var list []*reaction
+	for _, rc := range s.reactions {
+		if match(rc) {
+			list = append(list, rc)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].id > list[j].id
+	})
+	return list
+}
+
+func (s *Server) addReaction(r *request) (fields, error) {
+	var body struct {
+		ID          string   `json:"id"`
+		Kind        string   `json:"kind"`
+		ActivityID  string   `json:"activity_id"`
+		UserID      string   `json:"user_id"`
+		Data        fields   `json:"data"`
+		TargetFeeds []string `json:"target_feeds"`
+		ParentID    string   `json:"parent"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Kind == "" {
+		return nil, invalidInput("kind is a required field")
+	}
+	if body.UserID == "" {
+		return nil, invalidInput("user_id is a required field")
+	}
+	if body.ParentID != "" {
+		parent, ok := s.reactions[body.ParentID]
+		if !ok {
+			return nil, invalidInput("parent reaction %q does not exist", body.ParentID)
+		}
+		if body.ActivityID == "" {
+			body.ActivityID = parent.activityID
+		}
+	}
+	if _, ok := s.activities[body.ActivityID]; !ok {
+		return nil, invalidInput("activity %q does not exist", body.ActivityID)
+	}
+	if _, ok := s.reactions[body.ID]; ok {
+		return nil, conflict("reaction %q already exists", body.ID)
+	}
+	feeds := make([]*feed, len(body.TargetFeeds))
+	for i, id := range body.TargetFeeds {
+		f, err := s.feed(id)
+		if err != nil {
+			return nil, err
+		}
+		feeds[i] = f
+	}
+
+	now := s.currentTime()
+	rc := &reaction{
+		id:          body.ID,
+		kind:        body.Kind,
+		activityID:  body.ActivityID,
+		userID:      body.UserID,
+		parentID:    body.ParentID,
+		data:        body.Data,
+		targetFeeds: body.TargetFeeds,
+		createdAt:   now,
+		updatedAt:   now,
+	}
+	if rc.id == "" {
+		rc.id = s.newID(now)
+	}
+	s.reactions[rc.id] = rc
+
+	// reactions are added to their target feeds as activities referencing
+	// them, like the real API does
+	for _, f := range feeds {
+		a := s.storeActivity(&activity{
+			time: now,
+			fields: fields{
+				"actor":      fmt.Sprintf("SU:%s", rc.userID),
+				"verb":       rc.kind,
+				"object":     fmt.Sprintf("SR:%s", rc.id),
+				"foreign_id": fmt.Sprintf("SR:%s", rc.id),
+			},
+		})
+		s.distribute(f, a)
+	}
+	return s.reactionJSON(rc, ""), nil
+}
+
+func (s *Server) getReaction(r *request) (fields, error) {
+	rc, ok := s.reactions[r.params[0]]
+	if !ok {
+		return nil, notFound("reaction %q not found", r.params[0])
+	}
+	return s.reactionJSON(rc, ""), nil
+}
+
+func (s *Server) updateReaction(r *request) (fields, error) {
+	rc, ok := s.reactions[r.params[0]]
+	if !ok {
+		return nil, notFound("reaction %q not found", r.params[0])
+	}
+	var body struct {
+		Data        fields   `json:"data"`
+		TargetFeeds []string `json:"target_feeds"`
+	}
+	if err := r.decode(&body); err != nil {
+		return nil, err
+	}
+	rc.data = body.Data
+	if body.TargetFeeds != nil {
+		rc.targetFeeds = body.TargetFeeds
+	}
+	rc.updatedAt = s.currentTime()
+	return s.reactionJSON(rc, ""), nil
+}
+
+func (s *Server) deleteReaction(r *request) (fields, error) {
+	rc, ok := s.reactions[r.params[0]]
+	if !ok {
+		return nil, notFound("reaction %q not found", r.params[0])
+	}
+	s.removeReaction(rc)
+	return fields{}, nil
+}
+
+// removeReaction removes a reaction, its children and the activities
+// referencing it which were added to its target feeds.
+func (s *Server) removeReaction(rc *reaction) {
+	delete(s.reactions, rc.id)
+	for _, c := range s.listReactions(func(c *reaction) bool { return c.parentID == rc.id }) {
+		s.removeReaction(c)
+	}
+	foreignID := fmt.Sprintf("SR:%s", rc.id)
+	for _, f := range s.feeds {
+		for _, e := range append([]entry(nil), f.entries...) {
+			if a := s.activities[e.id]; a != nil && a.foreignID() == foreignID && e.origin == "" {
+				s.remove(f, e.id)
+			}
+		}
+	}
+}
+
+func (s *Server) filterReactions(r *request) (fields, error) {
+	attr, value := r.params[0], r.params[1]
+	var kind string
+	if len(r.params) == 3 {
+		kind = r.params[2]
+	}
+	var match func(*reaction) bool
+	switch attr {
+	case "activity_id":
+		match = func(rc *reaction) bool { return rc.activityID == value && rc.parentID == "" }
+	case "reaction_id":
+		match = func(rc *reaction) bool { return rc.parentID == value }
+	case "user_id":
+		match = func(rc *reaction) bool { return rc.userID == value }
+	default:
+		return nil, notFound("unknown endpoint %s %s", r.method, r.path)
+	}
+	limit, err := r.intParam("limit", defaultReactionLimit, maxReactionLimit)
+	if err != nil {
+		return nil, err
+	}
+	reactions := s.listReactions(func(rc *reaction) bool {
+		if !match(rc) || (kind != "" && rc.kind != kind) {
+			return false
+		}
+		for param, keep := range rangeParams {
+			if ref := r.query.Get(param); ref != "" && !keep(strings.Compare(rc.id, ref)) {
+				return false
+			}
+		}
+		return true
+	})
+
+	_, end, more := window(len(reactions), 0, limit)
+	ownUserID := ""
+	if r.boolParam("with_own_children") {
+		ownUserID = r.query.Get("user_id")
+	}
+	results := make([]fields, 0, end)
+	for _, rc := range reactions[:end] {
+		results = append(results, s.reactionJSON(rc, ownUserID))
+	}
+	resp := fields{"results": results, "next": ""}
+	if more && end > 0 {
+		resp["next"] = r.next("limit", fmt.Sprint(limit), "id_lt", reactions[end-1].id)
+	}
+	if attr == "activity_id" && r.boolParam("with_activity_data") {
+		if a, ok := s.activities[value]; ok {
+			resp["activity"] = s.resolveReferences(a.toJSON(""))
+		}
+	}
+	return resp, nil
+}
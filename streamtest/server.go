@@ -0,0 +1,546 @@
+// Package streamtest provides an in-memory fake of the Stream API, used for
+// testing code built on top of the stream package without network access.
+//
+// The fake server implements flat, aggregated and notification feeds, follows
+// (with activity copy and fan-out to followers), "to" targets, pagination with
+// next links, reactions, collections, users and enrichment. It's not meant to
+// replicate every detail of the real API: ranking, personalization and
+// analytics are not supported, and custom aggregation formats are replaced by
+// the default one, grouping activities by verb and day.
+//
+// A Server can either listen on a local address:
+//
+//	srv := streamtest.NewServer()
+//	defer srv.Close()
+//	client, err := srv.NewClient()
+//
+// or serve the requests of a client in-process, acting as its Requester:
+//
+//	client, err := streamtest.New().NewClient()
+package streamtest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	jwt "gopkg.in/dgrijalva/jwt-go.v3"
+)
+
+const (
+	// APIKey is the API key accepted by the fake server.
+	APIKey = "key"
+	// APISecret is the API secret the fake server verifies requests signatures
+	// with.
+	APISecret = "secret"
+)
+
+const (
+	defaultLimit         = 25
+	maxLimit             = 100
+	maxFollowLimit       = 500
+	defaultReactionLimit = 10
+	maxReactionLimit     = 25
+	defaultCopyLimit     = 300
+	maxCopyLimit         = 1000
+)
+
+// FeedType is the type of the feeds of a feed group, which determines how
+// their activities are returned.
+type FeedType int
+
+// The feed types.
+const (
+	// FlatFeed feeds return their activities as they are.
+	FlatFeed FeedType = iota
+	// AggregatedFeed feeds return their activities grouped by verb and day.
+	AggregatedFeed
+	// NotificationFeed feeds are aggregated feeds tracking whether each group
+	// was seen and read.
+	NotificationFeed
+)
+
+// Option customizes a Server.
+type Option func(*Server)
+
+// WithFeedGroup sets the type of the feeds having the given slug. By default,
+// the "aggregated" feed group is an aggregated one, the "notification" feed
+// group is a notification one, and any other feed group is flat.
+func WithFeedGroup(slug string, typ FeedType) Option {
+	return func(s *Server) {
+		s.groups[slug] = typ
+	}
+}
+
+// WithClock sets the function used by the Server to get the current time,
+// used for timestamps and for activities without a time.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// Server is an in-memory fake of the Stream API. It's safe for concurrent use.
+type Server struct {
+	mu     sync.Mutex
+	srv    *httptest.Server
+	groups map[string]FeedType
+	now    func() time.Time
+	seq    uint64
+
+	activities  map[string]*activity
+	feeds       map[string]*feed
+	follows     []*follow
+	reactions   map[string]*reaction
+	collections map[string]map[string]*object
+	users       map[string]*object
+}
+
+var _ stream.Requester = (*Server)(nil)
+
+// New returns a new Server serving requests in-process, to be used as the
+// Requester of a client (see NewClient).
+func New(opts ...Option) *Server {
+	s := &Server{
+		groups: map[string]FeedType{
+			"aggregated":   AggregatedFeed,
+			"notification": NotificationFeed,
+		},
+		now: time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.reset()
+	return s
+}
+
+// NewServer returns a new Server listening on a local address. It must be
+// closed when done.
+func NewServer(opts ...Option) *Server {
+	s := New(opts...)
+	s.srv = httptest.NewServer(s)
+	return s
+}
+
+// URL returns the base URL of a Server started with NewServer, or an empty
+// string otherwise.
+func (s *Server) URL() string {
+	if s.srv == nil {
+		return ""
+	}
+	return s.srv.URL
+}
+
+// Close shuts down a Server started with NewServer.
+func (s *Server) Close() {
+	if s.srv != nil {
+		s.srv.Close()
+	}
+}
+
+// NewClient returns a new stream.Client, authenticated with APIKey and
+// APISecret, whose requests are sent to the Server. Additional options can be
+// provided to configure the client further.
+func (s *Server) NewClient(opts ...stream.ClientOption) (*stream.Client, error) {
+	var base []stream.ClientOption
+	if s.srv != nil {
+		base = append(base,
+			stream.WithBaseURL(s.srv.URL),
+			stream.WithAnalyticsURL(s.srv.URL),
+			stream.WithPersonalizationURL(s.srv.URL),
+		)
+	} else {
+		base = append(base, stream.WithHTTPRequester(s))
+	}
+	return stream.NewClient(APIKey, APISecret, append(base, opts...)...)
+}
+
+// Reset deletes every feed, activity, follow, reaction, collection and user.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reset()
+}
+
+func (s *Server) reset() {
+	s.activities = make(map[string]*activity)
+	s.feeds = make(map[string]*feed)
+	s.follows = nil
+	s.reactions = make(map[string]*reaction)
+	s.collections = make(map[string]map[string]*object)
+	s.users = make(map[string]*object)
+}
+
+// Do serves the given request in-process, so that the Server can be used as a
+// stream.Requester.
+func (s *Server) Do(r *http.Request) (*http.Response, error) {
+	if err := r.Context().Err(); err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, r)
+	resp := rec.Result()
+	resp.Request = r
+	return resp, nil
+}
+
+// ServeHTTP serves an API request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		body   interface{}
+		status = http.StatusOK
+	)
+	resp, err := s.handle(r)
+	if err != nil {
+		var apiErr *apiError
+		if !errors.As(err, &apiErr) {
+			apiErr = newAPIError(http.StatusInternalServerError, -1, "InternalError", err.Error())
+		}
+		body, status = apiErr, apiErr.StatusCode
+	} else {
+		resp["duration"] = "0.00ms"
+		body = resp
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(status)
+		zw := gzip.NewWriter(w)
+		_ = json.NewEncoder(zw).Encode(body)
+		_ = zw.Close()
+		return
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) handle(r *http.Request) (fields, error) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "api" || !strings.HasPrefix(segments[1], "v") {
+		return nil, notFound("unknown endpoint %s %s", r.Method, r.URL.Path)
+	}
+	if err := authorize(r); err != nil {
+		return nil, err
+	}
+	req := &request{
+		method: r.Method,
+		path:   r.URL.Path,
+		query:  r.URL.Query(),
+	}
+	if r.Body != nil {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				return nil, invalidInput("cannot decompress request body: %s", err)
+			}
+			body = zr
+		}
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return nil, invalidInput("cannot read request body: %s", err)
+		}
+		req.body = data
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rt := range routes {
+		if rt.method != r.Method {
+			continue
+		}
+		if params, ok := rt.match(segments[2:]); ok {
+			req.params = params
+			return rt.handler(s, req)
+		}
+	}
+	return nil, notFound("unknown endpoint %s %s", r.Method, r.URL.Path)
+}
+
+func authorize(r *http.Request) error {
+	if key := r.URL.Query().Get("api_key"); key != APIKey {
+		return newAPIError(http.StatusUnauthorized, 5, "AuthenticationFailed", fmt.Sprintf("invalid api key %q", key))
+	}
+	_, err := jwt.Parse(r.Header.Get("Authorization"), func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return []byte(APISecret), nil
+	})
+	if err != nil {
+		return newAPIError(http.StatusForbidden, 17, "NotAllowedException", fmt.Sprintf("invalid signature: %s", err))
+	}
+	return nil
+}
+
+type route struct {
+	method  string
+	pattern []string
+	handler func(*Server, *request) (fields, error)
+}
+
+func (rt route) match(segments []string) ([]string, bool) {
+	if len(segments) != len(rt.pattern) {
+		return nil, false
+	}
+	var params []string
+	for i, p := range rt.pattern {
+		switch {
+		case p == "*":
+			params = append(params, segments[i])
+		case p != segments[i]:
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func newRoute(method, pattern string, handler func(*Server, *request) (fields, error)) route {
+	return route{method: method, pattern: strings.Split(pattern, "/"), handler: handler}
+}
+
+var routes = []route{
+	newRoute(http.MethodPost, "feed/add_to_many", (*Server).addToMany),
+	newRoute(http.MethodGet, "feed/*/*", (*Server).getActivities),
+	newRoute(http.MethodPost, "feed/*/*", (*Server).addActivities),
+	newRoute(http.MethodDelete, "feed/*/*/*", (*Server).removeActivity),
+	newRoute(http.MethodGet, "feed/*/*/follows", (*Server).getFollowing),
+	newRoute(http.MethodPost, "feed/*/*/follows", (*Server).follow),
+	newRoute(http.MethodDelete, "feed/*/*/follows/*", (*Server).unfollow),
+	newRoute(http.MethodGet, "feed/*/*/followers", (*Server).getFollowers),
+	newRoute(http.MethodGet, "enrich/feed/*/*", (*Server).getEnrichedActivities),
+	newRoute(http.MethodPost, "feed_targets/*/*/activity_to_targets", (*Server).updateToTargets),
+	newRoute(http.MethodPost, "follow_many", (*Server).followMany),
+	newRoute(http.MethodPost, "unfollow_many", (*Server).unfollowMany),
+	newRoute(http.MethodGet, "activities", (*Server).getActivitiesByID),
+	newRoute(http.MethodPost, "activities", (*Server).updateActivities),
+	newRoute(http.MethodPost, "activity", (*Server).partialUpdateActivities),
+	newRoute(http.MethodPost, "reaction", (*Server).addReaction),
+	newRoute(http.MethodGet, "reaction/*", (*Server).getReaction),
+	newRoute(http.MethodPut, "reaction/*", (*Server).updateReaction),
+	newRoute(http.MethodDelete, "reaction/*", (*Server).deleteReaction),
+	newRoute(http.MethodGet, "reaction/*/*", (*Server).filterReactions),
+	newRoute(http.MethodGet, "reaction/*/*/*", (*Server).filterReactions),
+	newRoute(http.MethodPost, "collections", (*Server).upsertObjects),
+	newRoute(http.MethodGet, "collections", (*Server).selectObjects),
+	newRoute(http.MethodDelete, "collections", (*Server).deleteObjects),
+	newRoute(http.MethodPost, "collections/*", (*Server).addObject),
+	newRoute(http.MethodGet, "collections/*/*", (*Server).getObject),
+	newRoute(http.MethodPut, "collections/*/*", (*Server).updateObject),
+	newRoute(http.MethodDelete, "collections/*/*", (*Server).deleteObject),
+	newRoute(http.MethodPost, "user", (*Server).addUser),
+	newRoute(http.MethodGet, "user/*", (*Server).getUser),
+	newRoute(http.MethodPut, "user/*", (*Server).updateUser),
+	newRoute(http.MethodDelete, "user/*", (*Server).deleteUser),
+}
+
+// fields is a JSON object.
+type fields map[string]interface{}
+
+type request struct {
+	method string
+	path   string
+	params []string
+	query  url.Values
+	body   []byte
+}
+
+// decode unmarshals the request body into v, keeping numbers as json.Number
+// so that they are returned unchanged.
+func (r *request) decode(v interface{}) error {
+	if len(r.body) == 0 {
+		return invalidInput("missing request body")
+	}
+	dec := json.NewDecoder(bytes.NewReader(r.body))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return invalidInput("invalid request body: %s", err)
+	}
+	return nil
+}
+
+// next returns the link to the next page of results, keeping the request
+// parameters but the pagination ones, which are replaced by the given
+// key/value pairs.
+func (r *request) next(kv ...string) string {
+	q := make(url.Values)
+	for k, v := range r.query {
+		switch k {
+		case "api_key", "limit", "offset", "id_lt", "id_lte", "id_gt", "id_gte", "mark_seen", "mark_read":
+			continue
+		}
+		q[k] = v
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		q.Set(kv[i], kv[i+1])
+	}
+	return r.path + "?" + q.Encode()
+}
+
+func (r *request) intParam(key string, def, max int) (int, error) {
+	v := r.query.Get(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, invalidInput("invalid value for %s: %q", key, v)
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	return n, nil
+}
+
+func (r *request) boolParam(key string) bool {
+	switch strings.ToLower(r.query.Get(key)) {
+	case "true", "t", "1":
+		return true
+	}
+	return false
+}
+
+// apiError is an error response, in the same format as the real API ones.
+type apiError struct {
+	Code       int    `json:"code"`
+	Detail     string `json:"detail"`
+	Duration   string `json:"duration"`
+	Exception  string `json:"exception"`
+	StatusCode int    `json:"status_code"`
+}
+
+func newAPIError(statusCode, code int, exception, detail string) *apiError {
+	return &apiError{
+		Code:       code,
+		Detail:     detail,
+		Duration:   "0.00ms",
+		Exception:  exception,
+		StatusCode: statusCode,
+	}
+}
+
+func (e *apiError) Error() string {
+	return e.Detail
+}
+
+func notFound(format string, a ...interface{}) error {
+	return newAPIError(http.StatusNotFound, 16, "DoesNotExistException", fmt.Sprintf(format, a...))
+}
+
+func invalidInput(format string, a ...interface{}) error {
+	return newAPIError(http.StatusBadRequest, 4, "InputException", fmt.Sprintf(format, a...))
+}
+
+func conflict(format string, a ...interface{}) error {
+	return newAPIError(http.StatusConflict, 4, "ConflictException", fmt.Sprintf(format, a...))
+}
+
+// newID returns a new unique ID in the UUID format, sorting in the same order
+// as the given times.
+func (s *Server) newID(t time.Time) string {
+	s.seq++
+	n := uint64(t.UnixNano())
+	return fmt.Sprintf("%08x-%04x-%04x-8000-%012x", n>>32, n>>16&0xffff, n&0xffff, s.seq)
+}
+
+func (s *Server) currentTime() time.Time {
+	return s.now().UTC().Truncate(time.Microsecond)
+}
+
+var timeLayouts = []string{
+	stream.TimeLayout,
+	stream.ReactionTimeLayout,
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999-07:00",
+}
+
+func parseTime(v interface{}) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid time %v", v)
+	}
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t.UTC().Truncate(time.Microsecond), nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// formatTime formats activity times.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(stream.TimeLayout)
+}
+
+// formatTimestamp formats the creation and update times of reactions,
+// collection objects and users.
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(stream.ReactionTimeLayout)
+}
+
+// stringList returns the strings in a JSON array.
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeParams are the pagination parameters selecting items by their
+// position relative to a given ID, mapped to whether they keep an item given
+// the comparison of its position to the one of the ID.
+var rangeParams = map[string]func(int) bool{
+	"id_lt":  func(c int) bool { return c < 0 },
+	"id_lte": func(c int) bool { return c <= 0 },
+	"id_gt":  func(c int) bool { return c > 0 },
+	"id_gte": func(c int) bool { return c >= 0 },
+}
+
+// window returns the bounds of the page of n items starting at offset, and
+// whether more items follow it.
+func window(n, offset, limit int) (int, int, bool) {
+	start := offset
+	if start > n {
+		start = n
+	}
+	end := start + limit
+	if end > n {
+		end = n
+	}
+	return start, end, end < n
+}
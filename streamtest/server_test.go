@@ -0,0 +1,459 @@
+package streamtest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/GetStream/stream-go2/streamtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, opts ...streamtest.Option) *stream.Client {
+	client, err := streamtest.New(opts...).NewClient()
+	require.NoError(t, err)
+	return client
+}
+
+func activityIDs(activities []stream.Activity) []string {
+	ids := make([]string, len(activities))
+	for i, a := range activities {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func TestServer(t *testing.T) {
+	srv := streamtest.NewServer()
+	defer srv.Close()
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+
+	flat, err := client.FlatFeed("user", "alice")
+	require.NoError(t, err)
+	added, err := flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1", Extra: map[string]interface{}{"caption": "hello"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, added.ID)
+	assert.False(t, added.Time.IsZero())
+
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, added.ID, resp.Results[0].ID)
+	assert.Equal(t, "hello", resp.Results[0].Extra["caption"])
+
+	_, err = flat.AddActivity(stream.Activity{Actor: "alice", Object: "picture:1"})
+	assert.True(t, errors.Is(err, stream.ErrValidation))
+
+	unauthorized, err := stream.NewClient(streamtest.APIKey, "wrong", stream.WithBaseURL(srv.URL()))
+	require.NoError(t, err)
+	_, err = unauthorized.Users().Get("alice")
+	assert.True(t, errors.Is(err, stream.ErrUnauthorized))
+
+	_, err = client.Personalization().Get("etoro", map[string]interface{}{})
+	assert.True(t, errors.Is(err, stream.ErrNotFound))
+}
+
+func TestFollows(t *testing.T) {
+	client := newClient(t)
+	alice, _ := client.FlatFeed("user", "alice")
+	timeline, _ := client.FlatFeed("timeline", "bob")
+
+	first, err := alice.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+	require.NoError(t, timeline.Follow(alice))
+
+	resp, err := timeline.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, first.ID, resp.Results[0].ID)
+	assert.Equal(t, "user:alice", resp.Results[0].Origin)
+
+	second, err := alice.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:2"})
+	require.NoError(t, err)
+	resp, err = timeline.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID, first.ID}, activityIDs(resp.Results))
+
+	followers, err := alice.GetFollowers()
+	require.NoError(t, err)
+	require.Len(t, followers.Results, 1)
+	assert.Equal(t, stream.Follower{FeedID: "timeline:bob", TargetID: "user:alice"}, followers.Results[0])
+	following, err := timeline.GetFollowing(stream.WithFollowingFilter("user:alice", "user:carol"))
+	require.NoError(t, err)
+	assert.Len(t, following.Results, 1)
+
+	require.NoError(t, alice.RemoveActivityByID(first.ID))
+	resp, err = timeline.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, []string{second.ID}, activityIDs(resp.Results))
+
+	require.NoError(t, timeline.Unfollow(alice))
+	resp, err = timeline.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+
+	// copy limit and history
+	require.NoError(t, timeline.Follow(alice, stream.WithFollowFeedActivityCopyLimit(0)))
+	resp, err = timeline.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	third, err := alice.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:3"})
+	require.NoError(t, err)
+	require.NoError(t, timeline.Unfollow(alice, stream.WithUnfollowKeepHistory(true)))
+	resp, err = timeline.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, []string{third.ID}, activityIDs(resp.Results))
+
+	// batch operations
+	carol, _ := client.FlatFeed("user", "carol")
+	dave, _ := client.FlatFeed("timeline", "dave")
+	require.NoError(t, client.FollowMany([]stream.FollowRelationship{stream.NewFollowRelationship(dave, carol)}))
+	require.NoError(t, client.AddToMany(stream.Activity{Actor: "carol", Verb: "post", Object: "picture:4"}, carol, alice))
+	resp, err = dave.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	shared := resp.Results[0].ID
+	resp, err = alice.GetActivities()
+	require.NoError(t, err)
+	assert.Contains(t, activityIDs(resp.Results), shared)
+	require.NoError(t, client.UnfollowMany([]stream.UnfollowRelationship{{Source: "timeline:dave", Target: "user:carol"}}))
+	resp, err = dave.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+}
+
+func TestToTargets(t *testing.T) {
+	client := newClient(t)
+	alice, _ := client.FlatFeed("user", "alice")
+	bob, _ := client.FlatFeed("user", "bob")
+	notifications, _ := client.NotificationFeed("notification", "carol")
+	follower, _ := client.FlatFeed("timeline", "dave")
+	require.NoError(t, follower.Follow(bob))
+
+	activity := stream.Activity{
+		Actor:     "alice",
+		Verb:      "mention",
+		Object:    "comment:1",
+		ForeignID: "comment:1",
+		Time:      stream.Time{Time: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)},
+		To:        []string{"notification:carol"},
+	}
+	_, err := alice.AddActivity(activity)
+	require.NoError(t, err)
+	resp, err := notifications.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, "comment:1", resp.Results[0].Activities[0].ForeignID)
+
+	require.NoError(t, alice.UpdateToTargets(activity, stream.WithToTargetsAdd("user:bob"), stream.WithToTargetsRemove("notification:carol")))
+	resp, err = notifications.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+	for _, feed := range []stream.FlatFeedInterface{bob, follower} {
+		flat, err := feed.GetActivities()
+		require.NoError(t, err)
+		require.Len(t, flat.Results, 1)
+		assert.Equal(t, []string{"user:bob"}, flat.Results[0].To)
+	}
+
+	require.NoError(t, alice.RemoveActivityByForeignID("comment:1"))
+	flat, err := follower.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, flat.Results)
+}
+
+func TestPagination(t *testing.T) {
+	client := newClient(t)
+	flat, _ := client.FlatFeed("user", "alice")
+	var expected []string
+	for i := 0; i < 5; i++ {
+		added, err := flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture"})
+		require.NoError(t, err)
+		expected = append([]string{added.ID}, expected...)
+	}
+
+	resp, err := flat.GetActivities(stream.WithActivitiesLimit(2))
+	require.NoError(t, err)
+	ids := activityIDs(resp.Results)
+	for resp.Next != "" {
+		resp, err = flat.GetNextPageActivities(resp)
+		require.NoError(t, err)
+		assert.True(t, len(resp.Results) <= 2)
+		ids = append(ids, activityIDs(resp.Results)...)
+	}
+	assert.Equal(t, expected, ids)
+	_, err = flat.GetNextPageActivities(resp)
+	assert.Equal(t, stream.ErrMissingNextPage, err)
+
+	resp, err = flat.GetActivities(stream.WithActivitiesOffset(1), stream.WithActivitiesLimit(2))
+	require.NoError(t, err)
+	assert.Equal(t, expected[1:3], activityIDs(resp.Results))
+	resp, err = flat.GetActivities(stream.WithActivitiesIDLT(expected[1]))
+	require.NoError(t, err)
+	assert.Equal(t, expected[2:], activityIDs(resp.Results))
+}
+
+func TestAggregatedFeeds(t *testing.T) {
+	client := newClient(t, streamtest.WithFeedGroup("alerts", streamtest.NotificationFeed))
+	aggregated, _ := client.AggregatedFeed("aggregated", "alice")
+	notifications, _ := client.NotificationFeed("alerts", "alice")
+	for _, verb := range []string{"like", "like", "comment"} {
+		activity := stream.Activity{Actor: "bob", Verb: verb, Object: "picture:1"}
+		_, err := aggregated.AddActivity(activity)
+		require.NoError(t, err)
+		_, err = notifications.AddActivity(activity)
+		require.NoError(t, err)
+	}
+
+	resp, err := aggregated.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, "comment", resp.Results[0].Verb)
+	assert.Equal(t, 2, resp.Results[1].ActivityCount)
+	assert.Equal(t, 1, resp.Results[1].ActorCount)
+	assert.Len(t, resp.Results[1].Activities, 2)
+
+	page, err := aggregated.GetActivities(stream.WithActivitiesLimit(1))
+	require.NoError(t, err)
+	page, err = aggregated.GetNextPageActivities(page)
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, "like", page.Results[0].Verb)
+
+	notif, err := notifications.GetActivities(stream.WithNotificationsMarkSeen(true))
+	require.NoError(t, err)
+	assert.Equal(t, 2, notif.Unseen)
+	assert.Equal(t, 2, notif.Unread)
+	assert.False(t, notif.Results[0].IsSeen)
+	notif, err = notifications.GetActivities(stream.WithNotificationsMarkRead(false, notif.Results[1].ID))
+	require.NoError(t, err)
+	assert.Equal(t, 0, notif.Unseen)
+	assert.Equal(t, 2, notif.Unread)
+	assert.True(t, notif.Results[0].IsSeen)
+
+	_, err = notifications.AddActivity(stream.Activity{Actor: "carol", Verb: "comment", Object: "picture:1"})
+	require.NoError(t, err)
+	notif, err = notifications.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, 1, notif.Unseen)
+	assert.Equal(t, 1, notif.Unread)
+	assert.Equal(t, 2, notif.Results[0].ActorCount)
+}
+
+func TestActivities(t *testing.T) {
+	client := newClient(t)
+	flat, _ := client.FlatFeed("user", "alice")
+	ts := stream.Time{Time: time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)}
+	added, err := flat.AddActivities(
+		stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1", ForeignID: "picture:1", Time: ts},
+		stream.Activity{Actor: "alice", Verb: "post", Object: "picture:2", ForeignID: "picture:2", Time: ts},
+	)
+	require.NoError(t, err)
+	require.Len(t, added.Activities, 2)
+
+	// same foreign ID and time, same activity
+	again, err := flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1", ForeignID: "picture:1", Time: ts})
+	require.NoError(t, err)
+	assert.Equal(t, added.Activities[0].ID, again.ID)
+
+	byID, err := client.GetActivitiesByID(added.Activities[1].ID, "missing")
+	require.NoError(t, err)
+	require.Len(t, byID.Results, 1)
+	assert.Equal(t, "picture:2", byID.Results[0].Object)
+	byForeignID, err := client.GetActivitiesByForeignID(stream.NewForeignIDTimePair("picture:1", ts))
+	require.NoError(t, err)
+	require.Len(t, byForeignID.Results, 1)
+
+	require.NoError(t, client.UpdateActivities(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1", ForeignID: "picture:1", Time: ts, Extra: map[string]interface{}{"likes": 1}}))
+	updated, err := client.UpdateActivityByID(added.Activities[0].ID, map[string]interface{}{"meta.tags": []string{"sea"}}, []string{"likes"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"meta": map[string]interface{}{"tags": []interface{}{"sea"}}}, updated.Extra)
+	partial, err := client.PartialUpdateActivities(stream.NewUpdateActivityRequestByForeignID("picture:2", ts, map[string]interface{}{"likes": 2}, nil))
+	require.NoError(t, err)
+	require.Len(t, partial.Activities, 1)
+	assert.EqualValues(t, 2, partial.Activities[0].Extra["likes"])
+
+	_, err = client.UpdateActivityByID("missing", map[string]interface{}{"likes": 2}, nil)
+	assert.True(t, errors.Is(err, stream.ErrNotFound))
+}
+
+func TestReactions(t *testing.T) {
+	client := newClient(t)
+	flat, _ := client.FlatFeed("user", "alice")
+	activity, err := flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+
+	var likes []string
+	for _, user := range []string{"bob", "carol", "dave"} {
+		r, err := client.Reactions().Add(stream.AddReactionRequestObject{Kind: "like", ActivityID: activity.ID, UserID: user})
+		require.NoError(t, err)
+		likes = append([]string{r.ID}, likes...)
+	}
+	comment, err := client.Reactions().Add(stream.AddReactionRequestObject{
+		Kind:        "comment",
+		ActivityID:  activity.ID,
+		UserID:      "bob",
+		Data:        map[string]interface{}{"text": "nice"},
+		TargetFeeds: []string{"user:bob"},
+	})
+	require.NoError(t, err)
+	_, err = client.Reactions().AddChild(comment.ID, stream.AddReactionRequestObject{Kind: "like", UserID: "alice"})
+	require.NoError(t, err)
+	_, err = client.Reactions().Add(stream.AddReactionRequestObject{Kind: "like", ActivityID: "missing", UserID: "bob"})
+	assert.True(t, errors.Is(err, stream.ErrValidation))
+
+	resp, err := client.Reactions().Filter(stream.ByActivityID(activity.ID).ByKind("like"), stream.WithLimit(2))
+	require.NoError(t, err)
+	ids := []string{resp.Results[0].ID, resp.Results[1].ID}
+	resp, err = client.Reactions().GetNextPageFilteredReactions(resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, likes, append(ids, resp.Results[0].ID))
+	assert.Empty(t, resp.Next)
+
+	resp, err = client.Reactions().Filter(stream.ByActivityID(activity.ID), stream.WithActivityData())
+	require.NoError(t, err)
+	assert.Len(t, resp.Results, 4)
+	assert.Equal(t, activity.ID, resp.Activity["id"])
+
+	parent, err := client.Reactions().Get(comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "nice", parent.Data["text"])
+	assert.EqualValues(t, 1, parent.ChildrenCounters["like"])
+	require.Len(t, parent.ChildrenReactions["like"], 1)
+
+	bob, _ := client.FlatFeed("user", "bob")
+	feed, err := bob.GetActivities()
+	require.NoError(t, err)
+	require.Len(t, feed.Results, 1)
+	assert.Equal(t, "SR:"+comment.ID, feed.Results[0].Object)
+
+	updated, err := client.Reactions().Update(comment.ID, map[string]interface{}{"text": "great"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "great", updated.Data["text"])
+
+	require.NoError(t, client.Reactions().Delete(comment.ID))
+	_, err = client.Reactions().Get(comment.ID)
+	assert.True(t, errors.Is(err, stream.ErrNotFound))
+	feed, err = bob.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, feed.Results)
+	resp, err = client.Reactions().Filter(stream.ByUserID("alice"))
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+}
+
+func TestCollectionsAndUsers(t *testing.T) {
+	client := newClient(t)
+
+	user, err := client.Users().Add(stream.User{ID: "alice", Data: map[string]interface{}{"name": "Alice"}}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Data["name"])
+	_, err = client.Users().Add(stream.User{ID: "alice"}, false)
+	apiErr, ok := stream.ToAPIError(err)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+	user, err = client.Users().Add(stream.User{ID: "alice"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Data["name"])
+	user, err = client.Users().Update("alice", map[string]interface{}{"name": "Alicia"})
+	require.NoError(t, err)
+	assert.Equal(t, "Alicia", user.Data["name"])
+	require.NoError(t, client.Users().Delete("alice"))
+	_, err = client.Users().Get("alice")
+	assert.True(t, errors.Is(err, stream.ErrNotFound))
+
+	collections := client.Collections()
+	require.NoError(t, collections.Upsert("food",
+		stream.CollectionObject{ID: "pizza", Data: map[string]interface{}{"rating": "great"}},
+		stream.CollectionObject{ID: "pasta", Data: map[string]interface{}{"rating": "good"}},
+	))
+	objects, err := collections.Select("food", "pizza", "salad", "pasta")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "food:pizza", objects[0].ForeignID)
+	assert.Equal(t, "great", objects[0].Data["rating"])
+
+	obj, err := collections.Add("food", stream.CollectionObject{Data: map[string]interface{}{"rating": "ok"}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, obj.ID)
+	_, err = collections.Add("food", stream.CollectionObject{ID: "pizza"})
+	assert.Error(t, err)
+	obj, err = collections.Update("food", obj.ID, map[string]interface{}{"rating": "bad"})
+	require.NoError(t, err)
+	assert.Equal(t, "bad", obj.Data["rating"])
+	obj, err = collections.Get("food", obj.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bad", obj.Data["rating"])
+	require.NoError(t, collections.Delete("food", obj.ID))
+	_, err = collections.Get("food", obj.ID)
+	assert.True(t, errors.Is(err, stream.ErrNotFound))
+	require.NoError(t, collections.DeleteMany("food", "pizza", "pasta"))
+	objects, err = collections.Select("food", "pizza", "pasta")
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+func TestEnrichment(t *testing.T) {
+	client := newClient(t)
+	_, err := client.Users().Add(stream.User{ID: "alice", Data: map[string]interface{}{"name": "Alice"}}, false)
+	require.NoError(t, err)
+	_, err = client.Users().Add(stream.User{ID: "bob"}, false)
+	require.NoError(t, err)
+	_, err = client.Collections().Add("food", stream.CollectionObject{ID: "pizza", Data: map[string]interface{}{"rating": "great"}})
+	require.NoError(t, err)
+
+	flat, _ := client.FlatFeed("user", "alice")
+	activity, err := flat.AddActivity(stream.Activity{
+		Actor:  client.Users().CreateReference("alice"),
+		Verb:   "eat",
+		Object: client.Collections().CreateReference("food", "pizza"),
+		Target: client.Users().CreateReference("missing"),
+	})
+	require.NoError(t, err)
+	for _, kind := range []string{"like", "comment"} {
+		_, err = client.Reactions().Add(stream.AddReactionRequestObject{Kind: kind, ActivityID: activity.ID, UserID: "bob"})
+		require.NoError(t, err)
+	}
+
+	resp, err := flat.GetEnrichedActivities(
+		stream.WithEnrichReactionCounts(),
+		stream.WithEnrichRecentReactions(),
+		stream.WithEnrichReactionKindsFilter("like"),
+	)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	enriched := resp.Results[0]
+	assert.Equal(t, "alice", enriched.Actor.ID)
+	assert.Equal(t, map[string]interface{}{"name": "Alice"}, enriched.Actor.Extra["data"])
+	assert.Equal(t, "pizza", enriched.Object.ID)
+	assert.Equal(t, "food", enriched.Object.Extra["collection"])
+	assert.Equal(t, "SU:missing", enriched.Target.ID)
+	assert.Equal(t, map[string]int{"like": 1}, enriched.ReactionCounts)
+	require.Len(t, enriched.LatestReactions["like"], 1)
+	assert.Equal(t, "bob", enriched.LatestReactions["like"][0].UserID)
+	assert.Equal(t, "bob", enriched.LatestReactions["like"][0].User.ID)
+	assert.Empty(t, enriched.OwnReactions)
+
+	plain, err := flat.GetActivities()
+	require.NoError(t, err)
+	assert.Equal(t, "SU:alice", plain.Results[0].Actor)
+}
+
+func TestReset(t *testing.T) {
+	srv := streamtest.New()
+	client, err := srv.NewClient()
+	require.NoError(t, err)
+	flat, _ := client.FlatFeed("user", "alice")
+	_, err = flat.AddActivity(stream.Activity{Actor: "alice", Verb: "post", Object: "picture:1"})
+	require.NoError(t, err)
+
+	srv.Reset()
+	resp, err := flat.GetActivities()
+	require.NoError(t, err)
+	assert.Empty(t, resp.Results)
+}
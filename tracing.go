@@ -0,0 +1,78 @@
+package stream
+
+import "context"
+
+// Tracer creates the spans tracing the API calls performed by a Client, so
+// that they can be bridged to a tracing system such as OpenTelemetry.
+//
+// A span is started for each API call, named after the logical operation and
+// the feed it targets, if any (e.g. "feed.add_activity user:42",
+// "reactions.filter"), plus a child span for each HTTP attempt performed.
+type Tracer interface {
+	// StartSpan starts a new span with the given name, as a child of the span
+	// carried by the context if any, returning a context carrying the new span.
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is an operation traced by a Tracer.
+type Span interface {
+	// SetAttribute sets an attribute of the span.
+	SetAttribute(key string, value interface{})
+	// End completes the span, recording the error the operation failed with,
+	// if any.
+	End(err error)
+}
+
+// Attributes set on the spans.
+const (
+	// AttributeOperation is the logical operation of an API call (string).
+	AttributeOperation = "stream.operation"
+	// AttributeFeedID is the ID of the feed targeted by an API call, if any
+	// (string).
+	AttributeFeedID = "stream.feed_id"
+	// AttributeResource is the API resource of the call (string).
+	AttributeResource = "stream.resource"
+	// AttributeMethod is the HTTP method of the call (string).
+	AttributeMethod = "http.method"
+	// AttributeStatusCode is the HTTP status code of the last response
+	// received, zero if none (int).
+	AttributeStatusCode = "http.status_code"
+	// AttributeAttempt is the 1-based number of an HTTP attempt (int).
+	AttributeAttempt = "stream.attempt"
+	// AttributeRetryCount is the number of retries performed for an API call
+	// (int).
+	AttributeRetryCount = "stream.retry_count"
+	// AttributeRequestSize is the size in bytes of the request body (int).
+	AttributeRequestSize = "stream.request_size"
+	// AttributeResponseSize is the size in bytes of the response body (int).
+	AttributeResponseSize = "stream.response_size"
+)
+
+// WithTracer sets the Tracer for a given Client. By default, API calls aren't
+// traced.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) {
+		c.tracer = tracer
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+
+func (noopSpan) End(error) {}
+
+func (c *Client) startSpan(ctx context.Context, name string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.tracer.StartSpan(ctx, name)
+}
+
+// spanName returns the name of the span tracing an API call.
+func (e endpoint) spanName() string {
+	if e.feedID == "" {
+		return e.operation
+	}
+	return e.operation + " " + e.feedID
+}
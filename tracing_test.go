@@ -0,0 +1,137 @@
+package stream_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name       string
+	parent     *recordedSpan
+	attributes map[string]interface{}
+	ended      bool
+	err        error
+}
+
+func (s *recordedSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) StartSpan(ctx context.Context, name string) (context.Context, stream.Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{name: name, parent: parent, attributes: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestTracing(t *testing.T) {
+	requester := &scriptedRequester{responses: []scriptedResponse{
+		{code: http.StatusServiceUnavailable, body: `{"detail":"unavailable"}`},
+		{code: http.StatusCreated, body: `{"id":"abc"}`},
+		{code: http.StatusBadRequest, body: `{"detail":"bad request"}`},
+	}}
+	tracer := &recordingTracer{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithRetryPolicy(stream.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+		stream.WithTracer(tracer),
+	)
+	require.NoError(t, err)
+	flat, _ := newFlatFeedWithUserID(client, "42")
+
+	activity := stream.Activity{Actor: "bob", Verb: "like", Object: "cake", ForeignID: "like:1", Time: stream.Time{Time: time.Now()}}
+	_, err = flat.AddActivity(activity)
+	require.NoError(t, err)
+
+	require.Len(t, tracer.spans, 3)
+	op := tracer.spans[0]
+	assert.Equal(t, "feed.add_activity flat:42", op.name)
+	assert.Nil(t, op.parent)
+	assert.True(t, op.ended)
+	assert.NoError(t, op.err)
+	assert.Equal(t, "feed.add_activity", op.attributes[stream.AttributeOperation])
+	assert.Equal(t, "flat:42", op.attributes[stream.AttributeFeedID])
+	assert.Equal(t, "feed", op.attributes[stream.AttributeResource])
+	assert.Equal(t, http.MethodPost, op.attributes[stream.AttributeMethod])
+	assert.Equal(t, http.StatusCreated, op.attributes[stream.AttributeStatusCode])
+	assert.Equal(t, 1, op.attributes[stream.AttributeRetryCount])
+	assert.Equal(t, len(requester.bodies[0]), op.attributes[stream.AttributeRequestSize])
+	assert.Equal(t, len(`{"id":"abc"}`), op.attributes[stream.AttributeResponseSize])
+
+	for i, span := range tracer.spans[1:] {
+		assert.Equal(t, "HTTP POST", span.name)
+		assert.Equal(t, op, span.parent)
+		assert.True(t, span.ended)
+		assert.Equal(t, i+1, span.attributes[stream.AttributeAttempt])
+	}
+	assert.Error(t, tracer.spans[1].err)
+	assert.Equal(t, http.StatusServiceUnavailable, tracer.spans[1].attributes[stream.AttributeStatusCode])
+	assert.NoError(t, tracer.spans[2].err)
+
+	tracer.spans = nil
+	_, err = client.Reactions().Filter(stream.ByActivityID("aid"))
+	require.Error(t, err)
+	require.Len(t, tracer.spans, 2)
+	op = tracer.spans[0]
+	assert.Equal(t, "reactions.filter", op.name)
+	assert.NotContains(t, op.attributes, stream.AttributeFeedID)
+	assert.NotContains(t, op.attributes, stream.AttributeRetryCount)
+	assert.Equal(t, http.StatusBadRequest, op.attributes[stream.AttributeStatusCode])
+	assert.Error(t, op.err)
+}
+
+func TestTracingOperations(t *testing.T) {
+	tracer := &recordingTracer{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(&scriptedRequester{}),
+		stream.WithTracer(tracer),
+	)
+	require.NoError(t, err)
+	flat, _ := newFlatFeedWithUserID(client, "42")
+
+	_, _ = flat.GetActivities()
+	_, _ = flat.GetEnrichedActivities()
+	_, _ = flat.GetFollowers()
+	_ = flat.Unfollow(flat)
+	_, _ = client.GetActivitiesByID("a")
+	_, _ = client.Collections().Get("food", "1")
+	_, _ = client.Users().Get("bob")
+	_, _ = client.Personalization().Get("follow_recommendations", nil)
+	_ = client.Analytics().TrackEngagement()
+
+	var names []string
+	for _, span := range tracer.spans {
+		if span.parent == nil {
+			names = append(names, span.name)
+		}
+	}
+	expected := []string{
+		"feed.get_activities flat:42",
+		"feed.get_enriched_activities flat:42",
+		"feed.get_followers flat:42",
+		"feed.unfollow flat:42",
+		"activities.get",
+		"collections.get",
+		"users.get",
+		"personalization.get",
+		"analytics.track_engagement",
+	}
+	assert.Equal(t, expected, names)
+}
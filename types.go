@@ -1,13 +1,10 @@
 package stream
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/url"
-	"strings"
 	"time"
-
-	"github.com/mitchellh/mapstructure"
 )
 
 // Duration wraps time.Duration, used because of JSON marshaling and
@@ -29,7 +26,7 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 	case float64:
 		*d, err = durationFromString(fmt.Sprintf("%fs", v))
 	default:
-		err = fmt.Errorf("invalid duration")
+		err = fmt.Errorf("invalid duration: expected a string or a number of seconds, got %s", jsonKind(b))
 	}
 	return err
 }
@@ -52,9 +49,15 @@ type Time struct {
 
 // UnmarshalJSON for Time is required because of the incoming time string format.
 func (t *Time) UnmarshalJSON(b []byte) error {
-	var err error
-	*t, err = timeFromString(strings.Replace(string(b), `"`, "", -1))
-	return err
+	if bytes.Equal(b, jsonNull) {
+		return nil
+	}
+	tt, err := decodeTime(b)
+	if err != nil {
+		return err
+	}
+	*t = tt
+	return nil
 }
 
 // MarshalJSON marshals Time into a string formatted with the TimeLayout format.
@@ -81,26 +84,80 @@ type Data struct {
 	Extra map[string]interface{} `json:"-"`
 }
 
-func (a *Data) decode(data map[string]interface{}) error {
-	// We are not using decodeData here because we do not need the DecodeHook
-	// since it leads to a stack overflow
-	cfg := &mapstructure.DecoderConfig{
-		Result:   a,
-		Metadata: &mapstructure.Metadata{},
-		TagName:  "json",
+// UnmarshalJSON decodes the provided JSON payload into the Data, which is
+// either a plain ID or an enriched object whose fields other than the ID are
+// kept in Extra.
+func (a *Data) UnmarshalJSON(b []byte) error {
+	switch {
+	case len(b) > 0 && b[0] == '"':
+		id, err := decodeString(b)
+		if err != nil {
+			return err
+		}
+		*a = Data{ID: id}
+	case len(b) > 0 && b[0] == '{':
+		fields, err := decodeObject(b)
+		if err != nil {
+			return err
+		}
+		*a = Data{}
+		for k, raw := range fields {
+			if k == "id" {
+				if a.ID, err = decodeString(raw); err != nil {
+					return fieldError(k, err)
+				}
+				continue
+			}
+			if a.Extra, err = setExtra(a.Extra, k, raw); err != nil {
+				return fieldError(k, err)
+			}
+		}
+	case bytes.Equal(b, jsonNull):
+	default:
+		return fmt.Errorf("invalid data: expected an ID or an object, got %s", jsonKind(b))
 	}
-	dec, err := mapstructure.NewDecoder(cfg)
-	if err != nil {
-		return err
+	return nil
+}
+
+// MarshalJSON encodes the Data as an object holding its ID and extra fields.
+func (a Data) MarshalJSON() ([]byte, error) {
+	return a.appendJSON(nil)
+}
+
+// dataFields are the JSON fields of Data.
+var dataFields = map[string]bool{"id": true}
+
+func (a Data) appendJSON(b []byte) ([]byte, error) {
+	if len(a.Extra) == 0 {
+		b = append(b, `{"id":`...)
+		b = appendString(b, a.ID)
+		return append(b, '}'), nil
 	}
-	if err := dec.Decode(data); err != nil {
-		return err
+	e := objectEncoder{buf: make([]byte, 0, 128)}
+	e.setString("id", a.ID)
+	if err := e.setExtra(a.Extra, dataFields); err != nil {
+		return nil, err
 	}
+	return e.appendTo(b), nil
+}
 
-	if len(cfg.Metadata.Unused) > 0 {
-		a.Extra = make(map[string]interface{})
-		for _, k := range cfg.Metadata.Unused {
-			a.Extra[k] = data[k]
+// decode sets the Data from the given enriched object, as decoded by
+// encoding/json.
+func (a *Data) decode(data map[string]interface{}) error {
+	for k, v := range data {
+		if k != "id" {
+			if a.Extra == nil {
+				a.Extra = make(map[string]interface{})
+			}
+			a.Extra[k] = v
+			continue
+		}
+		switch id := v.(type) {
+		case string:
+			a.ID = id
+		case nil:
+		default:
+			return fmt.Errorf("invalid %q field: expected a string, got %T", k, v)
 		}
 	}
 	return nil
@@ -108,9 +165,21 @@ func (a *Data) decode(data map[string]interface{}) error {
 
 // Response is the part of StreamAPI responses common throughout the API.
 type response struct {
+	rateLimited
 	Duration Duration `json:"duration,omitempty"`
 }
 
+// mergeChunk merges into the response the one of a chunk of a read split in
+// chunks, keeping the longest duration and the lowest rate limit left.
+func (r *response) mergeChunk(chunk response) {
+	if chunk.Duration.Duration > r.Duration.Duration {
+		r.Duration = chunk.Duration
+	}
+	if rl := chunk.rateLimit; rl != nil && (r.rateLimit == nil || rl.Remaining < r.rateLimit.Remaining) {
+		r.rateLimit = rl
+	}
+}
+
 // readResponse is the part of StreamAPI responses common for GetActivities API requests.
 type readResponse struct {
 	response
@@ -126,78 +195,6 @@ var (
 	ErrInvalidNextPage = fmt.Errorf("invalid format for Next field")
 )
 
-func (r readResponse) parseNext() ([]GetActivitiesOption, error) {
-	if r.Next == "" {
-		return nil, ErrMissingNextPage
-	}
-
-	urlParts := strings.Split(r.Next, "?")
-	if len(urlParts) != 2 {
-		return nil, ErrInvalidNextPage
-	}
-	values, err := url.ParseQuery(urlParts[1])
-	if err != nil {
-		return nil, ErrInvalidNextPage
-	}
-
-	var opts []GetActivitiesOption
-
-	limit, ok, err := parseIntValue(values, "limit")
-	if err != nil {
-		return nil, err
-	}
-	if ok {
-		opts = append(opts, WithActivitiesLimit(limit))
-	}
-
-	offset, ok, err := parseIntValue(values, "offset")
-	if err != nil {
-		return nil, err
-	}
-	if ok {
-		opts = append(opts, WithActivitiesOffset(offset))
-	}
-
-	if idLT := values.Get("id_lt"); idLT != "" {
-		opts = append(opts, WithActivitiesIDLT(idLT))
-	}
-
-	if ranking := values.Get("ranking"); ranking != "" {
-		opts = append(opts, withActivitiesRanking(ranking))
-	}
-
-	if enrichOpt := values.Get("withOwnReactions"); parseBool(enrichOpt) {
-		opts = append(opts, WithEnrichOwnReactions())
-	}
-
-	if enrichOpt := values.Get("withRecentReactions"); parseBool(enrichOpt) {
-		opts = append(opts, WithEnrichRecentReactions())
-	}
-
-	if enrichOpt := values.Get("withReactionCounts"); parseBool(enrichOpt) {
-		opts = append(opts, WithEnrichReactionCounts())
-	}
-
-	if enrichOpt := values.Get("withOwnChildren"); parseBool(enrichOpt) {
-		opts = append(opts, WithEnrichOwnChildren())
-	}
-
-	reactionsLimit, ok, err := parseIntValue(values, "recentReactionsLimit")
-	if err != nil {
-		return nil, err
-	}
-	if ok {
-		opts = append(opts, WithEnrichRecentReactionsLimit(reactionsLimit))
-	}
-
-	if enrichOpt := values.Get("reactionKindsFilter"); enrichOpt != "" {
-		kinds := strings.Split(enrichOpt, ",")
-		opts = append(opts, WithEnrichReactionKindsFilter(kinds...))
-	}
-
-	return opts, nil
-}
-
 // baseNotificationFeedResponse is the common part of responses obtained from reading normal or enriched notification feeds.
 type baseNotificationFeedResponse struct {
 	readResponse
@@ -248,6 +245,7 @@ type NotificationFeedResult struct {
 // to a feed.
 type AddActivityResponse struct {
 	Activity
+	rateLimited
 }
 
 // AddActivitiesResponse is the API response obtained when adding activities to
@@ -383,6 +381,15 @@ type GetCollectionResponseObject struct {
 	Data      map[string]interface{} `json:"data"`
 }
 
+// SelectCollectionResponse is the response of selecting collection objects,
+// reporting the IDs no object was found for.
+type SelectCollectionResponse struct {
+	// Results are the objects found, in the order of the requested IDs.
+	Results []GetCollectionResponseObject
+	// Missing are the requested IDs no object was found for.
+	Missing []string
+}
+
 //User represents a user
 type User struct {
 	ID   string                 `json:"id"`
@@ -414,53 +421,6 @@ type filterResponse struct {
 	Next string `json:"next,omitempty"`
 }
 
-func (r filterResponse) parseNext() ([]FilterReactionsOption, error) {
-	if r.Next == "" {
-		return nil, ErrMissingNextPage
-	}
-
-	urlParts := strings.Split(r.Next, "?")
-	if len(urlParts) != 2 {
-		return nil, ErrInvalidNextPage
-	}
-	values, err := url.ParseQuery(urlParts[1])
-	if err != nil {
-		return nil, ErrInvalidNextPage
-	}
-
-	var opts []FilterReactionsOption
-
-	limit, ok, err := parseIntValue(values, "limit")
-	if err != nil {
-		return nil, err
-	}
-	if ok {
-		opts = append(opts, WithLimit(limit))
-	}
-
-	if idLT := values.Get("id_lt"); idLT != "" {
-		opts = append(opts, WithIDLT(idLT))
-	}
-
-	if idLT := values.Get("id_gt"); idLT != "" {
-		opts = append(opts, WithIDGT(idLT))
-	}
-
-	if withActData := values.Get("with_activity_data"); withActData != "" {
-		if val := strings.ToLower(withActData); val == "true" || val == "t" || val == "1" {
-			opts = append(opts, WithActivityData())
-		}
-	}
-
-	if withOwnChildren := values.Get("with_own_children"); withOwnChildren != "" {
-		if val := strings.ToLower(withOwnChildren); val == "true" || val == "t" || val == "1" {
-			opts = append(opts, WithOwnChildren())
-		}
-	}
-
-	return opts, nil
-}
-
 // FilterReactionResponse is the response received from the ReactionsClient.Filter call.
 type FilterReactionResponse struct {
 	filterResponse
@@ -487,6 +447,7 @@ type PersonalizationResponse struct {
 	Next     string                   `json:"next"`
 	Results  []map[string]interface{} `json:"results"`
 	extra    map[string]interface{}
+	rateLimited
 }
 
 // Extra returns the non-common response fields as a map[string]interface{}.
@@ -514,6 +475,14 @@ func (r *PersonalizationResponse) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+func (r *PersonalizationResponse) restoreExtra(obj map[string]interface{}, decoding NumberDecoding) {
+	for k := range r.extra {
+		if node, ok := obj[k]; ok {
+			r.extra[k] = convertNumbers(node, decoding)
+		}
+	}
+}
+
 // EnrichedFlatFeedResponse is the API response obtained when retrieving enriched activities from
 // a flat feed.
 type EnrichedFlatFeedResponse struct {
@@ -540,6 +509,8 @@ type EnrichedNotificationFeedResponse struct {
 type GetActivitiesResponse struct {
 	response
 	Results []Activity `json:"results"`
+	// Missing are the requested IDs, or foreign IDs, no activity was found for.
+	Missing []string `json:"-"`
 }
 
 // ForeignIDTimePair couples an activity's foreignID and timestamp.
@@ -556,6 +527,11 @@ func NewForeignIDTimePair(foreignID string, timestamp Time) ForeignIDTimePair {
 	}
 }
 
+// key identifies the pair when matching activities against it.
+func (p ForeignIDTimePair) key() string {
+	return p.ForeignID + "|" + p.Timestamp.Format(TimeLayout)
+}
+
 // UpdateActivityRequest is the API request body for partially updating an activity.
 type UpdateActivityRequest struct {
 	ID        *string                `json:"id,omitempty"`
@@ -588,8 +564,12 @@ func NewUpdateActivityRequestByForeignID(foreignID string, timestamp Time, set m
 // UpdateActivityByForeignID methods.
 type UpdateActivityResponse struct {
 	Activity
+	rateLimited
 }
 
+// UpdateActivitiesResponse is the response returned by the PartialUpdateActivities
+// method.
 type UpdateActivitiesResponse struct {
+	rateLimited
 	Activities []*Activity `json:"activities"`
 }
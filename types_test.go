@@ -22,6 +22,13 @@ func TestDurationMarshalUnmarshalJSON(t *testing.T) {
 	assert.Equal(t, dur, out)
 }
 
+func TestDurationUnmarshalJSON_invalid(t *testing.T) {
+	for _, data := range []string{`"forever"`, `{}`, `[]`, `true`, `1e300`, `"`} {
+		var out stream.Duration
+		assert.Error(t, out.UnmarshalJSON([]byte(data)), data)
+	}
+}
+
 func TestTimeMarshalUnmarshalJSON(t *testing.T) {
 	tt, _ := time.Parse("2006-Jan-02", "2013-Feb-03")
 	st := stream.Time{Time: tt}
@@ -34,3 +41,13 @@ func TestTimeMarshalUnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, st, out)
 }
+
+func TestTimeUnmarshalJSON_invalid(t *testing.T) {
+	for _, data := range []string{`"yesterday"`, `42`, `{}`, `""`, `"`, `"2013-02-03T00:00:00`} {
+		var out stream.Time
+		assert.Error(t, out.UnmarshalJSON([]byte(data)), data)
+	}
+	out := stream.Time{Time: time.Now()}
+	require.NoError(t, out.UnmarshalJSON([]byte(`null`)))
+	assert.False(t, out.IsZero())
+}
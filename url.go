@@ -2,7 +2,7 @@ package stream
 
 import (
 	"fmt"
-	"os"
+	"strings"
 )
 
 const domain = "stream-io-api.com"
@@ -25,18 +25,21 @@ var personalizationOverrides = map[string]string{
 type regionalURLBuilder struct {
 	region  string
 	version string
+	// baseURL, if set, overrides the regional host.
+	baseURL string
 }
 
-func newRegionalURLBuilder(region, version string) regionalURLBuilder {
+func newRegionalURLBuilder(region, version, baseURL string) regionalURLBuilder {
 	return regionalURLBuilder{
 		region:  region,
 		version: version,
+		baseURL: baseURL,
 	}
 }
 
 func (u regionalURLBuilder) makeHost(subdomain string) string {
-	if envHost := os.Getenv("STREAM_URL"); envHost != "" {
-		return envHost
+	if u.baseURL != "" {
+		return strings.TrimSuffix(u.baseURL, "/")
 	}
 	return fmt.Sprintf("https://%s.%s", u.makeRegion(subdomain), domain)
 }
@@ -62,8 +65,8 @@ type apiURLBuilder struct {
 	regionalURLBuilder
 }
 
-func newAPIURLBuilder(region, version string) apiURLBuilder {
-	return apiURLBuilder{newRegionalURLBuilder(region, version)}
+func newAPIURLBuilder(region, version, baseURL string) apiURLBuilder {
+	return apiURLBuilder{newRegionalURLBuilder(region, version, baseURL)}
 }
 
 func (u apiURLBuilder) url() string {
@@ -72,17 +75,25 @@ func (u apiURLBuilder) url() string {
 
 type personalizationURLBuilder struct {
 	region string
+	// baseURL, if set, overrides the regional host.
+	baseURL string
+	// endpoint, if set, overrides the whole URL.
+	endpoint string
 }
 
-func newPersonalizationURLBuilder(region string) personalizationURLBuilder {
+func newPersonalizationURLBuilder(region, baseURL string) personalizationURLBuilder {
 	return personalizationURLBuilder{
-		region: region,
+		region:  region,
+		baseURL: baseURL,
 	}
 }
 
 func (b personalizationURLBuilder) url() string {
-	if envHost := os.Getenv("STREAM_URL"); envHost != "" {
-		return envHost
+	if b.endpoint != "" {
+		return b.endpoint
+	}
+	if b.baseURL != "" {
+		return fmt.Sprintf("%s/personalization/v1.0/", strings.TrimSuffix(b.baseURL, "/"))
 	}
 	defaultPath := fmt.Sprintf("personalization.%s/personalization/v1.0/", domain)
 	if override, ok := personalizationOverrides[b.region]; ok {
@@ -96,8 +107,8 @@ type analyticsURLBuilder struct {
 	regionalURLBuilder
 }
 
-func newAnalyticsURLBuilder(region, version string) analyticsURLBuilder {
-	return analyticsURLBuilder{newRegionalURLBuilder(region, version)}
+func newAnalyticsURLBuilder(region, version, baseURL string) analyticsURLBuilder {
+	return analyticsURLBuilder{newRegionalURLBuilder(region, version, baseURL)}
 }
 
 func (u analyticsURLBuilder) url() string {
@@ -17,17 +17,21 @@ func Test_URLString(t *testing.T) {
 			expected:   fmt.Sprintf("https://api.%s/api/v1.0/", domain),
 		},
 		{
-			urlBuilder: newAPIURLBuilder("us-east", "2.0"),
+			urlBuilder: newAPIURLBuilder("us-east", "2.0", ""),
 			expected:   fmt.Sprintf("https://us-east-api.%s/api/v2.0/", domain),
 		},
 		{
-			urlBuilder: newAPIURLBuilder("eu-west", "2.0"),
+			urlBuilder: newAPIURLBuilder("eu-west", "2.0", ""),
 			expected:   fmt.Sprintf("https://eu-west-api.%s/api/v2.0/", domain),
 		},
 		{
-			urlBuilder: newAPIURLBuilder("singapore", "2.0"),
+			urlBuilder: newAPIURLBuilder("singapore", "2.0", ""),
 			expected:   fmt.Sprintf("https://singapore-api.%s/api/v2.0/", domain),
 		},
+		{
+			urlBuilder: newAPIURLBuilder("singapore", "2.0", "http://localhost:8000/"),
+			expected:   "http://localhost:8000/api/v2.0/",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -45,13 +49,41 @@ func Test_PersonalizationURLString(t *testing.T) {
 			expected:   "https://personalization.stream-io-api.com/personalization/v1.0/",
 		},
 		{
-			urlBuilder: personalizationURLBuilder{"us-east"},
+			urlBuilder: personalizationURLBuilder{region: "us-east"},
 			expected:   "https://personalization.stream-io-api.com/personalization/v1.0/",
 		},
 		{
-			urlBuilder: personalizationURLBuilder{"eu-west"},
+			urlBuilder: personalizationURLBuilder{region: "eu-west"},
 			expected:   "https://dublin-personalization.stream-io-api.com/personalization/v1.0/",
 		},
+		{
+			urlBuilder: newPersonalizationURLBuilder("eu-west", "http://localhost:8000"),
+			expected:   "http://localhost:8000/personalization/v1.0/",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, tc.urlBuilder.url())
+	}
+}
+
+func Test_AnalyticsURLString(t *testing.T) {
+	testCases := []struct {
+		urlBuilder analyticsURLBuilder
+		expected   string
+	}{
+		{
+			urlBuilder: newAnalyticsURLBuilder("", "", ""),
+			expected:   "https://analytics.stream-io-api.com/analytics/v1.0/",
+		},
+		{
+			urlBuilder: newAnalyticsURLBuilder("us-east", "2.0", ""),
+			expected:   "https://us-east-api.stream-io-api.com/analytics/v2.0/",
+		},
+		{
+			urlBuilder: newAnalyticsURLBuilder("us-east", "", "http://localhost:8000"),
+			expected:   "http://localhost:8000/analytics/v1.0/",
+		},
 	}
 
 	for _, tc := range testCases {
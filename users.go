@@ -1,7 +1,7 @@
 package stream
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
@@ -12,16 +12,18 @@ type UsersClient struct {
 
 // Add adds a new user with the specified id and optional extra data.
 func (c *UsersClient) Add(user User, getOrCreate bool) (*User, error) {
-	endpoint := c.client.makeEndpoint("user/")
-	endpoint.addQueryParam(makeRequestOption("get_or_create", getOrCreate))
+	return c.AddContext(context.Background(), user, getOrCreate)
+}
 
-	resp, err := c.client.post(endpoint, user, c.client.authenticator.usersAuth)
-	if err != nil {
-		return nil, err
-	}
+// AddContext is like Add, using the provided context for the request.
+func (c *UsersClient) AddContext(ctx context.Context, user User, getOrCreate bool) (*User, error) {
+	endpoint := c.client.makeEndpoint(ResourceUsers, "user/")
+	endpoint.operation = "users.add"
+	endpoint.addQueryParam(makeRequestOption("get_or_create", getOrCreate))
+	endpoint.idempotent = getOrCreate
 
 	result := &User{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.post(ctx, endpoint, user, result, c.client.authenticator.usersAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -30,18 +32,19 @@ func (c *UsersClient) Add(user User, getOrCreate bool) (*User, error) {
 
 // Update updates the user's data.
 func (c *UsersClient) Update(id string, data map[string]interface{}) (*User, error) {
-	endpoint := c.client.makeEndpoint("user/%s/", id)
+	return c.UpdateContext(context.Background(), id, data)
+}
+
+// UpdateContext is like Update, using the provided context for the request.
+func (c *UsersClient) UpdateContext(ctx context.Context, id string, data map[string]interface{}) (*User, error) {
+	endpoint := c.client.makeEndpoint(ResourceUsers, "user/%s/", id)
+	endpoint.operation = "users.update"
 
 	reqData := map[string]interface{}{
 		"data": data,
 	}
-	resp, err := c.client.put(endpoint, reqData, c.client.authenticator.usersAuth)
-	if err != nil {
-		return nil, err
-	}
-
 	result := &User{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.put(ctx, endpoint, reqData, result, c.client.authenticator.usersAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -50,15 +53,16 @@ func (c *UsersClient) Update(id string, data map[string]interface{}) (*User, err
 
 //Get retrieves a user having the given id.
 func (c *UsersClient) Get(id string) (*User, error) {
-	endpoint := c.client.makeEndpoint("user/%s/", id)
+	return c.GetContext(context.Background(), id)
+}
 
-	resp, err := c.client.get(endpoint, nil, c.client.authenticator.usersAuth)
-	if err != nil {
-		return nil, err
-	}
+// GetContext is like Get, using the provided context for the request.
+func (c *UsersClient) GetContext(ctx context.Context, id string) (*User, error) {
+	endpoint := c.client.makeEndpoint(ResourceUsers, "user/%s/", id)
+	endpoint.operation = "users.get"
 
 	result := &User{}
-	err = json.Unmarshal(resp, result)
+	err := c.client.get(ctx, endpoint, nil, result, c.client.authenticator.usersAuth)
 	if err != nil {
 		return nil, err
 	}
@@ -67,10 +71,15 @@ func (c *UsersClient) Get(id string) (*User, error) {
 
 //Delete deletes a user having the given id.
 func (c *UsersClient) Delete(id string) error {
-	endpoint := c.client.makeEndpoint("user/%s/", id)
+	return c.DeleteContext(context.Background(), id)
+}
+
+// DeleteContext is like Delete, using the provided context for the request.
+func (c *UsersClient) DeleteContext(ctx context.Context, id string) error {
+	endpoint := c.client.makeEndpoint(ResourceUsers, "user/%s/", id)
+	endpoint.operation = "users.delete"
 
-	_, err := c.client.delete(endpoint, nil, c.client.authenticator.usersAuth)
-	return err
+	return c.client.delete(ctx, endpoint, nil, nil, c.client.authenticator.usersAuth)
 }
 
 // CreateReference returns a new reference string in the form SU:<id>.
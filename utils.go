@@ -1,10 +1,10 @@
 package stream
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"reflect"
-	"strconv"
 	"strings"
 
 	"github.com/mitchellh/mapstructure"
@@ -13,7 +13,11 @@ import (
 func decodeJSONHook(f reflect.Type, typ reflect.Type, data interface{}) (interface{}, error) {
 	switch typ {
 	case reflect.TypeOf(Time{}):
-		return timeFromString(data.(string))
+		s, ok := data.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid time: expected a string, got %T", data)
+		}
+		return timeFromString(s)
 	case reflect.TypeOf(Duration{}):
 		switch v := data.(type) {
 		case string:
@@ -59,19 +63,60 @@ func decodeData(data map[string]interface{}, target interface{}) (*mapstructure.
 	return cfg.Metadata, nil
 }
 
-func parseIntValue(values url.Values, key string) (int, bool, error) {
-	v := values.Get(key)
-	if v == "" {
-		return 0, false, nil
+// decodeExtra decodes the given extra fields into v, which must be a pointer
+// to a struct or a map, following the same rules as json.Unmarshal.
+func decodeExtra(extra map[string]interface{}, v interface{}) error {
+	if extra == nil {
+		extra = map[string]interface{}{}
 	}
-	i, err := strconv.Atoi(v)
+	data, err := json.Marshal(extra)
 	if err != nil {
-		return 0, false, err
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// encodeExtra returns a copy of extra with the fields v encodes to as a JSON
+// object added to it, failing if any of them is one of the given reserved
+// fields. Numbers are kept as json.Number, so that no precision is lost.
+func encodeExtra(extra map[string]interface{}, v interface{}, reserved map[string]bool) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, fmt.Errorf("extra fields must encode to a JSON object: %s", err)
 	}
-	return i, true, nil
+	if fields == nil {
+		return nil, fmt.Errorf("extra fields must encode to a JSON object")
+	}
+	for k := range fields {
+		if reserved[k] {
+			return nil, fmt.Errorf("extra field %q conflicts with a standard field", k)
+		}
+	}
+	merged := make(map[string]interface{}, len(extra)+len(fields))
+	for k, v := range extra {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged, nil
 }
 
-func parseBool(value string) bool {
-	v := strings.ToLower(value)
-	return v != "" && v != "false" && v != "f" && v != "0"
+// jsonFieldNames returns the set of JSON field names of the given struct.
+func jsonFieldNames(v interface{}) map[string]bool {
+	typ := reflect.TypeOf(v)
+	names := make(map[string]bool, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
 }
@@ -1,7 +1,6 @@
 package stream
 
 import (
-	"net/url"
 	"reflect"
 	"testing"
 	"time"
@@ -95,48 +94,3 @@ func Test_decodeJSONHook(t *testing.T) {
 		}
 	}
 }
-
-func Test_parseIntValue(t *testing.T) {
-	testCases := []struct {
-		values       url.Values
-		shouldError  bool
-		expected     int
-		expectedFlag bool
-	}{
-		{
-			values:       url.Values{},
-			shouldError:  false,
-			expected:     0,
-			expectedFlag: false,
-		},
-		{
-			values:       url.Values{"test": []string{"a"}},
-			shouldError:  true,
-			expected:     0,
-			expectedFlag: false,
-		},
-		{
-			values:       url.Values{"test": []string{"123"}},
-			shouldError:  false,
-			expected:     123,
-			expectedFlag: true,
-		},
-		{
-			values:       url.Values{"test": []string{"123.5"}},
-			shouldError:  true,
-			expected:     0,
-			expectedFlag: false,
-		},
-	}
-	for _, tc := range testCases {
-		v, ok, err := parseIntValue(tc.values, "test")
-		if tc.shouldError {
-			require.Error(t, err)
-			assert.False(t, ok)
-		} else {
-			require.NoError(t, err)
-			assert.Equal(t, tc.expectedFlag, ok)
-			assert.Equal(t, tc.expected, v)
-		}
-	}
-}
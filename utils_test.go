@@ -74,14 +74,14 @@ func getTime(t time.Time) stream.Time {
 	return stream.Time{Time: st}
 }
 
-func newFlatFeedWithUserID(c *stream.Client, userID string) (*stream.FlatFeed, error) {
+func newFlatFeedWithUserID(c *stream.Client, userID string) (stream.FlatFeedInterface, error) {
 	return c.FlatFeed("flat", userID)
 }
 
-func newAggregatedFeedWithUserID(c *stream.Client, userID string) (*stream.AggregatedFeed, error) {
+func newAggregatedFeedWithUserID(c *stream.Client, userID string) (stream.AggregatedFeedInterface, error) {
 	return c.AggregatedFeed("aggregated", userID)
 }
 
-func newNotificationFeedWithUserID(c *stream.Client, userID string) (*stream.NotificationFeed, error) {
+func newNotificationFeedWithUserID(c *stream.Client, userID string) (stream.NotificationFeedInterface, error) {
 	return c.NotificationFeed("notification", userID)
 }
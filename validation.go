@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultMaxActivitySize is the default maximum size, in bytes, of the JSON
+// encoding of an activity checked by the client-side validation.
+const DefaultMaxActivitySize = 100 * 1024
+
+// ValidationConfig configures the client-side validation of activities.
+type ValidationConfig struct {
+	// MaxActivitySize is the maximum size, in bytes, of the JSON encoding of an
+	// activity. Defaults to DefaultMaxActivitySize, negative values mean no
+	// limit.
+	MaxActivitySize int
+}
+
+// WithActivityValidation enables the client-side validation of the activities
+// sent with AddActivity, AddActivities, AddToMany and UpdateActivities. When any
+// of them is invalid, no request is sent and an *ActivityValidationError is
+// returned. Activities are checked for:
+//
+//   - a missing actor, verb or object;
+//   - extra fields having the name of a standard or reserved field;
+//   - `to` targets which aren't valid feed IDs;
+//   - a foreign ID without a time, as both are required to identify an
+//     activity (and a missing foreign ID or time when updating activities);
+//   - a JSON encoding larger than the configured maximum size.
+func WithActivityValidation(config ValidationConfig) ClientOption {
+	return func(c *Client) {
+		if config.MaxActivitySize == 0 {
+			config.MaxActivitySize = DefaultMaxActivitySize
+		}
+		c.validation = &config
+	}
+}
+
+// FieldError is the validation failure of a field of an activity.
+type FieldError struct {
+	// Field is the JSON name of the field, e.g. "actor" or "to[1]".
+	Field string
+	// Reason tells why the field is invalid.
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Reason
+}
+
+// ActivityErrors are the validation failures of an activity.
+type ActivityErrors struct {
+	// Index is the position of the activity among the ones sent.
+	Index int
+	// Activity is the invalid activity.
+	Activity Activity
+	// Errors are the validation failures of the activity fields.
+	Errors []FieldError
+}
+
+func (e ActivityErrors) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		msgs[i] = e.Errors[i].Error()
+	}
+	return fmt.Sprintf("activity %d: %s", e.Index, strings.Join(msgs, ", "))
+}
+
+// ActivityValidationError is returned when client-side validation is enabled
+// and some of the activities to send are invalid, in which case no request is
+// sent. It matches ErrValidation.
+type ActivityValidationError struct {
+	// Activities holds the failures of the invalid activities, ordered by
+	// their position.
+	Activities []ActivityErrors
+}
+
+func (e *ActivityValidationError) Error() string {
+	msgs := make([]string, len(e.Activities))
+	for i := range e.Activities {
+		msgs[i] = e.Activities[i].Error()
+	}
+	return "invalid activities: " + strings.Join(msgs, "; ")
+}
+
+// Is tells whether the error matches the given sentinel error.
+func (e *ActivityValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// reservedActivityFields are the field names reserved by the API, which can't
+// be used for custom fields along with the standard activity fields.
+var reservedActivityFields = []string{
+	"activity_id", "activity", "analytics", "extra_context", "is_read", "is_seen", "site_id",
+}
+
+var feedIDRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+:[a-zA-Z0-9_-]+$`)
+
+// validateActivities checks the given activities, if validation is enabled,
+// returning an *ActivityValidationError if any of them is invalid. Activities
+// being updated must also have a foreign ID and a time.
+func (c *Client) validateActivities(update bool, activities ...Activity) error {
+	if c.validation == nil {
+		return nil
+	}
+	var invalid []ActivityErrors
+	for i, activity := range activities {
+		if errs := c.validation.validate(activity, update); len(errs) > 0 {
+			invalid = append(invalid, ActivityErrors{Index: i, Activity: activity, Errors: errs})
+		}
+	}
+	if len(invalid) > 0 {
+		return &ActivityValidationError{Activities: invalid}
+	}
+	return nil
+}
+
+func (v *ValidationConfig) validate(a Activity, update bool) []FieldError {
+	var errs []FieldError
+	required := func(field, value string) {
+		if value == "" {
+			errs = append(errs, FieldError{Field: field, Reason: "is required"})
+		}
+	}
+	required("actor", a.Actor)
+	required("verb", a.Verb)
+	required("object", a.Object)
+
+	keys := make([]string, 0, len(a.Extra))
+	for k := range a.Extra {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if activityFields[k] {
+			errs = append(errs, FieldError{Field: k, Reason: "extra field conflicts with a standard field"})
+			continue
+		}
+		for _, reserved := range reservedActivityFields {
+			if k == reserved {
+				errs = append(errs, FieldError{Field: k, Reason: "extra field has a reserved name"})
+				break
+			}
+		}
+	}
+
+	for i, to := range a.To {
+		if !feedIDRegex.MatchString(to) {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("to[%d]", i), Reason: fmt.Sprintf("invalid feed ID %q", to)})
+		}
+	}
+
+	switch {
+	case update:
+		required("foreign_id", a.ForeignID)
+		if a.Time.IsZero() {
+			errs = append(errs, FieldError{Field: "time", Reason: "is required"})
+		}
+	case a.ForeignID != "" && a.Time.IsZero():
+		errs = append(errs, FieldError{Field: "time", Reason: "is required along with foreign_id"})
+	}
+
+	if v.MaxActivitySize > 0 {
+		data, err := json.Marshal(a)
+		switch {
+		case err != nil:
+			errs = append(errs, FieldError{Field: "extra", Reason: err.Error()})
+		case len(data) > v.MaxActivitySize:
+			errs = append(errs, FieldError{Field: "activity", Reason: fmt.Sprintf("size of %d bytes exceeds the maximum of %d", len(data), v.MaxActivitySize)})
+		}
+	}
+	return errs
+}
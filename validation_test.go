@@ -0,0 +1,121 @@
+package stream_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	stream "github.com/GetStream/stream-go2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityValidation(t *testing.T) {
+	requester := &mockRequester{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithActivityValidation(stream.ValidationConfig{MaxActivitySize: 200}),
+	)
+	require.NoError(t, err)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	now := stream.Time{Time: time.Now()}
+	valid := stream.Activity{Actor: "bob", Verb: "like", Object: "picture:1", ForeignID: "like:1", Time: now, To: []string{"timeline:alice", "notification:al-ice_1"}}
+
+	testCases := []struct {
+		activity stream.Activity
+		errors   []stream.FieldError
+	}{
+		{
+			activity: valid,
+		},
+		{
+			activity: stream.Activity{Object: "picture:1"},
+			errors: []stream.FieldError{
+				{Field: "actor", Reason: "is required"},
+				{Field: "verb", Reason: "is required"},
+			},
+		},
+		{
+			activity: stream.Activity{Actor: "bob", Verb: "like", Object: "picture:1", Extra: map[string]interface{}{"time": "now", "id": "1", "is_seen": true, "popularity": 1}},
+			errors: []stream.FieldError{
+				{Field: "id", Reason: "extra field conflicts with a standard field"},
+				{Field: "is_seen", Reason: "extra field has a reserved name"},
+				{Field: "time", Reason: "extra field conflicts with a standard field"},
+			},
+		},
+		{
+			activity: stream.Activity{Actor: "bob", Verb: "like", Object: "picture:1", To: []string{"timeline:alice", "alice", "timeline:al ice"}},
+			errors: []stream.FieldError{
+				{Field: "to[1]", Reason: `invalid feed ID "alice"`},
+				{Field: "to[2]", Reason: `invalid feed ID "timeline:al ice"`},
+			},
+		},
+		{
+			activity: stream.Activity{Actor: "bob", Verb: "like", Object: "picture:1", ForeignID: "like:1"},
+			errors: []stream.FieldError{
+				{Field: "time", Reason: "is required along with foreign_id"},
+			},
+		},
+		{
+			activity: stream.Activity{Actor: "bob", Verb: "like", Object: "picture:1", Extra: map[string]interface{}{"text": strings.Repeat("a", 200)}},
+			errors: []stream.FieldError{
+				{Field: "activity", Reason: "size of 260 bytes exceeds the maximum of 200"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		requester.req = nil
+		_, err := feed.AddActivity(tc.activity)
+		if tc.errors == nil {
+			assert.NoError(t, err)
+			assert.NotNil(t, requester.req)
+			continue
+		}
+		assert.Nil(t, requester.req)
+		var verr *stream.ActivityValidationError
+		require.True(t, errors.As(err, &verr))
+		assert.True(t, errors.Is(err, stream.ErrValidation))
+		require.Len(t, verr.Activities, 1)
+		assert.Equal(t, 0, verr.Activities[0].Index)
+		assert.Equal(t, tc.activity, verr.Activities[0].Activity)
+		assert.Equal(t, tc.errors, verr.Activities[0].Errors)
+	}
+
+	requester.req = nil
+	_, err = feed.AddActivities(valid, stream.Activity{Actor: "bob", Verb: "like"}, valid, stream.Activity{Actor: "bob", Object: "picture:1"})
+	assert.EqualError(t, err, "invalid activities: activity 1: object: is required; activity 3: verb: is required")
+	assert.Nil(t, requester.req)
+
+	err = client.AddToMany(stream.Activity{Actor: "bob"}, feed)
+	assert.EqualError(t, err, "invalid activities: activity 0: verb: is required, object: is required")
+	assert.Nil(t, requester.req)
+
+	err = client.UpdateActivities(valid, stream.Activity{Actor: "bob", Verb: "like", Object: "picture:1"})
+	assert.EqualError(t, err, "invalid activities: activity 1: foreign_id: is required, time: is required")
+	assert.Nil(t, requester.req)
+	require.NoError(t, client.UpdateActivities(valid))
+	assert.NotNil(t, requester.req)
+}
+
+func TestActivityValidationDisabled(t *testing.T) {
+	client, requester := newClient(t)
+	feed, err := client.FlatFeed("user", "bob")
+	require.NoError(t, err)
+	_, err = feed.AddActivity(stream.Activity{Extra: map[string]interface{}{"id": "1"}})
+	require.NoError(t, err)
+	assert.NotNil(t, requester.req)
+}
+
+func TestActivityValidationDefaultSize(t *testing.T) {
+	requester := &mockRequester{}
+	client, err := stream.NewClient("key", "secret",
+		stream.WithHTTPRequester(requester),
+		stream.WithActivityValidation(stream.ValidationConfig{}),
+	)
+	require.NoError(t, err)
+	activity := stream.Activity{Actor: "bob", Verb: "post", Object: "text:1", Extra: map[string]interface{}{"text": strings.Repeat("a", stream.DefaultMaxActivitySize)}}
+	err = client.AddToMany(activity)
+	assert.True(t, errors.Is(err, stream.ErrValidation))
+}